@@ -83,6 +83,7 @@ func (sr *snowflakeRestful) getAsync(
 	if respd.Success {
 		if resType == execResultType {
 			res.insertID = -1
+			res.stats = newQueryStats(&respd.Data)
 			if isDml(respd.Data.StatementTypeID) {
 				res.affectedRows, err = updateRows(respd.Data)
 				if err != nil {
@@ -99,12 +100,14 @@ func (sr *snowflakeRestful) getAsync(
 					res.errChannel <- err
 					return err
 				}
+				res.stats = r.(*snowflakeResult).stats
 			}
 			res.queryID = respd.Data.QueryID
 			res.errChannel <- nil // mark exec status complete
 		} else {
 			rows.sc = sc
 			rows.queryID = respd.Data.QueryID
+			rows.stats = newQueryStats(&respd.Data)
 			if isMultiStmt(&respd.Data) {
 				if err = sc.handleMultiQuery(ctx, respd.Data, rows); err != nil {
 					rows.errChannel <- err
@@ -205,7 +208,13 @@ func getQueryResultWithRetriesForAsyncMode(
 			// Once 5 second backoff is reached it will keep retrying with this sleeptime.
 			sleepTime := time.Millisecond * time.Duration(500*retryPattern[retryPatternIndex])
 			logger.WithContext(ctx).Infof("Query execution still in progress. Response code: %v, message: %v Sleep for %v ms", respd.Code, respd.Message, sleepTime)
-			time.Sleep(sleepTime)
+			await := time.NewTimer(sleepTime)
+			select {
+			case <-await.C:
+			case <-ctx.Done():
+				await.Stop()
+				return respd, ctx.Err()
+			}
 			retry++
 
 			if retryPatternIndex < len(retryPattern)-1 {
@@ -2,9 +2,7 @@ package gosnowflake
 
 import (
 	"context"
-	"crypto/sha256"
-	"crypto/x509"
-	"encoding/base64"
+	"crypto"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,7 +11,6 @@ import (
 	"net/url"
 	"os"
 	"runtime"
-	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -57,6 +54,8 @@ const (
 	AuthTypeOAuthClientCredentials
 	// AuthTypeWorkloadIdentityFederation is to use CSP identity for authentication
 	AuthTypeWorkloadIdentityFederation
+	// AuthTypeCustom is to use the Authenticator set in Config.CustomAuthenticator
+	AuthTypeCustom
 )
 
 func (authType AuthType) isOauthNativeFlow() bool {
@@ -159,6 +158,8 @@ func (authType AuthType) String() string {
 		return "OAUTH_CLIENT_CREDENTIALS"
 	case AuthTypeWorkloadIdentityFederation:
 		return "WORKLOAD_IDENTITY"
+	case AuthTypeCustom:
+		return "CUSTOM"
 	default:
 		return "UNKNOWN"
 	}
@@ -180,11 +181,32 @@ var userAgent = fmt.Sprintf("%v/%v (%v-%v) %v/%v",
 	runtime.Version())
 
 type authRequestClientEnvironment struct {
-	Application string `json:"APPLICATION"`
-	Os          string `json:"OS"`
-	OsVersion   string `json:"OS_VERSION"`
-	OCSPMode    string `json:"OCSP_MODE"`
-	GoVersion   string `json:"GO_VERSION"`
+	Application string            `json:"APPLICATION"`
+	Os          string            `json:"OS"`
+	OsVersion   string            `json:"OS_VERSION"`
+	OCSPMode    string            `json:"OCSP_MODE"`
+	GoVersion   string            `json:"GO_VERSION"`
+	Extra       map[string]string `json:"-"` // values added by the authenticator
+}
+
+// MarshalJSON adds the extra values to the client environment, without overriding the
+// ones set by the driver.
+func (env authRequestClientEnvironment) MarshalJSON() ([]byte, error) {
+	type clientEnvironment authRequestClientEnvironment
+	b, err := json.Marshal(clientEnvironment(env))
+	if err != nil || len(env.Extra) == 0 {
+		return b, err
+	}
+	values := make(map[string]interface{})
+	if err = json.Unmarshal(b, &values); err != nil {
+		return nil, err
+	}
+	for k, v := range env.Extra {
+		if _, ok := values[k]; !ok {
+			values[k] = v
+		}
+	}
+	return json.Marshal(values)
 }
 
 type authRequestData struct {
@@ -267,7 +289,7 @@ func postAuth(
 
 	fullURL := sr.getFullURL(loginRequestPath, params)
 	logger.WithContext(ctx).Infof("full URL: %v", fullURL)
-	resp, err := sr.FuncAuthPost(ctx, client, fullURL, headers, bodyCreator, timeout, sr.MaxRetryCount)
+	resp, err := sr.FuncAuthPost(ctx, client, fullURL, headers, bodyCreator, timeout, sr.MaxRetryCount, sr.getConfig())
 	if err != nil {
 		return nil, err
 	}
@@ -333,13 +355,16 @@ func authenticate(
 	samlResponse []byte,
 	proofKey []byte,
 ) (resp *authResponseMain, err error) {
+	ctx, span := startSpan(ctx, sc.cfg, authenticateSpanName, authenticatorAttribute.String(sc.cfg.Authenticator.String()))
+	defer func() { endSpan(span, err) }()
 	if sc.cfg.Authenticator == AuthTypeTokenAccessor {
 		logger.WithContext(ctx).Info("Bypass authentication using existing token from token accessor")
+		session := sc.currentSessionState()
 		sessionInfo := authResponseSessionInfo{
-			DatabaseName:  sc.cfg.Database,
-			SchemaName:    sc.cfg.Schema,
-			WarehouseName: sc.cfg.Warehouse,
-			RoleName:      sc.cfg.Role,
+			DatabaseName:  session.database,
+			SchemaName:    session.schema,
+			WarehouseName: session.warehouse,
+			RoleName:      session.role,
 		}
 		token, masterToken, sessionID := sc.cfg.TokenAccessor.GetTokens()
 		return &authResponseMain{
@@ -375,21 +400,22 @@ func authenticate(
 		sessionParameters[clientStoreTemporaryCredential] = true
 	}
 	bodyCreator := func() ([]byte, error) {
-		return createRequestBody(sc, sessionParameters, clientEnvironment, proofKey, samlResponse)
+		return createRequestBody(ctx, sc, sessionParameters, clientEnvironment, proofKey, samlResponse)
 	}
 
+	session := sc.currentSessionState()
 	params := &url.Values{}
-	if sc.cfg.Database != "" {
-		params.Add("databaseName", sc.cfg.Database)
+	if session.database != "" {
+		params.Add("databaseName", session.database)
 	}
-	if sc.cfg.Schema != "" {
-		params.Add("schemaName", sc.cfg.Schema)
+	if session.schema != "" {
+		params.Add("schemaName", session.schema)
 	}
-	if sc.cfg.Warehouse != "" {
-		params.Add("warehouse", sc.cfg.Warehouse)
+	if session.warehouse != "" {
+		params.Add("warehouse", session.warehouse)
 	}
-	if sc.cfg.Role != "" {
-		params.Add("roleName", sc.cfg.Role)
+	if session.role != "" {
+		params.Add("roleName", session.role)
 	}
 
 	logger.WithContext(ctx).Infof("PARAMS for Auth: %v, %v, %v, %v, %v, %v",
@@ -434,123 +460,33 @@ func authenticate(
 	return &respd.Data, nil
 }
 
-func createRequestBody(sc *snowflakeConn, sessionParameters map[string]interface{},
+func createRequestBody(ctx context.Context, sc *snowflakeConn, sessionParameters map[string]interface{},
 	clientEnvironment authRequestClientEnvironment, proofKey []byte, samlResponse []byte,
 ) ([]byte, error) {
+	loginRequest := LoginRequest{
+		Account: sc.cfg.Account,
+		User:    sc.cfg.User,
+	}
+	if err := sc.getAuthenticator(samlResponse, proofKey).PrepareLogin(ctx, &loginRequest); err != nil {
+		return nil, err
+	}
+	clientEnvironment.Extra = loginRequest.ClientEnvironment
 	requestMain := authRequestData{
 		ClientAppID:       clientType,
 		ClientAppVersion:  SnowflakeGoDriverVersion,
 		AccountName:       sc.cfg.Account,
 		SessionParameters: sessionParameters,
 		ClientEnvironment: clientEnvironment,
-	}
-
-	switch sc.cfg.Authenticator {
-	case AuthTypeExternalBrowser:
-		if sc.cfg.IDToken != "" {
-			requestMain.Authenticator = idTokenAuthenticator
-			requestMain.Token = sc.cfg.IDToken
-			requestMain.LoginName = sc.cfg.User
-		} else {
-			requestMain.ProofKey = string(proofKey)
-			requestMain.Token = string(samlResponse)
-			requestMain.LoginName = sc.cfg.User
-			requestMain.Authenticator = AuthTypeExternalBrowser.String()
-		}
-	case AuthTypeOAuth:
-		requestMain.LoginName = sc.cfg.User
-		requestMain.Authenticator = AuthTypeOAuth.String()
-		requestMain.Token = sc.cfg.Token
-	case AuthTypeOkta:
-		samlResponse, err := authenticateBySAML(
-			sc.ctx,
-			sc.rest,
-			sc.cfg.OktaURL,
-			sc.cfg.Application,
-			sc.cfg.Account,
-			sc.cfg.User,
-			sc.cfg.Password,
-			sc.cfg.DisableSamlURLCheck)
-		if err != nil {
-			return nil, err
-		}
-		requestMain.RawSAMLResponse = string(samlResponse)
-	case AuthTypeJwt:
-		requestMain.Authenticator = AuthTypeJwt.String()
-
-		jwtTokenString, err := prepareJWTToken(sc.cfg)
-		if err != nil {
-			return nil, err
-		}
-		requestMain.Token = jwtTokenString
-	case AuthTypePat:
-		logger.WithContext(sc.ctx).Info("Programmatic access token")
-		requestMain.Authenticator = AuthTypePat.String()
-		requestMain.LoginName = sc.cfg.User
-		requestMain.Token = sc.cfg.Token
-	case AuthTypeSnowflake:
-		logger.WithContext(sc.ctx).Debug("Username and password")
-		requestMain.LoginName = sc.cfg.User
-		requestMain.Password = sc.cfg.Password
-		switch {
-		case sc.cfg.PasscodeInPassword:
-			requestMain.ExtAuthnDuoMethod = "passcode"
-		case sc.cfg.Passcode != "":
-			requestMain.Passcode = sc.cfg.Passcode
-			requestMain.ExtAuthnDuoMethod = "passcode"
-		}
-	case AuthTypeUsernamePasswordMFA:
-		logger.WithContext(sc.ctx).Debug("Username and password MFA")
-		requestMain.LoginName = sc.cfg.User
-		requestMain.Password = sc.cfg.Password
-		switch {
-		case sc.cfg.MfaToken != "":
-			requestMain.Token = sc.cfg.MfaToken
-		case sc.cfg.PasscodeInPassword:
-			requestMain.ExtAuthnDuoMethod = "passcode"
-		case sc.cfg.Passcode != "":
-			requestMain.Passcode = sc.cfg.Passcode
-			requestMain.ExtAuthnDuoMethod = "passcode"
-		}
-	case AuthTypeOAuthAuthorizationCode:
-		logger.WithContext(sc.ctx).Debug("OAuth authorization code")
-		oauthClient, err := newOauthClient(sc.ctx, sc.cfg)
-		if err != nil {
-			return nil, err
-		}
-		token, err := oauthClient.authenticateByOAuthAuthorizationCode()
-		if err != nil {
-			return nil, err
-		}
-		requestMain.LoginName = sc.cfg.User
-		requestMain.Token = token
-		requestMain.OauthType = "OAUTH_AUTHORIZATION_CODE"
-	case AuthTypeOAuthClientCredentials:
-		logger.WithContext(sc.ctx).Debug("OAuth client credentials")
-		oauthClient, err := newOauthClient(sc.ctx, sc.cfg)
-		if err != nil {
-			return nil, err
-		}
-		token, err := oauthClient.authenticateByOAuthClientCredentials()
-		if err != nil {
-			return nil, err
-		}
-		requestMain.LoginName = sc.cfg.User
-		requestMain.Token = token
-		requestMain.OauthType = "OAUTH_CLIENT_CREDENTIALS"
-	case AuthTypeWorkloadIdentityFederation:
-		if !experimentalAuthEnabled() {
-			return nil, errors.New("workload identity authentication is not ready to use")
-		}
-		logger.WithContext(sc.ctx).Debug("Workload Identity Federation")
-		wifAttestationProvider := createWifAttestationProvider(sc.ctx)
-		wifAttestation, err := wifAttestationProvider.getAttestation(sc.cfg.WorkloadIdentityProvider)
-		if err != nil {
-			return nil, err
-		}
-		requestMain.Authenticator = AuthTypeWorkloadIdentityFederation.String()
-		requestMain.Token = wifAttestation.Credential
-		requestMain.Provider = wifAttestation.ProviderType
+		Authenticator:     loginRequest.Authenticator,
+		LoginName:         loginRequest.LoginName,
+		Password:          loginRequest.Password,
+		Passcode:          loginRequest.Passcode,
+		ExtAuthnDuoMethod: loginRequest.ExtAuthnDuoMethod,
+		Token:             loginRequest.Token,
+		OauthType:         loginRequest.OAuthType,
+		Provider:          loginRequest.Provider,
+		ProofKey:          loginRequest.ProofKey,
+		RawSAMLResponse:   loginRequest.RawSAMLResponse,
 	}
 
 	authRequest := authRequest{
@@ -570,30 +506,35 @@ func experimentalAuthEnabled() bool {
 
 // Generate a JWT token in string given the configuration
 func prepareJWTToken(config *Config) (string, error) {
-	if config.PrivateKey == nil {
-		return "", errors.New("trying to use keypair authentication, but PrivateKey was not provided in the driver config")
+	signer, err := config.getJWTSigner()
+	if err != nil {
+		return "", err
 	}
+	return signJWTToken(config, signer)
+}
+
+// signJWTToken generates a JWT token signed with the key
+func signJWTToken(config *Config, signer crypto.Signer) (string, error) {
 	logger.Debug("preparing JWT for keypair authentication")
-	pubBytes, err := x509.MarshalPKIXPublicKey(config.PrivateKey.Public())
+	fingerprint, err := publicKeyFingerprint(signer.Public())
 	if err != nil {
 		return "", err
 	}
-	hash := sha256.Sum256(pubBytes)
 
 	accountName := extractAccountName(config.Account)
 	userName := strings.ToUpper(config.User)
 
 	issueAtTime := time.Now().UTC()
 	jwtClaims := jwt.MapClaims{
-		"iss": fmt.Sprintf("%s.%s.%s", accountName, userName, "SHA256:"+base64.StdEncoding.EncodeToString(hash[:])),
+		"iss": fmt.Sprintf("%s.%s.%s", accountName, userName, fingerprint),
 		"sub": fmt.Sprintf("%s.%s", accountName, userName),
 		"iat": issueAtTime.Unix(),
 		"nbf": time.Date(2015, 10, 10, 12, 0, 0, 0, time.UTC).Unix(),
 		"exp": issueAtTime.Add(config.JWTExpireTimeout).Unix(),
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwtClaims)
+	token := jwt.NewWithClaims(signerSigningMethodRS256{}, jwtClaims)
 
-	tokenString, err := token.SignedString(config.PrivateKey)
+	tokenString, err := token.SignedString(signer)
 
 	if err != nil {
 		return "", err
@@ -603,8 +544,80 @@ func prepareJWTToken(config *Config) (string, error) {
 	return tokenString, err
 }
 
+// getToken returns the token used to log in, from Config.TokenProvider if set.
+func (c *Config) getToken(ctx context.Context) (string, error) {
+	if c.TokenProvider == nil {
+		return c.Token, nil
+	}
+	token, err := c.TokenProvider(ctx)
+	if err != nil {
+		logger.WithContext(ctx).Errorf("failed to get a token from the token provider. err: %v", err)
+		return "", err
+	}
+	return token, nil
+}
+
+// canReauthenticate tells whether the connection may log in again once its session can
+// no longer be renewed. It is opt-in, and only for authenticators that need no user interaction.
+func (sr *snowflakeRestful) canReauthenticate() bool {
+	cfg := sr.getConfig()
+	if cfg == nil || !cfg.ReauthenticateOnExpiry {
+		return false
+	}
+	switch cfg.Authenticator {
+	case AuthTypeJwt, AuthTypeOAuthClientCredentials, AuthTypePat, AuthTypeWorkloadIdentityFederation:
+		return true
+	case AuthTypeCustom:
+		authenticator, ok := cfg.CustomAuthenticator.(NonInteractiveAuthenticator)
+		return ok && authenticator.NonInteractive()
+	case AuthTypeOAuth:
+		// a static token is likely expired as well
+		return cfg.TokenProvider != nil
+	}
+	return false
+}
+
+// reauthenticate logs in again after the session expired, with the context of the
+// request that found it expired. The new session gets the role, warehouse, database
+// and schema the expired one had, as they are tracked in the config. Session
+// parameters changed with ALTER SESSION are lost. The caller must hold the token
+// accessor lock.
+func (sr *snowflakeRestful) reauthenticate(ctx context.Context, renewErr error) error {
+	sc := sr.Connection
+	logger.WithContext(ctx).Infof("session expired: %v. authenticating again via %v", renewErr, sc.cfg.Authenticator)
+	authData, err := login(ctx, sc)
+	if err != nil {
+		logger.WithContext(ctx).Errorf("failed to authenticate again. err: %v", err)
+		return renewErr
+	}
+	sc.populateSessionParameters(authData.Parameters)
+	// the session keeps being reset to the state it had after the first login
+	sc.updateSessionState(authData.SessionInfo)
+	if sessionCtx, ok := sc.ctx.(*sessionContext); ok {
+		sessionCtx.setSessionID(authData.SessionID)
+	}
+	return nil
+}
+
 // Authenticate with sc.cfg
 func authenticateWithConfig(sc *snowflakeConn) error {
+	authData, err := login(sc.ctx, sc)
+	if err != nil {
+		sc.cleanup()
+		return err
+	}
+	sc.populateSessionParameters(authData.Parameters)
+	sc.initSessionState(authData.SessionInfo)
+	sc.ctx = withSessionID(sc.ctx, authData.SessionID)
+	return nil
+}
+
+// login authenticates with sc.cfg, retrying rejected logins the authenticator can
+// recover from, and returns the new session.
+func login(ctx context.Context, sc *snowflakeConn) (*authResponseMain, error) {
+	sc.loginMu.Lock()
+	defer sc.loginMu.Unlock()
+
 	var authData *authResponseMain
 	var samlResponse []byte
 	var proofKey []byte
@@ -633,12 +646,12 @@ func authenticateWithConfig(sc *snowflakeConn) error {
 		}
 	}
 
-	logger.WithContext(sc.ctx).Infof("Authenticating via %v", sc.cfg.Authenticator.String())
+	logger.WithContext(ctx).Infof("Authenticating via %v", sc.cfg.Authenticator.String())
 	switch sc.cfg.Authenticator {
 	case AuthTypeExternalBrowser:
 		if sc.cfg.IDToken == "" {
 			samlResponse, proofKey, err = authenticateByExternalBrowser(
-				sc.ctx,
+				ctx,
 				sc.rest,
 				sc.cfg.Authenticator.String(),
 				sc.cfg.Application,
@@ -648,43 +661,41 @@ func authenticateWithConfig(sc *snowflakeConn) error {
 				sc.cfg.ExternalBrowserTimeout,
 				sc.cfg.DisableConsoleLogin)
 			if err != nil {
-				sc.cleanup()
-				return err
+				return nil, err
 			}
 		}
 	}
-	authData, err = authenticate(
-		sc.ctx,
-		sc,
-		samlResponse,
-		proofKey)
-	if err != nil {
+	authenticator := sc.getAuthenticator(samlResponse, proofKey)
+	sc.rejectedPrivateKeys = 0
+	for attempt := 1; ; attempt++ {
+		authData, err = authenticate(
+			ctx,
+			sc,
+			samlResponse,
+			proofKey)
 		var se *SnowflakeError
-		if errors.As(err, &se) && slices.Contains(refreshOAuthTokenErrorCodes, strconv.Itoa(se.Number)) {
-			credentialsStorage.deleteCredential(newOAuthAccessTokenSpec(sc.cfg.OauthTokenRequestURL, sc.cfg.User))
-
-			if sc.cfg.Authenticator == AuthTypeOAuthAuthorizationCode {
-				var oauthClient *oauthClient
-				if oauthClient, err = newOauthClient(sc.ctx, sc.cfg); err != nil {
-					logger.Warnf("failed to create oauth client. %v", err)
-				} else {
-					if err = oauthClient.refreshToken(); err != nil {
-						logger.Warnf("cannot refresh token. %v", err)
-						credentialsStorage.deleteCredential(newOAuthRefreshTokenSpec(sc.cfg.OauthTokenRequestURL, sc.cfg.User))
-					}
-				}
-			}
-
-			// if refreshing succeeds for authorization code, we will take a token from cache
-			// if it fails, we will just run the full flow
-			authData, err = authenticate(sc.ctx, sc, nil, nil)
+		if err == nil || attempt > loginRetries(authenticator) || !errors.As(err, &se) {
+			break
 		}
-		if err != nil {
-			sc.cleanup()
-			return err
+		retry, handleErr := authenticator.HandleLoginError(ctx, se, attempt)
+		if handleErr != nil {
+			err = handleErr
+			break
+		}
+		if !retry {
+			break
 		}
+		logger.WithContext(ctx).Infof("authenticating again after a rejected login. attempt: %v, err: %v", attempt, se)
+		// the SAML response and proof key of a browser login are single use
+		samlResponse, proofKey = nil, nil
 	}
-	sc.populateSessionParameters(authData.Parameters)
-	sc.ctx = context.WithValue(sc.ctx, SFSessionIDKey, authData.SessionID)
-	return nil
+	if err != nil {
+		return nil, err
+	}
+	if sc.cfg.Authenticator == AuthTypeJwt && sc.privateKeyCount > 1 {
+		logger.WithContext(ctx).Infof("authenticated with private key %v of %v. fingerprint: %v",
+			sc.privateKeyIndex+1, sc.privateKeyCount, sc.privateKeyFingerprint)
+		setLastPrivateKey(sc.cfg, sc.privateKeyFingerprint)
+	}
+	return authData, nil
 }
@@ -1094,3 +1094,152 @@ func TestWithOAuthClientCredentialsFlowManual(t *testing.T) {
 	defer db.Close()
 	runSmokeQuery(t, db)
 }
+
+func TestUnitReauthenticateOnExpiry(t *testing.T) {
+	sessionExpiredErr := &SnowflakeError{Number: 390114, Message: "Authentication token has expired."}
+	newConn := func(authenticator AuthType, reauthenticate bool, logins *[]url.Values) *snowflakeConn {
+		sc := getDefaultSnowflakeConn()
+		sc.ctx = context.Background()
+		sc.cfg.Authenticator = authenticator
+		sc.cfg.Token = "pat"
+		sc.cfg.ReauthenticateOnExpiry = reauthenticate
+		sc.rest.Connection = sc
+		sc.rest.FuncRenewSession = func(context.Context, *snowflakeRestful, time.Duration) error {
+			return sessionExpiredErr
+		}
+		sc.rest.FuncPostAuth = func(ctx context.Context, sr *snowflakeRestful, client *http.Client, params *url.Values, headers map[string]string, bodyCreator bodyCreatorType, timeout time.Duration) (*authResponse, error) {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			*logins = append(*logins, *params)
+			resp, err := postAuthSuccess(ctx, sr, client, params, headers, bodyCreator, timeout)
+			if resp != nil {
+				resp.Data.SessionID = int64(len(*logins))
+			}
+			return resp, err
+		}
+		sc.rest.TokenAccessor.SetTokens("expired", "expired master", 1)
+		sc.initSessionState(authResponseSessionInfo{})
+		return sc
+	}
+
+	t.Run("logs in again with the current session state", func(t *testing.T) {
+		var logins []url.Values
+		sc := newConn(AuthTypePat, true, &logins)
+		initialSession := *sc.initialSession
+		sc.cfg.Database = "other"
+
+		assertNilF(t, sc.rest.renewExpiredSessionToken(context.Background(), time.Second, "expired"))
+		token, masterToken, _ := sc.rest.TokenAccessor.GetTokens()
+		assertEqualE(t, token, "t")
+		assertEqualE(t, masterToken, "m")
+		assertEqualF(t, len(logins), 1)
+		assertEqualE(t, logins[0].Get("databaseName"), "other")
+		assertEqualE(t, *sc.initialSession, initialSession)
+	})
+
+	t.Run("logs in with the context of the request", func(t *testing.T) {
+		var logins []url.Values
+		sc := newConn(AuthTypePat, true, &logins)
+		connectCtx, cancel := context.WithCancel(context.Background())
+		cancel()
+		sc.ctx = withSessionID(connectCtx, 100)
+		sessionCtx := sc.ctx
+
+		assertNilF(t, sc.rest.renewExpiredSessionToken(context.Background(), time.Second, "expired"))
+		assertEqualF(t, len(logins), 1)
+		// the session ID is replaced in the context of the connection
+		assertEqualE(t, sc.ctx, sessionCtx)
+		assertEqualE(t, sc.ctx.Value(SFSessionIDKey), any(int64(1)))
+	})
+
+	t.Run("concurrently with statements", func(t *testing.T) {
+		var logins []url.Values
+		sc := newConn(AuthTypePat, true, &logins)
+		done := make(chan error)
+		go func() {
+			done <- sc.rest.renewExpiredSessionToken(context.Background(), time.Second, "expired")
+		}()
+		sc.trackSessionChanges("USE ROLE OTHER", &execResponseData{FinalRoleName: "OTHER"})
+		sc.currentSessionState()
+		assertNilF(t, <-done)
+		assertEqualE(t, len(logins), 1)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		var logins []url.Values
+		sc := newConn(AuthTypePat, false, &logins)
+		assertEqualE(t, sc.rest.renewExpiredSessionToken(context.Background(), time.Second, "expired"), error(sessionExpiredErr))
+		assertEqualE(t, len(logins), 0)
+	})
+
+	t.Run("interactive authenticator", func(t *testing.T) {
+		var logins []url.Values
+		sc := newConn(AuthTypeExternalBrowser, true, &logins)
+		assertEqualE(t, sc.rest.renewExpiredSessionToken(context.Background(), time.Second, "expired"), error(sessionExpiredErr))
+		assertEqualE(t, len(logins), 0)
+	})
+
+	t.Run("login fails", func(t *testing.T) {
+		var logins []url.Values
+		sc := newConn(AuthTypePat, true, &logins)
+		sc.rest.FuncPostAuth = postAuthFailServiceIssue
+		assertEqualE(t, sc.rest.renewExpiredSessionToken(context.Background(), time.Second, "expired"), error(sessionExpiredErr))
+	})
+}
+
+func TestUnitTokenProvider(t *testing.T) {
+	for _, authenticator := range []AuthType{AuthTypeOAuth, AuthTypePat} {
+		t.Run(authenticator.String(), func(t *testing.T) {
+			var requests []authRequestData
+			tokens := []string{"expired", "refreshed"}
+			calls := 0
+			sc := getDefaultSnowflakeConn()
+			sc.ctx = context.Background()
+			sc.cfg.Authenticator = authenticator
+			sc.cfg.TokenProvider = func(context.Context) (string, error) {
+				calls++
+				return tokens[calls-1], nil
+			}
+			sc.rest.FuncPostAuth = postAuthRejecting(&requests, expiredOAuthAccessTokenCode)
+
+			// the provider is called again after the token is rejected
+			assertNilF(t, authenticateWithConfig(sc))
+			assertEqualE(t, calls, 2)
+			assertEqualF(t, len(requests), 2)
+			assertEqualE(t, requests[0].Token, "expired")
+			assertEqualE(t, requests[1].Token, "refreshed")
+		})
+	}
+
+	t.Run("provider error", func(t *testing.T) {
+		var requests []authRequestData
+		sc := getDefaultSnowflakeConn()
+		sc.ctx = context.Background()
+		sc.cfg.Authenticator = AuthTypePat
+		sc.cfg.TokenProvider = func(context.Context) (string, error) {
+			return "", errors.New("sidecar unavailable")
+		}
+		sc.rest.FuncPostAuth = postAuthRejecting(&requests)
+		err := authenticateWithConfig(sc)
+		assertNotNilF(t, err)
+		assertEqualE(t, err.Error(), "sidecar unavailable")
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		cfg := &Config{Account: "a", User: "u", Authenticator: AuthTypePat}
+		assertNotNilE(t, fillMissingConfigParameters(cfg))
+		cfg.TokenProvider = func(context.Context) (string, error) { return "pat", nil }
+		assertNilE(t, fillMissingConfigParameters(cfg))
+	})
+
+	t.Run("reauthentication", func(t *testing.T) {
+		sc := getDefaultSnowflakeConn()
+		sc.rest.Connection = sc
+		sc.cfg.Authenticator = AuthTypeOAuth
+		sc.cfg.ReauthenticateOnExpiry = true
+		assertFalseE(t, sc.rest.canReauthenticate())
+		sc.cfg.TokenProvider = func(context.Context) (string, error) { return "token", nil }
+		assertTrueE(t, sc.rest.canReauthenticate())
+	})
+}
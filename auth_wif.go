@@ -12,6 +12,7 @@ import (
 	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/golang-jwt/jwt/v5"
 	"net/http"
 	"strings"
 	"time"
@@ -44,13 +45,13 @@ type wifAttestationProvider struct {
 	oidcCreator  wifAttestationCreator
 }
 
-func createWifAttestationProvider(ctx context.Context) *wifAttestationProvider {
+func createWifAttestationProvider(ctx context.Context, cfg *Config) *wifAttestationProvider {
 	return &wifAttestationProvider{
 		context:      ctx,
 		awsCreator:   &awsIdentityAttestationCreator{attestationService: createDefaultAwsAttestationService(ctx)},
 		gcpCreator:   nil,
 		azureCreator: nil,
-		oidcCreator:  nil,
+		oidcCreator:  &oidcIdentityAttestationCreator{cfg: cfg},
 	}
 }
 
@@ -248,3 +249,33 @@ func (creator *awsIdentityAttestationCreator) createBase64EncodedRequestCredenti
 
 	return base64.StdEncoding.EncodeToString(assertionJSON), nil
 }
+
+// oidcIdentityAttestationCreator uses the OIDC token from the config, e.g. a token issued
+// to a Kubernetes service account.
+type oidcIdentityAttestationCreator struct {
+	cfg *Config
+}
+
+func (creator *oidcIdentityAttestationCreator) createAttestation(ctx context.Context) (*wifAttestation, error) {
+	logger.Debug("Creating OIDC identity attestation...")
+	token, err := creator.cfg.getToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if token == "" {
+		logger.Debug("No OIDC token was specified.")
+		return nil, nil
+	}
+	claims := jwt.RegisteredClaims{}
+	if _, _, err = jwt.NewParser().ParseUnverified(token, &claims); err != nil {
+		return nil, fmt.Errorf("failed to parse the OIDC token: %w", err)
+	}
+	if claims.Issuer == "" || claims.Subject == "" {
+		return nil, errors.New("the OIDC token has no issuer or subject")
+	}
+	return &wifAttestation{
+		ProviderType: string(oidcWif),
+		Credential:   token,
+		Metadata:     map[string]string{"iss": claims.Issuer, "sub": claims.Subject},
+	}, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/golang-jwt/jwt/v5"
 	"testing"
 )
 
@@ -241,3 +242,45 @@ func (m *mockAwsAttestationService) GetAWSRegion() string {
 func (m *mockAwsAttestationService) GetArn() string {
 	return m.arn
 }
+
+func TestOidcIdentityAttestationCreator(t *testing.T) {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Issuer:  "https://oidc.example.com",
+		Subject: "system:serviceaccount:default:app",
+	}).SignedString([]byte("secret"))
+	assertNilF(t, err)
+
+	t.Run("token from the token provider", func(t *testing.T) {
+		creator := &oidcIdentityAttestationCreator{cfg: &Config{
+			Token:         "static",
+			TokenProvider: func(context.Context) (string, error) { return token, nil },
+		}}
+		attestation, err := creator.createAttestation(context.Background())
+		assertNilF(t, err)
+		assertEqualE(t, attestation.ProviderType, "OIDC")
+		assertEqualE(t, attestation.Credential, token)
+		assertEqualE(t, attestation.Metadata["iss"], "https://oidc.example.com")
+		assertEqualE(t, attestation.Metadata["sub"], "system:serviceaccount:default:app")
+	})
+
+	t.Run("no token", func(t *testing.T) {
+		creator := &oidcIdentityAttestationCreator{cfg: &Config{}}
+		attestation, err := creator.createAttestation(context.Background())
+		assertNilE(t, err)
+		assertNilE(t, attestation)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		creator := &oidcIdentityAttestationCreator{cfg: &Config{Token: "not a jwt"}}
+		_, err := creator.createAttestation(context.Background())
+		assertNotNilE(t, err)
+	})
+
+	t.Run("token provider error", func(t *testing.T) {
+		creator := &oidcIdentityAttestationCreator{cfg: &Config{
+			TokenProvider: func(context.Context) (string, error) { return "", errors.New("sidecar unavailable") },
+		}}
+		_, err := creator.createAttestation(context.Background())
+		assertEqualE(t, err.Error(), "sidecar unavailable")
+	})
+}
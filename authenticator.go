@@ -0,0 +1,267 @@
+package gosnowflake
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"slices"
+	"strconv"
+)
+
+// Number of times a login rejected by Snowflake is retried when the authenticator asks for it.
+// The built-in key pair authenticator tries each private key of the config instead.
+const maxLoginRetries = 2
+
+// loginRetries returns how many times a login rejected by Snowflake may be retried.
+func loginRetries(authenticator Authenticator) int {
+	if ba, ok := authenticator.(*builtinAuthenticator); ok && ba.sc.cfg.Authenticator == AuthTypeJwt {
+		return ba.sc.privateKeyCount - 1
+	}
+	return maxLoginRetries
+}
+
+// Authenticator is a login flow. When Config.CustomAuthenticator is set, the driver logs
+// in with it instead of the built-in authenticators, e.g. to use a token issued by an
+// internal token broker. The built-in authenticators implement it as well.
+type Authenticator interface {
+	// PrepareLogin fills in the login request. It is called before each login attempt.
+	PrepareLogin(ctx context.Context, req *LoginRequest) error
+	// HandleLoginError is called when Snowflake rejects a login attempt, e.g. with a
+	// 390xxx error because a credential expired. It returns true to log in again once it
+	// refreshed its credentials or answered a challenge. attempt starts at 1.
+	HandleLoginError(ctx context.Context, err *SnowflakeError, attempt int) (retry bool, e error)
+}
+
+// NonInteractiveAuthenticator is implemented by a custom Authenticator that can tell
+// whether it logs in without user interaction. Only then may a connection log in again
+// with it when its session expires and Config.ReauthenticateOnExpiry is set.
+type NonInteractiveAuthenticator interface {
+	Authenticator
+	// NonInteractive returns true if the authenticator logs in without user interaction.
+	NonInteractive() bool
+}
+
+// LoginRequest holds the fields of a login request filled in by an Authenticator.
+type LoginRequest struct {
+	Account string // Account of the Config, set by the driver
+	User    string // User of the Config, set by the driver
+
+	Authenticator     string // name of the login flow, e.g. OAUTH or SNOWFLAKE_JWT
+	LoginName         string
+	Password          string
+	Passcode          string
+	ExtAuthnDuoMethod string
+	Token             string
+	OAuthType         string
+	Provider          string
+	ProofKey          string
+	RawSAMLResponse   string
+
+	ClientEnvironment map[string]string // Added to the client environment the driver reports
+}
+
+// getAuthenticator returns the custom authenticator of the config, if any, or the
+// built-in one.
+func (sc *snowflakeConn) getAuthenticator(samlResponse []byte, proofKey []byte) Authenticator {
+	if sc.cfg.CustomAuthenticator != nil {
+		return sc.cfg.CustomAuthenticator
+	}
+	return &builtinAuthenticator{sc: sc, samlResponse: samlResponse, proofKey: proofKey}
+}
+
+// builtinAuthenticator implements the login flows selected by Config.Authenticator.
+type builtinAuthenticator struct {
+	sc           *snowflakeConn
+	samlResponse []byte
+	proofKey     []byte
+}
+
+func (ba *builtinAuthenticator) PrepareLogin(ctx context.Context, req *LoginRequest) error {
+	sc := ba.sc
+	switch sc.cfg.Authenticator {
+	case AuthTypeExternalBrowser:
+		if sc.cfg.IDToken != "" {
+			req.Authenticator = idTokenAuthenticator
+			req.Token = sc.cfg.IDToken
+			req.LoginName = sc.cfg.User
+		} else {
+			req.ProofKey = string(ba.proofKey)
+			req.Token = string(ba.samlResponse)
+			req.LoginName = sc.cfg.User
+			req.Authenticator = AuthTypeExternalBrowser.String()
+		}
+	case AuthTypeOAuth:
+		token, err := sc.cfg.getToken(ctx)
+		if err != nil {
+			return err
+		}
+		req.LoginName = sc.cfg.User
+		req.Authenticator = AuthTypeOAuth.String()
+		req.Token = token
+	case AuthTypeOkta:
+		samlResponse, err := authenticateBySAML(
+			ctx,
+			sc.rest,
+			sc.cfg.OktaURL,
+			sc.cfg.Application,
+			sc.cfg.Account,
+			sc.cfg.User,
+			sc.cfg.Password,
+			sc.cfg.DisableSamlURLCheck)
+		if err != nil {
+			return err
+		}
+		req.RawSAMLResponse = string(samlResponse)
+	case AuthTypeJwt:
+		req.Authenticator = AuthTypeJwt.String()
+
+		signer, err := ba.privateKey(ctx)
+		if err != nil {
+			return err
+		}
+		jwtTokenString, err := signJWTToken(sc.cfg, signer)
+		if err != nil {
+			return err
+		}
+		req.Token = jwtTokenString
+	case AuthTypePat:
+		logger.WithContext(ctx).Info("Programmatic access token")
+		token, err := sc.cfg.getToken(ctx)
+		if err != nil {
+			return err
+		}
+		req.Authenticator = AuthTypePat.String()
+		req.LoginName = sc.cfg.User
+		req.Token = token
+	case AuthTypeSnowflake:
+		logger.WithContext(ctx).Debug("Username and password")
+		req.LoginName = sc.cfg.User
+		req.Password = sc.cfg.Password
+		switch {
+		case sc.cfg.PasscodeInPassword:
+			req.ExtAuthnDuoMethod = "passcode"
+		case sc.cfg.Passcode != "":
+			req.Passcode = sc.cfg.Passcode
+			req.ExtAuthnDuoMethod = "passcode"
+		}
+	case AuthTypeUsernamePasswordMFA:
+		logger.WithContext(ctx).Debug("Username and password MFA")
+		req.LoginName = sc.cfg.User
+		req.Password = sc.cfg.Password
+		switch {
+		case sc.cfg.MfaToken != "":
+			req.Token = sc.cfg.MfaToken
+		case sc.cfg.PasscodeInPassword:
+			req.ExtAuthnDuoMethod = "passcode"
+		case sc.cfg.Passcode != "":
+			req.Passcode = sc.cfg.Passcode
+			req.ExtAuthnDuoMethod = "passcode"
+		}
+	case AuthTypeOAuthAuthorizationCode:
+		logger.WithContext(ctx).Debug("OAuth authorization code")
+		oauthClient, err := newOauthClient(ctx, sc.cfg)
+		if err != nil {
+			return err
+		}
+		token, err := oauthClient.authenticateByOAuthAuthorizationCode()
+		if err != nil {
+			return err
+		}
+		req.LoginName = sc.cfg.User
+		req.Token = token
+		req.OAuthType = "OAUTH_AUTHORIZATION_CODE"
+	case AuthTypeOAuthClientCredentials:
+		logger.WithContext(ctx).Debug("OAuth client credentials")
+		oauthClient, err := newOauthClient(ctx, sc.cfg)
+		if err != nil {
+			return err
+		}
+		token, err := oauthClient.authenticateByOAuthClientCredentials()
+		if err != nil {
+			return err
+		}
+		req.LoginName = sc.cfg.User
+		req.Token = token
+		req.OAuthType = "OAUTH_CLIENT_CREDENTIALS"
+	case AuthTypeWorkloadIdentityFederation:
+		if !experimentalAuthEnabled() {
+			return errors.New("workload identity authentication is not ready to use")
+		}
+		logger.WithContext(ctx).Debug("Workload Identity Federation")
+		wifAttestationProvider := createWifAttestationProvider(ctx, sc.cfg)
+		wifAttestation, err := wifAttestationProvider.getAttestation(sc.cfg.WorkloadIdentityProvider)
+		if err != nil {
+			return err
+		}
+		if wifAttestation == nil {
+			return errors.New("no workload identity attestation could be created for " + sc.cfg.WorkloadIdentityProvider)
+		}
+		req.Authenticator = AuthTypeWorkloadIdentityFederation.String()
+		req.Token = wifAttestation.Credential
+		req.Provider = wifAttestation.ProviderType
+	}
+	return nil
+}
+
+// privateKey returns the key the JWT is signed with. A login starts with the key of the
+// last successful login of the account and user, which is the first key at first, and
+// moves on to the next key each time one is rejected.
+func (ba *builtinAuthenticator) privateKey(ctx context.Context) (crypto.Signer, error) {
+	sc := ba.sc
+	signers, err := sc.cfg.getJWTSigners(ctx)
+	if err != nil {
+		return nil, err
+	}
+	fingerprints := make([]string, len(signers))
+	start := 0
+	lastFingerprint := getLastPrivateKey(sc.cfg)
+	for i, signer := range signers {
+		if fingerprints[i], err = publicKeyFingerprint(signer.Public()); err != nil {
+			return nil, err
+		}
+		if fingerprints[i] == lastFingerprint {
+			start = i
+		}
+	}
+	sc.privateKeyCount = len(signers)
+	sc.privateKeyIndex = (start + sc.rejectedPrivateKeys) % len(signers)
+	sc.privateKeyFingerprint = fingerprints[sc.privateKeyIndex]
+	return signers[sc.privateKeyIndex], nil
+}
+
+// HandleLoginError logs in again with the next private key when the JWT was rejected, until
+// each key was tried, and once more with a new OAuth access token when the cached one, or
+// the one returned by Config.TokenProvider, was rejected.
+func (ba *builtinAuthenticator) HandleLoginError(ctx context.Context, err *SnowflakeError, attempt int) (bool, error) {
+	sc := ba.sc
+	if sc.cfg.Authenticator == AuthTypeJwt {
+		if err.Number != invalidJWTTokenNumber || sc.rejectedPrivateKeys+1 >= sc.privateKeyCount {
+			return false, nil
+		}
+		logger.WithContext(ctx).Warnf("JWT signed with private key %v of %v was rejected. fingerprint: %v. trying the next key",
+			sc.privateKeyIndex+1, sc.privateKeyCount, sc.privateKeyFingerprint)
+		sc.rejectedPrivateKeys++
+		return true, nil
+	}
+	if attempt > 1 || !slices.Contains(refreshOAuthTokenErrorCodes, strconv.Itoa(err.Number)) {
+		return false, nil
+	}
+	credentialsStorage.deleteCredential(newOAuthAccessTokenSpec(sc.cfg.OauthTokenRequestURL, sc.cfg.User))
+
+	if sc.cfg.Authenticator == AuthTypeOAuthAuthorizationCode {
+		var oauthClient *oauthClient
+		var err error
+		if oauthClient, err = newOauthClient(ctx, sc.cfg); err != nil {
+			logger.Warnf("failed to create oauth client. %v", err)
+		} else {
+			if err = oauthClient.refreshToken(); err != nil {
+				logger.Warnf("cannot refresh token. %v", err)
+				credentialsStorage.deleteCredential(newOAuthRefreshTokenSpec(sc.cfg.OauthTokenRequestURL, sc.cfg.User))
+			}
+		}
+	}
+
+	// if refreshing succeeds for authorization code, we will take a token from cache
+	// if it fails, we will just run the full flow
+	return true, nil
+}
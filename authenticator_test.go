@@ -0,0 +1,155 @@
+package gosnowflake
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+type testTokenBrokerAuthenticator struct {
+	tokens   []string
+	prepared int
+	errors   []int
+}
+
+func (a *testTokenBrokerAuthenticator) PrepareLogin(_ context.Context, req *LoginRequest) error {
+	req.Authenticator = AuthTypeOAuth.String()
+	req.LoginName = req.User
+	req.Token = a.tokens[a.prepared]
+	req.ClientEnvironment = map[string]string{"TOKEN_BROKER": "test", "OS": "overridden"}
+	a.prepared++
+	return nil
+}
+
+func (a *testTokenBrokerAuthenticator) HandleLoginError(_ context.Context, err *SnowflakeError, _ int) (bool, error) {
+	a.errors = append(a.errors, err.Number)
+	return err.Number == 390303, nil
+}
+
+// postAuthRejecting rejects the logins with the given codes, in order, then accepts them.
+func postAuthRejecting(requests *[]authRequestData, codes ...string) func(context.Context, *snowflakeRestful, *http.Client, *url.Values, map[string]string, bodyCreatorType, time.Duration) (*authResponse, error) {
+	return func(_ context.Context, _ *snowflakeRestful, _ *http.Client, _ *url.Values, _ map[string]string, bodyCreator bodyCreatorType, _ time.Duration) (*authResponse, error) {
+		body, err := bodyCreator()
+		if err != nil {
+			return nil, err
+		}
+		var ar authRequest
+		if err = json.Unmarshal(body, &ar); err != nil {
+			return nil, err
+		}
+		*requests = append(*requests, ar.Data)
+		if len(*requests) <= len(codes) {
+			return &authResponse{Success: false, Code: codes[len(*requests)-1], Message: "rejected"}, nil
+		}
+		return &authResponse{Success: true, Data: authResponseMain{Token: "t", MasterToken: "m"}}, nil
+	}
+}
+
+func newCustomAuthenticatorTestConn(authenticator Authenticator, postAuth func(context.Context, *snowflakeRestful, *http.Client, *url.Values, map[string]string, bodyCreatorType, time.Duration) (*authResponse, error)) *snowflakeConn {
+	sc := getDefaultSnowflakeConn()
+	sc.ctx = context.Background()
+	sc.cfg.Password = ""
+	sc.cfg.CustomAuthenticator = authenticator
+	sc.rest.FuncPostAuth = postAuth
+	return sc
+}
+
+func TestUnitCustomAuthenticator(t *testing.T) {
+	var requests []authRequestData
+	authenticator := &testTokenBrokerAuthenticator{tokens: []string{"brokered", "brokered"}}
+	sc := newCustomAuthenticatorTestConn(authenticator, postAuthRejecting(&requests))
+	assertNilF(t, fillMissingConfigParameters(sc.cfg))
+	assertEqualE(t, sc.cfg.Authenticator, AuthTypeCustom)
+
+	assertNilF(t, authenticateWithConfig(sc))
+	assertEqualF(t, len(requests), 1)
+	assertEqualE(t, requests[0].Authenticator, "OAUTH")
+	assertEqualE(t, requests[0].LoginName, "u")
+	assertEqualE(t, requests[0].Token, "brokered")
+	assertEqualE(t, requests[0].AccountName, "a")
+	token, _, _ := sc.rest.TokenAccessor.GetTokens()
+	assertEqualE(t, token, "t")
+
+	body, err := createRequestBody(context.Background(), sc, nil, authRequestClientEnvironment{Os: "linux"}, nil, nil)
+	assertNilF(t, err)
+	var ar map[string]map[string]interface{}
+	assertNilF(t, json.Unmarshal(body, &ar))
+	clientEnvironment := ar["data"]["CLIENT_ENVIRONMENT"].(map[string]interface{})
+	assertEqualE(t, clientEnvironment["TOKEN_BROKER"], "test")
+	assertEqualE(t, clientEnvironment["OS"], "linux")
+}
+
+func TestUnitCustomAuthenticatorRetriesRejectedLogin(t *testing.T) {
+	var requests []authRequestData
+	authenticator := &testTokenBrokerAuthenticator{tokens: []string{"expired", "refreshed"}}
+	sc := newCustomAuthenticatorTestConn(authenticator, postAuthRejecting(&requests, invalidOAuthAccessTokenCode))
+
+	assertNilF(t, authenticateWithConfig(sc))
+	assertEqualF(t, len(requests), 2)
+	assertEqualE(t, requests[0].Token, "expired")
+	assertEqualE(t, requests[1].Token, "refreshed")
+	assertDeepEqualE(t, authenticator.errors, []int{390303})
+}
+
+func TestUnitCustomAuthenticatorLimitsRetries(t *testing.T) {
+	var requests []authRequestData
+	authenticator := &testTokenBrokerAuthenticator{tokens: []string{"a", "b", "c", "d"}}
+	code := invalidOAuthAccessTokenCode
+	sc := newCustomAuthenticatorTestConn(authenticator, postAuthRejecting(&requests, code, code, code, code))
+
+	err := authenticateWithConfig(sc)
+	assertNotNilF(t, err)
+	assertEqualE(t, err.(*SnowflakeError).Number, 390303)
+	assertEqualE(t, len(requests), maxLoginRetries+1)
+}
+
+func TestUnitCustomAuthenticatorDoesNotRetryOtherErrors(t *testing.T) {
+	var requests []authRequestData
+	authenticator := &testTokenBrokerAuthenticator{tokens: []string{"a", "b"}}
+	sc := newCustomAuthenticatorTestConn(authenticator, postAuthRejecting(&requests, "390100"))
+
+	err := authenticateWithConfig(sc)
+	assertNotNilF(t, err)
+	assertEqualE(t, err.(*SnowflakeError).Number, 390100)
+	assertEqualE(t, len(requests), 1)
+	assertDeepEqualE(t, authenticator.errors, []int{390100})
+}
+
+type testNonInteractiveAuthenticator struct {
+	testTokenBrokerAuthenticator
+	nonInteractive bool
+}
+
+func (a *testNonInteractiveAuthenticator) NonInteractive() bool {
+	return a.nonInteractive
+}
+
+func TestUnitCustomAuthenticatorReauthentication(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		authenticator Authenticator
+		expected      bool
+	}{
+		{"not opted in", &testTokenBrokerAuthenticator{}, false},
+		{"interactive", &testNonInteractiveAuthenticator{}, false},
+		{"non-interactive", &testNonInteractiveAuthenticator{nonInteractive: true}, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			sc := newCustomAuthenticatorTestConn(tc.authenticator, nil)
+			sc.rest.Connection = sc
+			sc.cfg.Authenticator = AuthTypeCustom
+			sc.cfg.ReauthenticateOnExpiry = true
+			assertEqualE(t, sc.rest.canReauthenticate(), tc.expected)
+		})
+	}
+}
+
+func TestUnitEmptyCustomAuthenticator(t *testing.T) {
+	cfg := &Config{Account: "a", Authenticator: AuthTypeCustom}
+	err := fillMissingConfigParameters(cfg)
+	assertNotNilF(t, err)
+	assertEqualE(t, err.(*SnowflakeError).Number, ErrCodeEmptyCustomAuthenticator)
+}
@@ -404,6 +404,62 @@ func TestUploadFileWithAzureUploadNeedsRetry(t *testing.T) {
 	}
 }
 
+func TestUploadFileWithAzureRetryPolicyNotRetryable(t *testing.T) {
+	info := execResponseStageInfo{
+		Location:     "azblob/storage/users/456/",
+		LocationType: "AZURE",
+	}
+	dir, err := os.Getwd()
+	assertNilF(t, err)
+	azureCli, err := new(snowflakeAzureClient).createClient(&info, false)
+	assertNilF(t, err)
+
+	uploads := 0
+	policy := &recordingRetryPolicy{retryable: false}
+	uploadMeta := fileMetadata{
+		name:               "data1.txt.gz",
+		stageLocationType:  "AZURE",
+		parallel:           int64(100),
+		client:             azureCli,
+		sha256Digest:       "123456789abcdef",
+		stageInfo:          &info,
+		dstFileName:        "data1.txt.gz",
+		srcFileName:        path.Join(dir, "/test_data/put_get_1.txt"),
+		encryptMeta:        testEncryptionMeta(),
+		overwrite:          true,
+		dstCompressionType: compressionTypes["GZIP"],
+		options: &SnowflakeFileTransferOptions{
+			MultiPartThreshold: dataSizeThreshold,
+		},
+		mockAzureClient: &azureObjectAPIMock{
+			UploadFileFunc: func(ctx context.Context, file *os.File, o *azblob.UploadFileOptions) (azblob.UploadFileResponse, error) {
+				uploads++
+				return azblob.UploadFileResponse{}, &azcore.ResponseError{
+					ErrorCode:   "12345",
+					StatusCode:  500,
+					RawResponse: &http.Response{StatusCode: http.StatusInternalServerError, Body: &fakeResponseBody{body: []byte("{}")}},
+				}
+			},
+		},
+		sfa: &snowflakeFileTransferAgent{
+			sc: &snowflakeConn{
+				cfg: &Config{RetryPolicy: policy},
+			},
+		},
+	}
+	uploadMeta.realSrcFileName = uploadMeta.srcFileName
+	fi, err := os.Stat(uploadMeta.srcFileName)
+	assertNilF(t, err)
+	uploadMeta.uploadSize = fi.Size()
+
+	err = new(remoteStorageUtil).uploadOneFile(&uploadMeta)
+	assertNotNilF(t, err, "should have raised an error")
+	assertEqualE(t, uploadMeta.resStatus, needRetry)
+	assertEqualE(t, uploads, 1)
+	assertDeepEqualE(t, policy.kinds, []RetryRequestKind{CloudStorageRetryRequest})
+	assertEqualE(t, len(policy.attempts), 0)
+}
+
 func TestDownloadOneFileToAzureFailed(t *testing.T) {
 	info := execResponseStageInfo{
 		Location:     "azblob/rwyitestacco/users/1234/",
@@ -222,7 +222,7 @@ func (sc *snowflakeConn) processBindings(
 		req.Bindings = nil
 		req.BindStage = uploader.stagePath
 	} else {
-		req.Bindings, err = getBindValues(bindings, sc.cfg.Params)
+		req.Bindings, err = getBindValues(bindings, sessionParametersFor(ctx, sc.cfg.Params))
 		if err != nil {
 			return err
 		}
@@ -265,6 +265,8 @@ func getBindValues(bindings []driver.NamedValue, params map[string]*string) (map
 			}
 			if t == nullType || t == unSupportedType {
 				t = textType // if null or not supported, pass to GS as text
+			} else if t == vectorType {
+				t = textType // vectors are bound as their text representation, e.g. ?::VECTOR(FLOAT, 3)
 			} else if t == nilObjectType || t == mapType || t == nilMapType {
 				t = objectType
 			} else if t == nilArrayType {
@@ -321,7 +323,8 @@ func supportedArrayBind(nv *driver.NamedValue) bool {
 		reflect.TypeOf(&stringArray{}), reflect.TypeOf(&byteArray{}),
 		reflect.TypeOf(&timestampNtzArray{}), reflect.TypeOf(&timestampLtzArray{}),
 		reflect.TypeOf(&timestampTzArray{}), reflect.TypeOf(&dateArray{}),
-		reflect.TypeOf(&timeArray{}):
+		reflect.TypeOf(&timeArray{}), reflect.TypeOf(&bigIntArray{}),
+		reflect.TypeOf(&bigFloatArray{}), reflect.TypeOf(&decimalArray{}):
 		return true
 	case reflect.TypeOf([]uint8{}):
 		// internal binding ts mode
@@ -332,7 +335,7 @@ func supportedArrayBind(nv *driver.NamedValue) bool {
 		if len(val) == 0 {
 			return true // for null binds
 		}
-		if fixedType <= snowflakeType(val[0]) && snowflakeType(val[0]) <= unSupportedType {
+		if t := snowflakeType(val[0]); fixedType <= t && t <= unSupportedType || t == vectorType {
 			return true
 		}
 		return false
@@ -356,6 +359,10 @@ func supportedNullBind(nv *driver.NamedValue) bool {
 	return false
 }
 
+func supportedDecimalBind(nv *driver.NamedValue) bool {
+	return isBigNumber(nv.Value)
+}
+
 func supportedStructuredObjectWriterBind(nv *driver.NamedValue) bool {
 	if _, ok := nv.Value.(StructuredObjectWriter); ok {
 		return true
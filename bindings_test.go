@@ -1127,6 +1127,44 @@ func TestBulkArrayMultiPartBindingInt(t *testing.T) {
 	})
 }
 
+func TestBindingBigNumbers(t *testing.T) {
+	runDBTest(t, func(dbt *DBTest) {
+		dbt.mustExec("create or replace table binding_test (c1 number(38,0), c2 number(38,10), c3 number(38,4))")
+		bigInt, _ := new(big.Int).SetString("12345678901234567890123456789012345678", 10)
+		bigFloat, _, _ := big.ParseFloat("1234567890123456789012345678.0123456789", 10, 256, big.ToNearestEven)
+		decimal, err := ParseDecimal("-1234567890123456789012345678901234.5678")
+		assertNilF(t, err)
+		dbt.mustExec("insert into binding_test values (?, ?, ?)", bigInt, bigFloat, decimal)
+
+		bigInts := []*big.Int{big.NewInt(1), nil}
+		bigFloats := []*big.Float{big.NewFloat(0.5), nil}
+		decimals := []Decimal{mustNewDecimal(10001, 4), mustNewDecimal(-2, 0)}
+		dbt.mustExec("insert into binding_test values (?, ?, ?)", Array(&bigInts), Array(&bigFloats), Array(&decimals))
+
+		rows := dbt.mustQuery("select c1, c2, c3 from binding_test order by c3")
+		defer func() {
+			assertNilF(t, rows.Close())
+		}()
+		var c1, c2, c3 sql.NullString
+		var d Decimal
+		assertTrueF(t, rows.Next())
+		assertNilF(t, rows.Scan(&c1, &c2, &d))
+		assertEqualE(t, c1.String, bigInt.String())
+		assertEqualE(t, c2.String, "1234567890123456789012345678.0123456789")
+		assertEqualE(t, d.String(), decimal.String())
+		assertTrueF(t, rows.Next())
+		assertNilF(t, rows.Scan(&c1, &c2, &c3))
+		assertFalseE(t, c1.Valid)
+		assertFalseE(t, c2.Valid)
+		assertEqualE(t, c3.String, "-2.0000")
+		assertTrueF(t, rows.Next())
+		assertNilF(t, rows.Scan(&c1, &c2, &c3))
+		assertEqualE(t, c1.String, "1")
+		assertEqualE(t, c2.String, "0.5000000000")
+		assertEqualE(t, c3.String, "1.0001")
+	})
+}
+
 func TestBulkArrayMultiPartBindingWithNull(t *testing.T) {
 	runDBTest(t, func(dbt *DBTest) {
 		dbt.mustExec("create or replace table binding_test (c1 integer, c2 string)")
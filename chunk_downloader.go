@@ -204,6 +204,7 @@ func (scd *snowflakeChunkDownloader) next() (chunkRowType, error) {
 			break
 		}
 
+		waitStart := time.Now()
 		scd.ChunksMutex.Lock()
 		if scd.CurrentChunkIndex > 0 {
 			scd.Chunks[scd.CurrentChunkIndex-1] = nil // detach the previously used chunk
@@ -225,6 +226,7 @@ func (scd *snowflakeChunkDownloader) next() (chunkRowType, error) {
 		logger.WithContext(scd.ctx).Debugf("ready: chunk %v", scd.CurrentChunkIndex+1)
 		scd.CurrentChunk = scd.Chunks[scd.CurrentChunkIndex]
 		scd.ChunksMutex.Unlock()
+		getMetrics(scd.getConfig()).recordChunkWait(scd.ctx, time.Since(waitStart))
 		scd.CurrentChunkSize = len(scd.CurrentChunk)
 
 		// kick off the next download
@@ -243,6 +245,13 @@ func (scd *snowflakeChunkDownloader) reset() {
 	scd.Chunks = nil // detach all chunks. No way to go backward without reinitialize it.
 }
 
+func (scd *snowflakeChunkDownloader) getConfig() *Config {
+	if scd.sc == nil {
+		return nil
+	}
+	return scd.sc.cfg
+}
+
 func (scd *snowflakeChunkDownloader) getChunkMetas() []execResponseChunk {
 	return scd.ChunkMetas
 }
@@ -274,7 +283,7 @@ func (scd *snowflakeChunkDownloader) getConfigParams() (map[string]*string, erro
 	if scd.sc == nil || scd.sc.cfg == nil {
 		return map[string]*string{}, errNoConnection
 	}
-	return scd.sc.cfg.Params, nil
+	return sessionParametersFor(scd.ctx, scd.sc.cfg.Params), nil
 }
 
 func getChunk(
@@ -289,7 +298,9 @@ func getChunk(
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse URL: %w", err)
 	}
-	return newRetryHTTP(ctx, sc.rest.Client, http.NewRequest, u, headers, timeout, sc.rest.MaxRetryCount, sc.currentTimeProvider, sc.cfg).execute()
+	return newRetryHTTP(ctx, sc.rest.Client, http.NewRequest, u, headers, timeout, sc.rest.MaxRetryCount, sc.currentTimeProvider, sc.cfg).
+		setKind(ChunkDownloadRetryRequest).
+		execute()
 }
 
 func (scd *snowflakeChunkDownloader) startArrowBatches() error {
@@ -377,7 +388,13 @@ func downloadChunk(ctx context.Context, scd *snowflakeChunkDownloader, idx int)
 	}
 }
 
-func downloadChunkHelper(ctx context.Context, scd *snowflakeChunkDownloader, idx int) error {
+func downloadChunkHelper(ctx context.Context, scd *snowflakeChunkDownloader, idx int) (err error) {
+	cfg := scd.getConfig()
+	ctx, span := startSpan(ctx, cfg, chunkDownloadSpanName,
+		chunkIndexAttribute.Int(idx),
+		chunkRowCountAttribute.Int(scd.ChunkMetas[idx].RowCount))
+	defer func() { endSpan(span, err) }()
+
 	headers := make(map[string]string)
 	if len(scd.ChunkHeader) > 0 {
 		logger.WithContext(ctx).Debug("chunk header is provided.")
@@ -412,8 +429,13 @@ func downloadChunkHelper(ctx context.Context, scd *snowflakeChunkDownloader, idx
 		}
 	}
 
-	bufStream := bufio.NewReader(resp.Body)
-	return decodeChunk(ctx, scd, idx, bufStream)
+	body := &countingReader{r: resp.Body}
+	start := time.Now()
+	if err = decodeChunk(ctx, scd, idx, bufio.NewReader(body)); err != nil {
+		return err
+	}
+	getMetrics(cfg).recordChunkDownload(ctx, scd.getQueryResultFormat(), body.n, time.Since(start))
+	return nil
 }
 
 func decodeChunk(ctx context.Context, scd *snowflakeChunkDownloader, idx int, bufStream *bufio.Reader) error {
@@ -677,7 +699,9 @@ func (f *httpStreamChunkFetcher) fetch(URL string, rows chan<- []*string) error
 	if err != nil {
 		return fmt.Errorf("parsing URL: %w", err)
 	}
-	res, err := newRetryHTTP(context.Background(), f.client, http.NewRequest, fullURL, f.headers, 0, 0, defaultTimeProvider, nil).execute()
+	res, err := newRetryHTTP(context.Background(), f.client, http.NewRequest, fullURL, f.headers, 0, 0, defaultTimeProvider, nil).
+		setKind(ChunkDownloadRetryRequest).
+		execute()
 	if err != nil {
 		return fmt.Errorf("executing HTTP request: %w", err)
 	}
@@ -0,0 +1,210 @@
+package gosnowflake
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	defaultCircuitBreakerFailureThreshold = 5
+	defaultCircuitBreakerOpenTimeout      = 30 * time.Second
+)
+
+// CircuitBreakerState is the state of a circuit breaker.
+type CircuitBreakerState int
+
+const (
+	// CircuitBreakerClosed lets all requests through.
+	CircuitBreakerClosed CircuitBreakerState = iota
+	// CircuitBreakerOpen rejects all requests with ErrCircuitBreakerOpen.
+	CircuitBreakerOpen
+	// CircuitBreakerHalfOpen lets a single probe request through to decide whether to close again.
+	CircuitBreakerHalfOpen
+)
+
+func (s CircuitBreakerState) String() string {
+	switch s {
+	case CircuitBreakerClosed:
+		return "closed"
+	case CircuitBreakerOpen:
+		return "open"
+	case CircuitBreakerHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("CircuitBreakerState(%d)", int(s))
+}
+
+// CircuitBreakerEndpoint is the class of endpoints a circuit breaker guards.
+type CircuitBreakerEndpoint int
+
+const (
+	// CircuitBreakerLogin guards login, token and authenticator requests.
+	CircuitBreakerLogin CircuitBreakerEndpoint = iota
+	// CircuitBreakerQuery guards query submission and query result requests.
+	CircuitBreakerQuery
+	// CircuitBreakerChunkStorage guards result chunk downloads from cloud storage.
+	CircuitBreakerChunkStorage
+)
+
+func (e CircuitBreakerEndpoint) String() string {
+	switch e {
+	case CircuitBreakerLogin:
+		return "login"
+	case CircuitBreakerQuery:
+		return "query"
+	case CircuitBreakerChunkStorage:
+		return "chunk storage"
+	}
+	return fmt.Sprintf("CircuitBreakerEndpoint(%d)", int(e))
+}
+
+// CircuitBreakerConfig enables a circuit breaker around Snowflake endpoints. It is set
+// with Config.CircuitBreaker.
+//
+// A circuit breaker is kept per host and endpoint class, and it is shared by all
+// connections of the process. It opens after FailureThreshold consecutive failed
+// attempts, i.e. connection errors or 5xx, 408 and 429 responses. Requests are then
+// rejected without being sent until OpenTimeout has passed, after which a single
+// probe request is let through. The circuit breaker closes if the probe succeeds and
+// opens again otherwise.
+type CircuitBreakerConfig struct {
+	FailureThreshold int           // consecutive failures opening the circuit breaker. 5 if not set
+	OpenTimeout      time.Duration // time the circuit breaker stays open before a probe. 30s if not set
+	// OnStateChange is called, if set, whenever a circuit breaker changes its state.
+	OnStateChange func(host string, endpoint CircuitBreakerEndpoint, from CircuitBreakerState, to CircuitBreakerState)
+}
+
+func (cbc *CircuitBreakerConfig) failureThreshold() int {
+	if cbc.FailureThreshold <= 0 {
+		return defaultCircuitBreakerFailureThreshold
+	}
+	return cbc.FailureThreshold
+}
+
+func (cbc *CircuitBreakerConfig) openTimeout() time.Duration {
+	if cbc.OpenTimeout <= 0 {
+		return defaultCircuitBreakerOpenTimeout
+	}
+	return cbc.OpenTimeout
+}
+
+type circuitBreakerKey struct {
+	host     string
+	endpoint CircuitBreakerEndpoint
+}
+
+type circuitBreaker struct {
+	key      circuitBreakerKey
+	mu       sync.Mutex
+	state    CircuitBreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+var (
+	circuitBreakersMutex = &sync.Mutex{}
+	circuitBreakers      = make(map[circuitBreakerKey]*circuitBreaker)
+)
+
+// getCircuitBreaker returns the circuit breaker guarding the request, or nil if the
+// circuit breaker is not enabled or the request is not guarded.
+func getCircuitBreaker(cfg *Config, u *url.URL, kind RetryRequestKind) *circuitBreaker {
+	if cfg == nil || cfg.CircuitBreaker == nil || u == nil {
+		return nil
+	}
+	var endpoint CircuitBreakerEndpoint
+	switch kind {
+	case LoginRetryRequest:
+		endpoint = CircuitBreakerLogin
+	case QueryRetryRequest, QueryResultRetryRequest:
+		endpoint = CircuitBreakerQuery
+	case ChunkDownloadRetryRequest:
+		endpoint = CircuitBreakerChunkStorage
+	default:
+		return nil
+	}
+	key := circuitBreakerKey{host: u.Host, endpoint: endpoint}
+	circuitBreakersMutex.Lock()
+	defer circuitBreakersMutex.Unlock()
+	cb, ok := circuitBreakers[key]
+	if !ok {
+		cb = &circuitBreaker{key: key}
+		circuitBreakers[key] = cb
+	}
+	return cb
+}
+
+// allow returns an error if the request must be rejected without being sent.
+func (cb *circuitBreaker) allow(cfg *CircuitBreakerConfig) error {
+	cb.mu.Lock()
+	from := cb.state
+	switch cb.state {
+	case CircuitBreakerOpen:
+		if time.Since(cb.openedAt) < cfg.openTimeout() {
+			cb.mu.Unlock()
+			return errCircuitBreakerOpen(cb.key.endpoint, cb.key.host)
+		}
+		cb.state = CircuitBreakerHalfOpen
+		cb.probing = true
+	case CircuitBreakerHalfOpen:
+		if cb.probing {
+			cb.mu.Unlock()
+			return errCircuitBreakerOpen(cb.key.endpoint, cb.key.host)
+		}
+		cb.probing = true
+	}
+	to := cb.state
+	cb.mu.Unlock()
+	cb.notify(cfg, from, to)
+	return nil
+}
+
+// record reports the outcome of a request let through by allow.
+func (cb *circuitBreaker) record(cfg *CircuitBreakerConfig, failed bool) {
+	cb.mu.Lock()
+	from := cb.state
+	cb.probing = false
+	if !failed {
+		cb.failures = 0
+		cb.state = CircuitBreakerClosed
+	} else {
+		cb.failures++
+		if cb.state == CircuitBreakerHalfOpen || cb.failures >= cfg.failureThreshold() {
+			cb.state = CircuitBreakerOpen
+			cb.openedAt = time.Now()
+		}
+	}
+	to := cb.state
+	cb.mu.Unlock()
+	cb.notify(cfg, from, to)
+}
+
+// release gives up a request let through by allow without an outcome, e.g. when its
+// context is canceled.
+func (cb *circuitBreaker) release() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.probing = false
+}
+
+func (cb *circuitBreaker) notify(cfg *CircuitBreakerConfig, from CircuitBreakerState, to CircuitBreakerState) {
+	if from == to {
+		return
+	}
+	logger.Infof("circuit breaker for %v requests to %v changed from %v to %v", cb.key.endpoint, cb.key.host, from, to)
+	if cfg.OnStateChange != nil {
+		cfg.OnStateChange(cb.key.host, cb.key.endpoint, from, to)
+	}
+}
+
+// isCircuitBreakerFailure tells whether an attempt counts as a failure for the circuit breaker.
+func isCircuitBreakerFailure(res *http.Response, err error) bool {
+	if res == nil {
+		return err != nil
+	}
+	return isRetryableStatus(res.StatusCode)
+}
@@ -0,0 +1,210 @@
+package gosnowflake
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+type circuitBreakerTransition struct {
+	host     string
+	endpoint CircuitBreakerEndpoint
+	from     CircuitBreakerState
+	to       CircuitBreakerState
+}
+
+type circuitBreakerRecorder struct {
+	mu          sync.Mutex
+	transitions []circuitBreakerTransition
+}
+
+func (r *circuitBreakerRecorder) onStateChange(host string, endpoint CircuitBreakerEndpoint, from CircuitBreakerState, to CircuitBreakerState) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.transitions = append(r.transitions, circuitBreakerTransition{host, endpoint, from, to})
+}
+
+func (r *circuitBreakerRecorder) states() []CircuitBreakerState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	states := make([]CircuitBreakerState, 0, len(r.transitions))
+	for _, transition := range r.transitions {
+		states = append(states, transition.to)
+	}
+	return states
+}
+
+func resetCircuitBreakers(t *testing.T) {
+	reset := func() {
+		circuitBreakersMutex.Lock()
+		defer circuitBreakersMutex.Unlock()
+		circuitBreakers = make(map[circuitBreakerKey]*circuitBreaker)
+	}
+	reset()
+	t.Cleanup(reset)
+}
+
+func newCircuitBreakerTestConfig(recorder *circuitBreakerRecorder, openTimeout time.Duration) *Config {
+	return &Config{
+		RetryPolicy: &recordingRetryPolicy{retryable: true, wait: time.Millisecond},
+		CircuitBreaker: &CircuitBreakerConfig{
+			FailureThreshold: 2,
+			OpenTimeout:      openTimeout,
+			OnStateChange:    recorder.onStateChange,
+		},
+	}
+}
+
+func TestCircuitBreakerOpensAfterConsecutiveFailures(t *testing.T) {
+	resetCircuitBreakers(t)
+	recorder := &circuitBreakerRecorder{}
+	cfg := newCircuitBreakerTestConfig(recorder, time.Minute)
+	client := &fakeHTTPClient{
+		statusCode: http.StatusServiceUnavailable,
+		t:          t,
+	}
+	urlPtr, err := url.Parse("https://fakeaccountbreaker.snowflakecomputing.com:443/queries/v1/query-request?" + requestIDKey + "=testid")
+	assertNilF(t, err, "failed to parse the test URL")
+
+	_, err = newRetryHTTP(context.Background(),
+		client,
+		emptyRequest, urlPtr, make(map[string]string), time.Minute, 5, defaultTimeProvider, cfg).doPost().setBody([]byte{0}).execute()
+	var se *SnowflakeError
+	assertTrueF(t, errors.As(err, &se), "expected a SnowflakeError")
+	assertEqualE(t, se.Number, ErrCircuitBreakerOpen)
+	assertEqualE(t, se.Error(), "261011: circuit breaker is open for query requests to fakeaccountbreaker.snowflakecomputing.com:443")
+	assertEqualE(t, client.retryNumber, 2)
+	assertEqualF(t, len(recorder.transitions), 1)
+	assertEqualE(t, recorder.transitions[0], circuitBreakerTransition{
+		host:     "fakeaccountbreaker.snowflakecomputing.com:443",
+		endpoint: CircuitBreakerQuery,
+		from:     CircuitBreakerClosed,
+		to:       CircuitBreakerOpen,
+	})
+
+	// other connections fail fast as well
+	_, err = newRetryHTTP(context.Background(),
+		client,
+		emptyRequest, urlPtr, make(map[string]string), time.Minute, 5, defaultTimeProvider, cfg).doPost().setBody([]byte{0}).execute()
+	assertTrueF(t, errors.As(err, &se), "expected a SnowflakeError")
+	assertEqualE(t, se.Number, ErrCircuitBreakerOpen)
+	assertEqualE(t, client.retryNumber, 2)
+
+	// login requests to the same host are guarded separately
+	loginURL, err := url.Parse("https://fakeaccountbreaker.snowflakecomputing.com:443" + loginRequestPath)
+	assertNilF(t, err, "failed to parse the test URL")
+	client = &fakeHTTPClient{cnt: 1, success: true, t: t}
+	res, err := newRetryHTTP(context.Background(),
+		client,
+		emptyRequest, loginURL, make(map[string]string), time.Minute, 5, defaultTimeProvider, cfg).doPost().setBody([]byte{0}).execute()
+	assertNilF(t, err)
+	assertEqualE(t, res.StatusCode, http.StatusOK)
+}
+
+func TestCircuitBreakerHalfOpenProbe(t *testing.T) {
+	resetCircuitBreakers(t)
+	recorder := &circuitBreakerRecorder{}
+	cfg := newCircuitBreakerTestConfig(recorder, 20*time.Millisecond)
+	urlPtr, err := url.Parse("https://sfc-stage.s3.amazonaws.com/results/chunk_0")
+	assertNilF(t, err, "failed to parse the test URL")
+	execute := func(client *fakeHTTPClient) (*http.Response, error) {
+		return newRetryHTTP(context.Background(),
+			client,
+			emptyRequest, urlPtr, make(map[string]string), time.Minute, 5, defaultTimeProvider, cfg).setKind(ChunkDownloadRetryRequest).execute()
+	}
+
+	_, err = execute(&fakeHTTPClient{statusCode: http.StatusBadGateway, t: t})
+	assertNotNilF(t, err)
+	assertDeepEqualE(t, recorder.states(), []CircuitBreakerState{CircuitBreakerOpen})
+
+	// a failed probe opens the circuit breaker again
+	time.Sleep(30 * time.Millisecond)
+	client := &fakeHTTPClient{statusCode: http.StatusBadGateway, t: t}
+	_, err = execute(client)
+	assertNotNilF(t, err)
+	assertEqualE(t, client.retryNumber, 1)
+	assertDeepEqualE(t, recorder.states(), []CircuitBreakerState{CircuitBreakerOpen, CircuitBreakerHalfOpen, CircuitBreakerOpen})
+
+	// a successful probe closes it
+	time.Sleep(30 * time.Millisecond)
+	client = &fakeHTTPClient{cnt: 1, success: true, t: t}
+	res, err := execute(client)
+	assertNilF(t, err)
+	assertEqualE(t, res.StatusCode, http.StatusOK)
+	assertDeepEqualE(t, recorder.states(), []CircuitBreakerState{CircuitBreakerOpen, CircuitBreakerHalfOpen, CircuitBreakerOpen, CircuitBreakerHalfOpen, CircuitBreakerClosed})
+	assertEqualE(t, recorder.transitions[4].endpoint, CircuitBreakerChunkStorage)
+}
+
+func TestCircuitBreakerSingleProbe(t *testing.T) {
+	resetCircuitBreakers(t)
+	recorder := &circuitBreakerRecorder{}
+	cfg := newCircuitBreakerTestConfig(recorder, time.Millisecond)
+	urlPtr, err := url.Parse("https://fakeaccountprobe.snowflakecomputing.com:443" + loginRequestPath)
+	assertNilF(t, err, "failed to parse the test URL")
+	cb := getCircuitBreaker(cfg, urlPtr, LoginRetryRequest)
+	assertNotNilF(t, cb)
+	cb.record(cfg.CircuitBreaker, true)
+	cb.record(cfg.CircuitBreaker, true)
+	time.Sleep(5 * time.Millisecond)
+
+	assertNilE(t, cb.allow(cfg.CircuitBreaker))
+	assertNotNilE(t, cb.allow(cfg.CircuitBreaker), "only one probe should be let through")
+	cb.release()
+	assertNilE(t, cb.allow(cfg.CircuitBreaker), "a released probe should be replaced")
+	cb.record(cfg.CircuitBreaker, false)
+	assertNilE(t, cb.allow(cfg.CircuitBreaker))
+	assertNilE(t, cb.allow(cfg.CircuitBreaker))
+}
+
+func TestGetCircuitBreaker(t *testing.T) {
+	resetCircuitBreakers(t)
+	urlPtr, err := url.Parse("https://fakeaccount.snowflakecomputing.com:443" + queryRequestPath)
+	assertNilF(t, err, "failed to parse the test URL")
+	cfg := &Config{CircuitBreaker: &CircuitBreakerConfig{}}
+
+	assertNilE(t, getCircuitBreaker(nil, urlPtr, QueryRetryRequest))
+	assertNilE(t, getCircuitBreaker(&Config{}, urlPtr, QueryRetryRequest))
+	assertNilE(t, getCircuitBreaker(cfg, urlPtr, OtherRetryRequest))
+	assertNilE(t, getCircuitBreaker(cfg, urlPtr, CloudStorageRetryRequest))
+
+	query := getCircuitBreaker(cfg, urlPtr, QueryRetryRequest)
+	assertNotNilF(t, query)
+	assertTrueE(t, query == getCircuitBreaker(&Config{CircuitBreaker: &CircuitBreakerConfig{}}, urlPtr, QueryResultRetryRequest))
+	assertFalseE(t, query == getCircuitBreaker(cfg, urlPtr, LoginRetryRequest))
+	assertEqualE(t, cfg.CircuitBreaker.failureThreshold(), defaultCircuitBreakerFailureThreshold)
+	assertEqualE(t, cfg.CircuitBreaker.openTimeout(), defaultCircuitBreakerOpenTimeout)
+}
+
+func TestCircuitBreakerWithWiremock(t *testing.T) {
+	resetCircuitBreakers(t)
+	wiremock.registerMappings(t,
+		newWiremockMapping("auth/password/successful_flow.json"),
+		newWiremockMapping("query_service_unavailable.json"),
+	)
+	recorder := &circuitBreakerRecorder{}
+	cfg := wiremock.connectionConfig()
+	cfg.CircuitBreaker = &CircuitBreakerConfig{
+		FailureThreshold: 2,
+		OpenTimeout:      time.Minute,
+		OnStateChange:    recorder.onStateChange,
+	}
+	db := sql.OpenDB(NewConnector(SnowflakeDriver{}, *cfg))
+	defer db.Close()
+
+	_, err := db.Query("SELECT 1")
+	var se *SnowflakeError
+	assertTrueF(t, errors.As(err, &se), "expected a SnowflakeError")
+	assertEqualE(t, se.Number, ErrCircuitBreakerOpen)
+	assertDeepEqualE(t, recorder.states(), []CircuitBreakerState{CircuitBreakerOpen})
+
+	start := time.Now()
+	_, err = db.Query("SELECT 1")
+	assertTrueF(t, errors.As(err, &se), "expected a SnowflakeError")
+	assertEqualE(t, se.Number, ErrCircuitBreakerOpen)
+	assertTrueE(t, time.Since(start) < time.Second, "an open circuit breaker should fail fast")
+}
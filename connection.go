@@ -14,11 +14,13 @@ import (
 	"net/url"
 	"regexp"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/apache/arrow-go/v18/arrow/ipc"
 )
@@ -39,15 +41,23 @@ const (
 const (
 	statementTypeIDSelect           = int64(0x1000)
 	statementTypeIDDml              = int64(0x3000)
+	statementTypeIDInsert           = statementTypeIDDml + int64(0x100)
+	statementTypeIDUpdate           = statementTypeIDDml + int64(0x200)
+	statementTypeIDDelete           = statementTypeIDDml + int64(0x300)
+	statementTypeIDMerge            = statementTypeIDDml + int64(0x400)
 	statementTypeIDMultiTableInsert = statementTypeIDDml + int64(0x500)
+	statementTypeIDScl              = int64(0x4000)
+	statementTypeIDTcl              = int64(0x5000)
+	statementTypeIDDdl              = int64(0x6000)
 	statementTypeIDMultistatement   = int64(0xA000)
 )
 
 const (
-	sessionClientSessionKeepAlive          = "client_session_keep_alive"
-	sessionClientValidateDefaultParameters = "CLIENT_VALIDATE_DEFAULT_PARAMETERS"
-	sessionArrayBindStageThreshold         = "client_stage_array_binding_threshold"
-	serviceName                            = "service_name"
+	sessionClientSessionKeepAlive                   = "client_session_keep_alive"
+	sessionClientSessionKeepAliveHeartbeatFrequency = "client_session_keep_alive_heartbeat_frequency"
+	sessionClientValidateDefaultParameters          = "CLIENT_VALIDATE_DEFAULT_PARAMETERS"
+	sessionArrayBindStageThreshold                  = "client_stage_array_binding_threshold"
+	serviceName                                     = "service_name"
 )
 
 type resultType string
@@ -58,13 +68,6 @@ const (
 	queryResultType     resultType = "query"
 )
 
-type execKey string
-
-const (
-	executionType          execKey = "executionType"
-	executionTypeStatement string  = "statement"
-)
-
 // snowflakeConn manages its own context.
 // External cancellation should not be supported because the connection
 // may be reused after the original query/request has completed.
@@ -77,6 +80,20 @@ type snowflakeConn struct {
 	internal            InternalClient
 	queryContextCache   *queryContextCache
 	currentTimeProvider currentTimeProvider
+
+	// sessionMu guards initialSession, sessionAltered and the role, warehouse, database
+	// and schema in cfg, which a login again from another goroutine updates as well.
+	sessionMu      sync.Mutex
+	initialSession *sessionState // state of the session after login, restored by ResetSession
+	sessionAltered bool          // whether a statement changed the session beyond what ResetSession restores
+	sessionExpired bool          // whether the session is known to be expired
+
+	// loginMu serialises logins, which use the private key fields below.
+	loginMu               sync.Mutex
+	privateKeyIndex       int    // index of the private key the JWT is signed with
+	privateKeyCount       int    // number of private keys of the config at the last login
+	privateKeyFingerprint string // fingerprint of the private key the JWT is signed with
+	rejectedPrivateKeys   int    // number of private keys rejected during the current login
 }
 
 var (
@@ -91,8 +108,7 @@ func (sc *snowflakeConn) exec(
 	isInternal bool,
 	describeOnly bool,
 	bindings []driver.NamedValue) (
-	*execResponse, error) {
-	var err error
+	data *execResponse, err error) {
 	counter := atomic.AddUint64(&sc.SequenceCounter, 1) // query sequence counter
 
 	queryContext, err := buildQueryContext(sc.queryContextCache)
@@ -111,13 +127,52 @@ func (sc *snowflakeConn) exec(
 	if key := ctx.Value(multiStatementCount); key != nil {
 		req.Parameters[string(multiStatementCount)] = key
 	}
+	for name, value := range getSessionParameters(ctx) {
+		req.Parameters[strings.ToUpper(name)] = value
+	}
 	if tag := ctx.Value(queryTag); tag != nil {
 		req.Parameters[string(queryTag)] = tag
 	}
+	switch timeout := ctx.Value(statementTimeout).(type) {
+	case time.Duration:
+		if timeout <= 0 {
+			return nil, errInvalidStatementTimeout(timeout)
+		}
+		req.Parameters[string(statementTimeout)] = statementTimeoutInSeconds(timeout)
+	case noStatementTimeout:
+		req.Parameters[string(statementTimeout)] = 0
+	}
+	requestID := getOrGenerateRequestIDFromContext(ctx)
+
+	if interceptor := sc.queryInterceptor(); interceptor != nil {
+		info := &QueryInfo{
+			Kind:         StatementQuery,
+			Query:        query,
+			Bindings:     bindings,
+			RequestID:    requestID,
+			Parameters:   req.Parameters,
+			IsInternal:   isInternal,
+			DescribeOnly: describeOnly,
+		}
+		if isFileTransfer(query) {
+			info.Kind = FileTransferQuery
+		}
+		start := time.Now()
+		defer func() {
+			interceptor.AfterExecute(ctx, info, interceptedResult(data, err, start))
+		}()
+		if err = interceptor.BeforeExecute(ctx, info); err != nil {
+			return nil, err
+		}
+		query, bindings = info.Query, info.Bindings
+		req.SQLText = query
+		if info.Parameters != nil {
+			req.Parameters = info.Parameters
+		}
+	}
 	logger.WithContext(ctx).Infof("parameters: %v", req.Parameters)
 
 	// handle bindings, if required
-	requestID := getOrGenerateRequestIDFromContext(ctx)
 	if len(bindings) > 0 {
 		if err = sc.processBindings(ctx, bindings, describeOnly, requestID, &req); err != nil {
 			return nil, err
@@ -146,9 +201,10 @@ func (sc *snowflakeConn) exec(
 		return nil, err
 	}
 
-	data, err := sc.rest.FuncPostQuery(ctx, sc.rest, &url.Values{}, headers,
+	data, err = sc.rest.FuncPostQuery(ctx, sc.rest, &url.Values{}, headers,
 		jsonBody, sc.rest.RequestTimeout, requestID, sc.cfg)
 	if err != nil {
+		sc.sessionExpired = sc.sessionExpired || IsSessionExpired(err)
 		return data, err
 	}
 	code := -1
@@ -161,6 +217,7 @@ func (sc *snowflakeConn) exec(
 	logger.WithContext(ctx).Infof("Success: %v, Code: %v", data.Success, code)
 	if !data.Success {
 		err = (populateErrorFields(code, data)).exceptionTelemetry(sc)
+		sc.sessionExpired = sc.sessionExpired || IsSessionExpired(err)
 		return nil, err
 	}
 
@@ -176,10 +233,12 @@ func (sc *snowflakeConn) exec(
 	// handle PUT/GET commands
 	fileTransferChan := make(chan error, 1)
 	if isFileTransfer(query) {
-		go func() {
-			data, err = sc.processFileTransfer(ctx, data, query, isInternal)
+		var fileTransferData *execResponse
+		go func(data *execResponse) {
+			var err error
+			fileTransferData, err = sc.processFileTransfer(ctx, data, query, isInternal)
 			fileTransferChan <- err
-		}()
+		}(data)
 
 		select {
 		case <-ctx.Done():
@@ -189,23 +248,13 @@ func (sc *snowflakeConn) exec(
 			if err != nil {
 				return nil, err
 			}
+			data = fileTransferData
 		}
 	}
 
 	logger.WithContext(ctx).Infof("Exec/Query SUCCESS with total=%v, returned=%v", data.Data.Total, data.Data.Returned)
-	if data.Data.FinalDatabaseName != "" {
-		sc.cfg.Database = data.Data.FinalDatabaseName
-	}
-	if data.Data.FinalSchemaName != "" {
-		sc.cfg.Schema = data.Data.FinalSchemaName
-	}
-	if data.Data.FinalWarehouseName != "" {
-		sc.cfg.Warehouse = data.Data.FinalWarehouseName
-	}
-	if data.Data.FinalRoleName != "" {
-		sc.cfg.Role = data.Data.FinalRoleName
-	}
-	sc.populateSessionParameters(data.Data.Parameters)
+	sc.trackSessionChanges(query, &data.Data)
+	sc.populateSessionParameters(withoutQueryParameters(req.Parameters, data.Data.Parameters))
 	return data, err
 }
 
@@ -306,8 +355,9 @@ func (sc *snowflakeConn) PrepareContext(
 		return nil, driver.ErrBadConn
 	}
 	stmt := &snowflakeStmt{
-		sc:    sc,
-		query: query,
+		sc:          sc,
+		query:       query,
+		describable: !isFileTransfer(query) && ctx.Value(multiStatementCount) == nil,
 	}
 	return stmt, nil
 }
@@ -316,16 +366,21 @@ func (sc *snowflakeConn) ExecContext(
 	ctx context.Context,
 	query string,
 	args []driver.NamedValue) (
-	driver.Result, error) {
+	_ driver.Result, err error) {
 	logger.WithContext(ctx).Infof("Exec: %#v, %v", query, args)
 	if sc.rest == nil {
 		return nil, driver.ErrBadConn
 	}
+	ctx, span := startSpan(ctx, sc.cfg, execSpanName)
+	defer func() { endSpan(span, err) }()
 	noResult := isAsyncMode(ctx)
 	isDesc := isDescribeOnly(ctx)
 	isInternal := isInternal(ctx)
 	ctx = setResultType(ctx, execResultType)
 	data, err := sc.exec(ctx, query, noResult, isInternal, isDesc, args)
+	if data != nil {
+		setExecResponseAttributes(span, &data.Data)
+	}
 	if err != nil {
 		logger.WithContext(ctx).Infof("error: %v", err)
 		if data != nil {
@@ -355,32 +410,30 @@ func (sc *snowflakeConn) ExecContext(
 			return nil, err
 		}
 		logger.WithContext(ctx).Debugf("number of updated rows: %#v", updatedRows)
+		span.SetAttributes(rowsAttribute.Int64(updatedRows))
 		return &snowflakeResult{
 			affectedRows: updatedRows,
 			insertID:     -1,
 			queryID:      data.Data.QueryID,
+			stats:        newQueryStats(&data.Data),
 		}, nil // last insert id is not supported by Snowflake
 	} else if isMultiStmt(&data.Data) {
 		return sc.handleMultiExec(ctx, data.Data)
 	} else if isDql(&data.Data) {
 		logger.WithContext(ctx).Debugf("DQL")
-		if isStatementContext(ctx) {
-			return &snowflakeResultNoRows{queryID: data.Data.QueryID}, nil
-		}
-		return driver.ResultNoRows, nil
+		return &snowflakeResultNoRows{queryID: data.Data.QueryID, stats: newQueryStats(&data.Data)}, nil
 	}
 	logger.WithContext(ctx).Debug("DDL")
-	if isStatementContext(ctx) {
-		return &snowflakeResultNoRows{queryID: data.Data.QueryID}, nil
-	}
-	return driver.ResultNoRows, nil
+	return &snowflakeResultNoRows{queryID: data.Data.QueryID, stats: newQueryStats(&data.Data)}, nil
 }
 
 func (sc *snowflakeConn) QueryContext(
 	ctx context.Context,
 	query string,
 	args []driver.NamedValue) (
-	driver.Rows, error) {
+	_ driver.Rows, err error) {
+	ctx, span := startSpan(ctx, sc.cfg, querySpanName)
+	defer func() { endSpan(span, err) }()
 	qid, err := getResumeQueryID(ctx)
 	if err != nil {
 		return nil, err
@@ -388,6 +441,7 @@ func (sc *snowflakeConn) QueryContext(
 	if qid == "" {
 		return sc.queryContextInternal(ctx, query, args)
 	}
+	span.SetAttributes(queryIDAttribute.String(qid))
 
 	// check the query status to find out if there is a result to fetch
 	_, err = sc.checkQueryStatus(ctx, qid)
@@ -414,6 +468,9 @@ func (sc *snowflakeConn) queryContextInternal(
 	ctx = setResultType(ctx, queryResultType)
 	isInternal := isInternal(ctx)
 	data, err := sc.exec(ctx, query, noResult, isInternal, isDesc, args)
+	if data != nil {
+		setExecResponseAttributes(trace.SpanFromContext(ctx), &data.Data)
+	}
 	if err != nil {
 		logger.WithContext(ctx).Errorf("error: %v", err)
 		if data != nil {
@@ -439,6 +496,7 @@ func (sc *snowflakeConn) queryContextInternal(
 	rows := new(snowflakeRows)
 	rows.sc = sc
 	rows.queryID = data.Data.QueryID
+	rows.stats = newQueryStats(&data.Data)
 	rows.ctx = ctx
 	rows.format = resultFormat(data.Data.QueryResultFormat)
 
@@ -490,7 +548,7 @@ func (sc *snowflakeConn) Ping(ctx context.Context) error {
 // CheckNamedValue determines which types are handled by this driver aside from
 // the instances captured by driver.Value
 func (sc *snowflakeConn) CheckNamedValue(nv *driver.NamedValue) error {
-	if supportedNullBind(nv) || supportedArrayBind(nv) || supportedStructuredObjectWriterBind(nv) || supportedStructuredArrayBind(nv) || supportedStructuredMapBind(nv) {
+	if supportedNullBind(nv) || supportedDecimalBind(nv) || supportedArrayBind(nv) || supportedStructuredObjectWriterBind(nv) || supportedStructuredArrayBind(nv) || supportedStructuredMapBind(nv) {
 		return nil
 	}
 	return driver.ErrSkip
@@ -504,15 +562,57 @@ func (sc *snowflakeConn) GetQueryStatus(
 	if err != nil {
 		return nil, err
 	}
-	return &SnowflakeQueryStatus{
-		queryRet.SQLText,
-		queryRet.StartTime,
-		queryRet.EndTime,
-		queryRet.ErrorCode,
-		queryRet.ErrorMessage,
-		queryRet.Stats.ScanBytes,
-		queryRet.Stats.ProducedRows,
-	}, nil
+	return queryRet.toSnowflakeQueryStatus(), nil
+}
+
+// CancelQuery cancels a running query by its query ID. Unlike canceling the context of
+// a statement, it also cancels queries submitted by other connections of the same user.
+// An error with the ErrQueryNotCanceled code is returned if the status of the query
+// reported by Snowflake afterwards is not the one of a canceled query, e.g. because the
+// query already completed.
+func (sc *snowflakeConn) CancelQuery(ctx context.Context, queryID string) error {
+	if sc.rest == nil {
+		return driver.ErrBadConn
+	}
+	if !queryIDRegexp.MatchString(queryID) {
+		return &SnowflakeError{
+			Number:  ErrQueryIDFormat,
+			Message: "Invalid QID",
+			QueryID: queryID,
+		}
+	}
+	logger.WithContext(ctx).Infof("cancelling query %v", queryID)
+	ctx = WithInternal(context.WithValue(ctx, asyncMode, false))
+	rows, err := sc.queryContextInternal(ctx, "SELECT SYSTEM$CANCEL_QUERY(?)",
+		[]driver.NamedValue{{Ordinal: 1, Value: queryID}})
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	dest := make([]driver.Value, 1)
+	if err = rows.Next(dest); err != nil {
+		return err
+	}
+	logger.WithContext(ctx).Debugf("SYSTEM$CANCEL_QUERY: %v", dest[0])
+	// the result of SYSTEM$CANCEL_QUERY is free text, so the status of the query tells
+	// whether it was canceled
+	status, err := sc.getQueryStatus(ctx, queryID)
+	if err != nil {
+		return err
+	}
+	if !isCanceledQueryStatus(status) {
+		return errQueryNotCanceled(queryID, status.Status)
+	}
+	return nil
+}
+
+// isCanceledQueryStatus tells whether the monitoring status is the one of a canceled query.
+func isCanceledQueryStatus(status *retStatus) bool {
+	switch strToQueryStatus(status.Status) {
+	case SFQueryAborting, SFQueryAborted:
+		return true
+	}
+	return status.ErrorCode == strconv.Itoa(sqlExecutionCanceledNumber)
 }
 
 // QueryArrowStream returns batches which can be queried for their raw arrow
@@ -544,6 +644,7 @@ func (sc *snowflakeConn) QueryArrowStream(ctx context.Context, query string, bin
 
 	return &snowflakeArrowStreamChunkDownloader{
 		sc:          sc,
+		ctx:         ctx,
 		ChunkMetas:  data.Data.Chunks,
 		Total:       data.Data.Total,
 		Qrmk:        data.Data.Qrmk,
@@ -684,6 +785,7 @@ type ArrowStreamLoader interface {
 
 type snowflakeArrowStreamChunkDownloader struct {
 	sc          *snowflakeConn
+	ctx         context.Context
 	ChunkMetas  []execResponseChunk
 	Total       int64
 	Qrmk        string
@@ -694,7 +796,7 @@ type snowflakeArrowStreamChunkDownloader struct {
 
 func (scd *snowflakeArrowStreamChunkDownloader) Location() *time.Location {
 	if scd.sc != nil && scd.sc.cfg != nil {
-		return getCurrentLocation(scd.sc.cfg.Params)
+		return getCurrentLocation(sessionParametersFor(scd.ctx, scd.sc.cfg.Params))
 	}
 	return nil
 }
@@ -781,6 +883,9 @@ func (scd *snowflakeArrowStreamChunkDownloader) GetBatches() (out []ArrowStreamB
 // buildSnowflakeConn creates a new snowflakeConn.
 // The provided context is used only for establishing the initial connection.
 func buildSnowflakeConn(ctx context.Context, config Config) (*snowflakeConn, error) {
+	if config.metrics == nil {
+		config.metrics = newMetricsCache()
+	}
 	sc := &snowflakeConn{
 		SequenceCounter:     0,
 		ctx:                 ctx,
@@ -832,6 +937,7 @@ func buildSnowflakeConn(ctx context.Context, config Config) (*snowflakeConn, err
 			Transport: st,
 		},
 		TokenAccessor:       tokenAccessor,
+		Connection:          sc,
 		LoginTimeout:        sc.cfg.LoginTimeout,
 		RequestTimeout:      sc.cfg.RequestTimeout,
 		MaxRetryCount:       sc.cfg.MaxRetryCount,
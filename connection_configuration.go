@@ -113,6 +113,8 @@ func handleSingleParam(cfg *Config, key string, value interface{}) error {
 		cfg.ClientTimeout, err = parseDuration(value)
 	case "jwtclienttimeout":
 		cfg.JWTClientTimeout, err = parseDuration(value)
+	case "heartbeatinterval":
+		cfg.HeartbeatInterval, err = parseDuration(value)
 	case "logintimeout":
 		cfg.LoginTimeout, err = parseDuration(value)
 	case "requesttimeout":
@@ -158,6 +160,18 @@ func handleSingleParam(cfg *Config, key string, value interface{}) error {
 			}
 		}
 		cfg.PrivateKey, err = parsePKCS8PrivateKey(block)
+	case "privatekeyfile", "private_key_file":
+		cfg.PrivateKeyFile, err = parseString(value)
+	case "privatekeyfilepwd", "private_key_file_pwd":
+		cfg.PrivateKeyFilePwd, err = parseString(value)
+	case "privatekeyfilepwdenv", "private_key_file_pwd_env":
+		cfg.PrivateKeyFilePwdEnv, err = parseString(value)
+	case "privatekeysigner":
+		v, err = parseString(value)
+		if err = checkParsingError(err, key, value); err != nil {
+			return err
+		}
+		cfg.PrivateKeySigner, err = getPrivateKeySigner(v)
 	case "validatedefaultparameters":
 		cfg.ValidateDefaultParameters, err = parseConfigBool(value)
 	case "clientrequestmfatoken":
@@ -170,6 +184,8 @@ func handleSingleParam(cfg *Config, key string, value interface{}) error {
 		cfg.TmpDirPath, err = parseString(value)
 	case "disablequerycontextcache":
 		cfg.DisableQueryContextCache, err = parseBool(value)
+	case "reauthenticateonexpiry":
+		cfg.ReauthenticateOnExpiry, err = parseBool(value)
 	case "includeretryreason":
 		cfg.IncludeRetryReason, err = parseConfigBool(value)
 	case "clientconfigfile":
@@ -658,6 +658,53 @@ func TestGetQueryStatus(t *testing.T) {
 				qStatus.ErrorCode, qStatus.ScanBytes, qStatus.ProducedRows)
 			return
 		}
+		assertEqualE(t, qStatus.QueryID, qid)
+		assertNotEqualE(t, qStatus.SessionID, int64(0))
+		assertTrueE(t, qStatus.PartitionsTotal > 0)
+	})
+}
+
+func TestUnitGetQueryStatusMonitoringFields(t *testing.T) {
+	sc := newQueryHandleTestConn(func(_ context.Context, _ *snowflakeRestful, _ *url.URL, _ map[string]string, _ time.Duration) (*http.Response, error) {
+		return jsonResponse(`{"data": {"queries": [{
+			"id": "` + testQueryHandleID + `", "status": "SUCCESS", "sqlText": "select 1", "startTime": 1700000000000,
+			"endTime": 1700000005000, "totalDuration": 5200, "sessionId": 1234567890, "warehouseId": 42,
+			"warehouseName": "TEST_WH", "queryTag": "dashboard",
+			"stats": {"scanBytes": 1024, "producedRows": 10, "compilationTime": 150, "executionTime": 4800,
+				"queuedProvisioningTime": 100, "queuedRepairTime": 5, "queuedOverloadTime": 50, "transactionBlockedTime": 7,
+				"ioLocalTempWriteBytes": 2048, "ioRemoteTempWriteBytes": 4096, "scanAssignedPartitions": 3,
+				"scanOriginalPartitions": 12, "numRowsInserted": 1, "numRowsUpdated": 2, "numRowsDeleted": 3,
+				"scanPercentageFromCache": 25}}]}, "success": true}`), nil
+	})
+	status, err := sc.GetQueryStatus(context.Background(), testQueryHandleID)
+	assertNilF(t, err)
+	assertDeepEqualE(t, status, &SnowflakeQueryStatus{
+		SQLText:                     "select 1",
+		StartTime:                   1700000000000,
+		EndTime:                     1700000005000,
+		ScanBytes:                   1024,
+		ProducedRows:                10,
+		Status:                      "SUCCESS",
+		QueryID:                     testQueryHandleID,
+		SessionID:                   1234567890,
+		WarehouseID:                 42,
+		WarehouseName:               "TEST_WH",
+		QueryTag:                    "dashboard",
+		TotalDuration:               5200,
+		CompilationTime:             150,
+		ExecutionTime:               4800,
+		QueuedProvisioningTime:      100,
+		QueuedRepairTime:            5,
+		QueuedOverloadTime:          50,
+		TransactionBlockedTime:      7,
+		BytesSpilledToLocalStorage:  2048,
+		BytesSpilledToRemoteStorage: 4096,
+		PartitionsScanned:           3,
+		PartitionsTotal:             12,
+		RowsInserted:                1,
+		RowsUpdated:                 2,
+		RowsDeleted:                 3,
+		CacheHitRatio:               0.25,
 	})
 }
 
@@ -672,6 +719,56 @@ func TestGetInvalidQueryStatus(t *testing.T) {
 	})
 }
 
+func TestCancelQueryFromAnotherConnection(t *testing.T) {
+	runSnowflakeConnTest(t, func(sct *SCTest) {
+		handle, err := sct.sc.SubmitQuery(sct.sc.ctx, "SELECT SYSTEM$WAIT(60)")
+		assertNilF(t, err)
+
+		runSnowflakeConnTest(t, func(other *SCTest) {
+			assertNilF(t, other.sc.CancelQuery(other.sc.ctx, handle.ID()))
+		})
+		assertTrueE(t, IsCanceled(handle.Wait(sct.sc.ctx)))
+	})
+}
+
+func TestUnitCancelQueryInvalid(t *testing.T) {
+	sc := &snowflakeConn{rest: &snowflakeRestful{}}
+	err := sc.CancelQuery(context.Background(), "?")
+	var se *SnowflakeError
+	assertTrueF(t, errors.As(err, &se))
+	assertEqualE(t, se.Number, ErrQueryIDFormat)
+
+	sc.rest = nil
+	assertEqualE(t, sc.CancelQuery(context.Background(), "01b2c3d4-0000-0000-0000-000000000042"), driver.ErrBadConn)
+}
+
+func TestUnitCancelQueryStatus(t *testing.T) {
+	var status string
+	sc := newQueryHandleTestConn(func(_ context.Context, _ *snowflakeRestful, _ *url.URL, _ map[string]string, _ time.Duration) (*http.Response, error) {
+		return jsonResponse(`{"data": {"queries": [` + status + `]}, "success": true}`), nil
+	})
+	sc.rest.FuncPostQuery = func(_ context.Context, _ *snowflakeRestful, _ *url.Values, _ map[string]string, _ []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		// the wording of the result does not matter
+		return systemCancelQueryResponse("some result"), nil
+	}
+
+	for _, status = range []string{
+		`{"status": "ABORTING"}`,
+		`{"status": "ABORTED"}`,
+		`{"status": "FAILED_WITH_ERROR", "errorCode": "604", "errorMessage": "SQL execution canceled"}`,
+	} {
+		assertNilE(t, sc.CancelQuery(context.Background(), testQueryHandleID), status)
+	}
+
+	status = `{"status": "SUCCESS"}`
+	err := sc.CancelQuery(context.Background(), testQueryHandleID)
+	var se *SnowflakeError
+	assertTrueF(t, errors.As(err, &se))
+	assertEqualE(t, se.Number, ErrQueryNotCanceled)
+	assertEqualE(t, se.QueryID, testQueryHandleID)
+	assertStringContainsE(t, se.Error(), "SUCCESS")
+}
+
 func TestExecWithServerSideError(t *testing.T) {
 	postQueryMock := func(_ context.Context, _ *snowflakeRestful,
 		_ *url.Values, _ map[string]string, _ []byte, _ time.Duration,
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"runtime"
 	"strconv"
@@ -31,10 +32,34 @@ func (sc *snowflakeConn) startHeartBeat() {
 		sc.rest.HeartBeat = &heartbeat{
 			restful: sc.rest,
 		}
+		if sc.cfg != nil {
+			sc.rest.HeartBeat.interval = sc.getHeartbeatInterval()
+			sc.rest.HeartBeat.onHeartbeat = sc.cfg.OnHeartbeat
+		}
 		sc.rest.HeartBeat.start()
 	}
 }
 
+// getHeartbeatInterval returns Config.HeartbeatInterval, one hour by default, bounded by
+// the CLIENT_SESSION_KEEP_ALIVE_HEARTBEAT_FREQUENCY parameter of the session.
+func (sc *snowflakeConn) getHeartbeatInterval() time.Duration {
+	interval := heartBeatInterval
+	if sc.cfg.HeartbeatInterval > 0 {
+		interval = sc.cfg.HeartbeatInterval
+	}
+	paramsMutex.Lock()
+	v, ok := sc.cfg.Params[sessionClientSessionKeepAliveHeartbeatFrequency]
+	paramsMutex.Unlock()
+	if !ok {
+		return interval
+	}
+	seconds, err := strconv.Atoi(*v)
+	if err != nil || seconds <= 0 {
+		return interval
+	}
+	return min(interval, time.Duration(seconds)*time.Second)
+}
+
 func (sc *snowflakeConn) stopHeartBeat() {
 	if sc.cfg != nil && !sc.isClientSessionKeepAliveEnabled() {
 		return
@@ -210,6 +235,17 @@ func isAsyncMode(ctx context.Context) bool {
 	return ok && a
 }
 
+// isSubmitOnly tells whether an asynchronous query returns as soon as it is submitted,
+// without retrieving its result in the background.
+func isSubmitOnly(ctx context.Context) bool {
+	v := ctx.Value(submitOnly)
+	if v == nil {
+		return false
+	}
+	d, ok := v.(bool)
+	return ok && d
+}
+
 func isDescribeOnly(ctx context.Context) bool {
 	v := ctx.Value(describeOnly)
 	if v == nil {
@@ -268,6 +304,60 @@ func isMultiStmt(data *execResponseData) bool {
 	return isMultistatementByReturningSelect || data.StatementTypeID == statementTypeIDMultistatement
 }
 
+func getSessionParameters(ctx context.Context) map[string]string {
+	if ctx == nil {
+		return nil
+	}
+	params, _ := ctx.Value(sessionParameters).(map[string]string)
+	return params
+}
+
+// withoutQueryParameters drops the parameters sent with a statement, e.g. set with
+// WithSessionParameters, WithQueryTag or WithStatementTimeout or added by a
+// QueryInterceptor, from the parameters returned by Snowflake, so they are not kept as
+// parameters of the session.
+func withoutQueryParameters(queryParams map[string]interface{}, parameters []nameValueParameter) []nameValueParameter {
+	if len(queryParams) == 0 {
+		return parameters
+	}
+	sent := make(map[string]bool, len(queryParams))
+	for name := range queryParams {
+		sent[strings.ToLower(name)] = true
+	}
+	sessionParams := make([]nameValueParameter, 0, len(parameters))
+	for _, param := range parameters {
+		if !sent[strings.ToLower(param.Name)] {
+			sessionParams = append(sessionParams, param)
+		}
+	}
+	return sessionParams
+}
+
+// sessionParametersFor returns the parameters of the session overridden with the
+// parameters set with WithSessionParameters, if any.
+func sessionParametersFor(ctx context.Context, params map[string]*string) map[string]*string {
+	overrides := getSessionParameters(ctx)
+	if len(overrides) == 0 {
+		return params
+	}
+	paramsMutex.Lock()
+	defer paramsMutex.Unlock()
+	merged := make(map[string]*string, len(params)+len(overrides))
+	for name, value := range params {
+		merged[name] = value
+	}
+	for name, value := range overrides {
+		v := value
+		merged[name] = &v
+	}
+	return merged
+}
+
+// statementTimeoutInSeconds rounds a statement timeout up to whole seconds.
+func statementTimeoutInSeconds(timeout time.Duration) int64 {
+	return int64(math.Ceil(timeout.Seconds()))
+}
+
 func getResumeQueryID(ctx context.Context) (string, error) {
 	val := ctx.Value(fetchResultByID)
 	if val == nil {
@@ -378,8 +468,3 @@ func setupOCSPPrivatelink(ctx context.Context, host string) error {
 func isPrivateLink(host string) bool {
 	return strings.Contains(strings.ToLower(host), ".privatelink.snowflakecomputing.")
 }
-
-func isStatementContext(ctx context.Context) bool {
-	v := ctx.Value(executionType)
-	return v == executionTypeStatement
-}
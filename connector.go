@@ -19,6 +19,7 @@ type Connector struct {
 
 // NewConnector creates a new connector with the given SnowflakeDriver and Config.
 func NewConnector(driver InternalSnowflakeDriver, config Config) driver.Connector {
+	config.metrics = newMetricsCache()
 	return Connector{driver, config}
 }
 
@@ -13,6 +13,7 @@ import (
 	"math/big"
 	"reflect"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -115,6 +116,12 @@ func goTypeToSnowflake(v driver.Value, tsmode snowflakeType) snowflakeType {
 		return changeType
 	case time.Time, sql.NullTime:
 		return tsmode
+	case *big.Int, *big.Float, Decimal, *Decimal:
+		return fixedType
+	case []float32, []int32:
+		if tsmode == vectorType {
+			return vectorType
+		}
 	}
 	if supportedArrayBind(&driver.NamedValue{Value: v}) {
 		return sliceType
@@ -169,6 +176,14 @@ func snowflakeTypeToGo(ctx context.Context, dbtype snowflakeType, precision int6
 		return reflect.TypeOf([]byte{})
 	case booleanType:
 		return reflect.TypeOf(true)
+	case vectorType:
+		if isIntVector(fields) {
+			return reflect.TypeOf([]int32{})
+		}
+		return reflect.TypeOf([]float32{})
+	case geographyType, geometryType:
+		// values are returned in the output format, scan into Geography or Geometry to decode them
+		return reflect.TypeOf("")
 	case objectType:
 		if len(fields) > 0 && structuredTypesEnabled {
 			return reflect.TypeOf(ObjectType{})
@@ -260,6 +275,15 @@ func valueToString(v driver.Value, tsmode snowflakeType, params map[string]*stri
 		}
 		return bindingValue{nil, "", nil}, nil
 	}
+	if isBigNumber(v) {
+		s, err := bigNumberToString(v)
+		return bindingValue{s, "", nil}, err
+	}
+	if tsmode == vectorType {
+		if s, ok := vectorToString(v); ok {
+			return bindingValue{&s, "", nil}, nil
+		}
+	}
 	v1 := reflect.Indirect(reflect.ValueOf(v))
 
 	if valuer, ok := v.(driver.Valuer); ok { // check for driver.Valuer satisfaction and honor that first
@@ -983,6 +1007,10 @@ func stringToValue(ctx context.Context, dest *driver.Value, srcColumnMeta execRe
 	case "text", "real", "variant":
 		*dest = *srcValue
 		return nil
+	case "vector":
+		var err error
+		*dest, err = stringToVector(*srcValue, isIntVector(srcColumnMeta.Fields))
+		return err
 	case "fixed":
 		if higherPrecisionEnabled(ctx) {
 			if srcColumnMeta.Scale == 0 {
@@ -1661,6 +1689,19 @@ func arrowToValue(ctx context.Context, rowIdx int, srcColumnMeta fieldMetadata,
 		}
 	case binaryType:
 		return arrowBinaryToValue(srcValue.(*array.Binary), rowIdx), nil
+	case vectorType:
+		return arrowVectorToValue(srcValue, rowIdx, isIntVector(srcColumnMeta.Fields))
+	case geographyType, geometryType:
+		if srcValue.IsNull(rowIdx) {
+			return nil, nil
+		}
+		switch geoValues := srcValue.(type) {
+		case *array.String:
+			return geoValues.Value(rowIdx), nil
+		case *array.Binary:
+			return geoValues.Value(rowIdx), nil
+		}
+		return nil, fmt.Errorf("unsupported data type")
 	case dateType:
 		return arrowDateToValue(srcValue.(*array.Date32), rowIdx), nil
 	case timeType:
@@ -2486,6 +2527,106 @@ func arrowTimeToValue(srcValue arrow.Array, rowIdx int, scale int) snowflakeValu
 	return nil
 }
 
+// isIntVector checks if a VECTOR column holds INT elements rather than FLOAT ones.
+func isIntVector(fields []fieldMetadata) bool {
+	return len(fields) > 0 && getSnowflakeType(fields[0].Type) == fixedType
+}
+
+// stringToVector parses the text representation of a VECTOR, e.g. "[1.1,2.2,3.3]".
+func stringToVector(srcValue string, isInt bool) (snowflakeValue, error) {
+	if isInt {
+		var v []int32
+		if err := json.Unmarshal([]byte(srcValue), &v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+	var v []float32
+	if err := json.Unmarshal([]byte(srcValue), &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func arrowVectorToValue(srcValue arrow.Array, rowIdx int, isInt bool) (snowflakeValue, error) {
+	if srcValue.IsNull(rowIdx) {
+		return nil, nil
+	}
+	switch vectors := srcValue.(type) {
+	case *array.FixedSizeList:
+		start, end := vectors.ValueOffsets(rowIdx)
+		switch values := vectors.ListValues().(type) {
+		case *array.Float32:
+			return slices.Clone(values.Float32Values()[start:end]), nil
+		case *array.Int32:
+			return slices.Clone(values.Int32Values()[start:end]), nil
+		}
+	case *array.String:
+		return stringToVector(vectors.Value(rowIdx), isInt)
+	}
+	return nil, fmt.Errorf("unsupported data type")
+}
+
+// vectorArrowType returns the fixed-size list type used for VECTOR columns in Arrow batches.
+func vectorArrowType(fieldMetadata fieldMetadata) arrow.DataType {
+	if isIntVector(fieldMetadata.Fields) {
+		return arrow.FixedSizeListOf(int32(fieldMetadata.VectorDimension), arrow.PrimitiveTypes.Int32)
+	}
+	return arrow.FixedSizeListOf(int32(fieldMetadata.VectorDimension), arrow.PrimitiveTypes.Float32)
+}
+
+// arrowStringToVectorColumn converts VECTOR values sent as text to a fixed-size list column.
+func arrowStringToVectorColumn(stringCol *array.String, pool memory.Allocator, fieldMetadata fieldMetadata) (arrow.Array, error) {
+	listType := vectorArrowType(fieldMetadata).(*arrow.FixedSizeListType)
+	lb := array.NewFixedSizeListBuilder(pool, listType.Len(), listType.Elem())
+	defer lb.Release()
+	isInt := isIntVector(fieldMetadata.Fields)
+	for i := 0; i < stringCol.Len(); i++ {
+		if stringCol.IsNull(i) {
+			lb.AppendNull()
+			continue
+		}
+		v, err := stringToVector(stringCol.Value(i), isInt)
+		if err != nil {
+			return nil, err
+		}
+		var n int
+		lb.Append(true)
+		switch values := v.(type) {
+		case []int32:
+			n = len(values)
+			lb.ValueBuilder().(*array.Int32Builder).AppendValues(values, nil)
+		case []float32:
+			n = len(values)
+			lb.ValueBuilder().(*array.Float32Builder).AppendValues(values, nil)
+		}
+		if n != fieldMetadata.VectorDimension {
+			return nil, fmt.Errorf("vector in column %v has %v elements, expected %v", fieldMetadata.Name, n, fieldMetadata.VectorDimension)
+		}
+	}
+	return lb.NewArray(), nil
+}
+
+// vectorToString converts a []float32 or []int32 to the text representation of a VECTOR.
+func vectorToString(v driver.Value) (string, bool) {
+	var elems []string
+	switch vector := v.(type) {
+	case []float32:
+		elems = make([]string, len(vector))
+		for i, x := range vector {
+			elems[i] = strconv.FormatFloat(float64(x), 'g', -1, 32)
+		}
+	case []int32:
+		elems = make([]string, len(vector))
+		for i, x := range vector {
+			elems[i] = strconv.FormatInt(int64(x), 10)
+		}
+	default:
+		return "", false
+	}
+	return "[" + strings.Join(elems, ",") + "]", true
+}
+
 type (
 	intArray          []int
 	int32Array        []int32
@@ -2500,6 +2641,9 @@ type (
 	timestampTzArray  []time.Time
 	dateArray         []time.Time
 	timeArray         []time.Time
+	bigIntArray       []*big.Int
+	bigFloatArray     []*big.Float
+	decimalArray      []Decimal
 )
 
 // Array takes in a column of a row to be inserted via array binding, bulk or
@@ -2522,6 +2666,12 @@ func Array(a interface{}, typ ...timezoneType) interface{} {
 		return (*stringArray)(&t)
 	case [][]byte:
 		return (*byteArray)(&t)
+	case []*big.Int:
+		return (*bigIntArray)(&t)
+	case []*big.Float:
+		return (*bigFloatArray)(&t)
+	case []Decimal:
+		return (*decimalArray)(&t)
 	case []time.Time:
 		if len(typ) < 1 {
 			return a
@@ -2556,6 +2706,12 @@ func Array(a interface{}, typ ...timezoneType) interface{} {
 		return (*stringArray)(t)
 	case *[][]byte:
 		return (*byteArray)(t)
+	case *[]*big.Int:
+		return (*bigIntArray)(t)
+	case *[]*big.Float:
+		return (*bigFloatArray)(t)
+	case *[]Decimal:
+		return (*decimalArray)(t)
 	case *[]time.Time:
 		if len(typ) < 1 {
 			return a
@@ -2655,6 +2811,33 @@ func snowflakeArrayToString(nv *driver.NamedValue, stream bool) (snowflakeType,
 			v := hex.EncodeToString(x)
 			arr = append(arr, &v)
 		}
+	case reflect.TypeOf(&bigIntArray{}):
+		t = fixedType
+		a := nv.Value.(*bigIntArray)
+		for _, x := range *a {
+			v, err := bigNumberToString(x)
+			if err != nil {
+				return unSupportedType, nil, err
+			}
+			arr = append(arr, v)
+		}
+	case reflect.TypeOf(&bigFloatArray{}):
+		t = fixedType
+		a := nv.Value.(*bigFloatArray)
+		for _, x := range *a {
+			v, err := bigNumberToString(x)
+			if err != nil {
+				return unSupportedType, nil, err
+			}
+			arr = append(arr, v)
+		}
+	case reflect.TypeOf(&decimalArray{}):
+		t = fixedType
+		a := nv.Value.(*decimalArray)
+		for _, x := range *a {
+			v := x.String()
+			arr = append(arr, &v)
+		}
 	case reflect.TypeOf(&timestampNtzArray{}):
 		t = timestampNtzType
 		a := nv.Value.(*timestampNtzArray)
@@ -2776,6 +2959,13 @@ func interfaceSliceToString(interfaceSlice reflect.Value, stream bool, tzType ..
 				t = binaryType
 				v := hex.EncodeToString(x)
 				arr = append(arr, &v)
+			case *big.Int, *big.Float, Decimal, *Decimal:
+				t = fixedType
+				v, err := bigNumberToString(x)
+				if err != nil {
+					return unSupportedType, nil, err
+				}
+				arr = append(arr, v)
 			case time.Time:
 				if len(tzType) < 1 {
 					return unSupportedType, nil, nil
@@ -3020,6 +3210,23 @@ func arrowToRecordSingleColumn(ctx context.Context, field arrow.Field, col arrow
 		if stringCol, ok := col.(*array.String); ok {
 			newCol = arrowStringRecordToColumn(ctx, stringCol, pool, numRows, fieldMetadata)
 		}
+	case geographyType, geometryType:
+		// GeoJSON and WKT are strings, WKB stays binary
+		if stringCol, ok := col.(*array.String); ok {
+			newCol = arrowStringRecordToColumn(ctx, stringCol, pool, numRows, fieldMetadata)
+		} else {
+			col.Retain()
+		}
+	case vectorType:
+		if stringCol, ok := col.(*array.String); ok && fieldMetadata.VectorDimension > 0 {
+			newCol, err = arrowStringToVectorColumn(stringCol, pool, fieldMetadata)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			// vectors are already fixed-size lists
+			col.Retain()
+		}
 	case objectType:
 		if structCol, ok := col.(*array.Struct); ok {
 			var internalCols []arrow.Array
@@ -3168,6 +3375,12 @@ func recordToSchemaSingleField(fieldMetadata fieldMetadata, f arrow.Field, withH
 		}
 	case timeType:
 		t = &arrow.Time64Type{Unit: arrow.Nanosecond}
+	case vectorType:
+		if f.Type.ID() == arrow.STRING && fieldMetadata.VectorDimension > 0 {
+			t = vectorArrowType(fieldMetadata)
+		} else {
+			converted = false
+		}
 	case timestampNtzType, timestampTzType:
 		if timestampOption == UseOriginalTimestamp {
 			// do nothing - return timestamp as is
@@ -116,6 +116,12 @@ func TestGoTypeToSnowflake(t *testing.T) {
 		{in: Array([]interface{}{time.Now()}, TimestampTZType), tmode: timeType, out: sliceType},
 		{in: Array([]interface{}{time.Now()}, DateType), tmode: timestampNtzType, out: sliceType},
 		{in: Array([]interface{}{time.Now()}, TimeType), tmode: timestampTzType, out: sliceType},
+		{in: big.NewInt(123), tmode: nullType, out: fixedType},
+		{in: big.NewFloat(1.5), tmode: nullType, out: fixedType},
+		{in: mustNewDecimal(12345, 2), tmode: nullType, out: fixedType},
+		{in: Array([]*big.Int{big.NewInt(1)}), tmode: nullType, out: sliceType},
+		{in: Array(&[]*big.Float{big.NewFloat(1.5)}), tmode: nullType, out: sliceType},
+		{in: Array([]Decimal{mustNewDecimal(15, 1)}), tmode: nullType, out: sliceType},
 		{in: nil, tmode: nullType, out: nullType},
 		// negative
 		{in: 123, tmode: nullType, out: unSupportedType},
@@ -2638,3 +2644,132 @@ func TestNumbersScanType(t *testing.T) {
 		})
 	}
 }
+
+func TestVectorConversion(t *testing.T) {
+	floatFields := []fieldMetadata{{Type: "real"}}
+	intFields := []fieldMetadata{{Type: "fixed"}}
+
+	t.Run("scan type", func(t *testing.T) {
+		assertEqualE(t, snowflakeTypeToGo(context.Background(), vectorType, 0, 0, floatFields), reflect.TypeOf([]float32{}))
+		assertEqualE(t, snowflakeTypeToGo(context.Background(), vectorType, 0, 0, intFields), reflect.TypeOf([]int32{}))
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var dest driver.Value
+		floats := "[1.1,2.2,-3.5e+00]"
+		assertNilF(t, stringToValue(context.Background(), &dest, execResponseRowType{Type: "vector", Fields: floatFields, VectorDimension: 3}, &floats, nil, nil))
+		assertDeepEqualE(t, dest, []float32{1.1, 2.2, -3.5})
+		ints := "[1,-2,3]"
+		assertNilF(t, stringToValue(context.Background(), &dest, execResponseRowType{Type: "vector", Fields: intFields, VectorDimension: 3}, &ints, nil, nil))
+		assertDeepEqualE(t, dest, []int32{1, -2, 3})
+		invalid := "[1,"
+		assertNotNilE(t, stringToValue(context.Background(), &dest, execResponseRowType{Type: "vector", Fields: intFields}, &invalid, nil, nil))
+	})
+
+	t.Run("arrow", func(t *testing.T) {
+		pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+		defer pool.AssertSize(t, 0)
+		lb := array.NewFixedSizeListBuilder(pool, 2, arrow.PrimitiveTypes.Float32)
+		defer lb.Release()
+		lb.Append(true)
+		lb.ValueBuilder().(*array.Float32Builder).AppendValues([]float32{1.5, 2.5}, nil)
+		lb.AppendNull()
+		lb.Append(true)
+		lb.ValueBuilder().(*array.Float32Builder).AppendValues([]float32{-1, 0}, nil)
+		arr := lb.NewArray()
+		defer arr.Release()
+
+		dest := make([]snowflakeValue, 3)
+		assertNilF(t, arrowToValues(context.Background(), dest, execResponseRowType{Type: "vector", Fields: floatFields, VectorDimension: 2}, arr, nil, false, nil))
+		assertDeepEqualE(t, dest[0], []float32{1.5, 2.5})
+		assertNilE(t, dest[1])
+		assertDeepEqualE(t, dest[2], []float32{-1, 0})
+	})
+
+	t.Run("binding", func(t *testing.T) {
+		bindValues, err := getBindValues([]driver.NamedValue{
+			{Ordinal: 1, Value: DataTypeVector},
+			{Ordinal: 2, Value: []float32{1.5, -2, 0.25}},
+			{Ordinal: 3, Value: []int32{1, 2, 3}},
+		}, nil)
+		assertNilF(t, err)
+		assertEqualE(t, len(bindValues), 2)
+		assertEqualE(t, bindValues["1"].Type, textType.String())
+		assertEqualE(t, *bindValues["1"].Value.(*string), "[1.5,-2,0.25]")
+		assertEqualE(t, bindValues["2"].Type, textType.String())
+		assertEqualE(t, *bindValues["2"].Value.(*string), "[1,2,3]")
+
+		// without DataTypeVector a slice is still bound as a structured array
+		assertEqualE(t, goTypeToSnowflake([]float32{1}, timestampNtzType), arrayType)
+	})
+}
+
+func TestArrowToRecordVector(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+	rowType := []execResponseRowType{
+		{Name: "FLOATS", Type: "vector", Fields: []fieldMetadata{{Type: "real"}}, VectorDimension: 2},
+		{Name: "INTS", Type: "vector", Fields: []fieldMetadata{{Type: "fixed"}}, VectorDimension: 3},
+	}
+
+	lb := array.NewFixedSizeListBuilder(pool, 2, arrow.PrimitiveTypes.Float32)
+	defer lb.Release()
+	lb.Append(true)
+	lb.ValueBuilder().(*array.Float32Builder).AppendValues([]float32{1.5, 2.5}, nil)
+	lb.AppendNull()
+	floats := lb.NewArray()
+	defer floats.Release()
+
+	sb := array.NewStringBuilder(pool)
+	defer sb.Release()
+	sb.Append("[1,2,3]")
+	sb.AppendNull()
+	ints := sb.NewArray()
+	defer ints.Release()
+
+	sc := arrow.NewSchema([]arrow.Field{{Name: "FLOATS", Type: floats.DataType(), Nullable: true}, {Name: "INTS", Type: arrow.BinaryTypes.String, Nullable: true}}, nil)
+	record := array.NewRecord(sc, []arrow.Array{floats, ints}, 2)
+	defer record.Release()
+
+	converted, err := arrowToRecord(context.Background(), record, pool, rowType, time.UTC)
+	assertNilF(t, err)
+	defer converted.Release()
+
+	assertEqualE(t, converted.Schema().Field(0).Type.String(), arrow.FixedSizeListOf(2, arrow.PrimitiveTypes.Float32).String())
+	assertEqualE(t, converted.Schema().Field(1).Type.String(), arrow.FixedSizeListOf(3, arrow.PrimitiveTypes.Int32).String())
+	intCol, ok := converted.Column(1).(*array.FixedSizeList)
+	assertTrueF(t, ok)
+	assertDeepEqualE(t, intCol.ListValues().(*array.Int32).Int32Values(), []int32{1, 2, 3, 0, 0, 0})
+	assertTrueE(t, intCol.IsNull(1))
+	assertTrueE(t, converted.Column(0).IsNull(1))
+}
+
+func TestVectorType(t *testing.T) {
+	for _, forceFormat := range []string{forceJSON, forceARROW} {
+		t.Run(forceFormat, func(t *testing.T) {
+			runDBTest(t, func(dbt *DBTest) {
+				dbt.mustExecT(t, forceFormat)
+				dbt.mustExec("create or replace table test_vector (f vector(float, 3), i vector(int, 2))")
+				defer dbt.mustExec("drop table if exists test_vector")
+				dbt.mustExec("insert into test_vector select ?::vector(float, 3), ?::vector(int, 2)", DataTypeVector, []float32{1.5, -2, 0.25}, []int32{7, -8})
+
+				rows := dbt.mustQuery("select f, i from test_vector")
+				defer rows.Close()
+				rows.mustNext()
+				var f []float32
+				var i []int32
+				rows.mustScan(&f, &i)
+				assertDeepEqualE(t, f, []float32{1.5, -2, 0.25})
+				assertDeepEqualE(t, i, []int32{7, -8})
+
+				types, err := rows.ColumnTypes()
+				assertNilF(t, err)
+				assertEqualE(t, types[0].ScanType(), reflect.TypeOf([]float32{}))
+				assertEqualE(t, types[1].ScanType(), reflect.TypeOf([]int32{}))
+				length, ok := types[0].Length()
+				assertTrueE(t, ok)
+				assertEqualE(t, length, int64(3))
+			})
+		})
+	}
+}
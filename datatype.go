@@ -33,6 +33,10 @@ const (
 	nilObjectType
 	nilArrayType
 	nilMapType
+	// snowflake types added later are appended so the values of the DataType* markers do not change
+	vectorType
+	geographyType
+	geometryType
 )
 
 var snowflakeToDriverType = map[string]snowflakeType{
@@ -50,6 +54,9 @@ var snowflakeToDriverType = map[string]snowflakeType{
 	"BINARY":        binaryType,
 	"TIME":          timeType,
 	"BOOLEAN":       booleanType,
+	"VECTOR":        vectorType,
+	"GEOGRAPHY":     geographyType,
+	"GEOMETRY":      geometryType,
 	"NULL":          nullType,
 	"SLICE":         sliceType,
 	"CHANGE_TYPE":   changeType,
@@ -107,6 +114,8 @@ var (
 	DataTypeTime = []byte{timeType.Byte()}
 	// DataTypeBoolean is a BOOLEAN datatype.
 	DataTypeBoolean = []byte{booleanType.Byte()}
+	// DataTypeVector is a VECTOR datatype.
+	DataTypeVector = []byte{vectorType.Byte()}
 	// DataTypeNilObject represents a nil structured object.
 	DataTypeNilObject = []byte{nilObjectType.Byte()}
 	// DataTypeNilArray represents a nil structured array.
@@ -137,6 +146,8 @@ func dataTypeMode(v driver.Value) (tsmode snowflakeType, err error) {
 			tsmode = arrayType
 		case bytes.Equal(bd, DataTypeVariant):
 			tsmode = variantType
+		case bytes.Equal(bd, DataTypeVector):
+			tsmode = vectorType
 		case bytes.Equal(bd, DataTypeNilObject):
 			tsmode = nilObjectType
 		case bytes.Equal(bd, DataTypeNilArray):
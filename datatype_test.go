@@ -21,6 +21,7 @@ func TestDataTypeMode(t *testing.T) {
 		{tp: DataTypeObject, tmode: objectType, err: nil},
 		{tp: DataTypeArray, tmode: arrayType, err: nil},
 		{tp: DataTypeVariant, tmode: variantType, err: nil},
+		{tp: DataTypeVector, tmode: vectorType, err: nil},
 		{tp: DataTypeFixed, tmode: fixedType,
 			err: fmt.Errorf(errMsgInvalidByteArray, DataTypeFixed)},
 		{tp: DataTypeReal, tmode: realType,
@@ -47,6 +48,24 @@ func TestDataTypeMode(t *testing.T) {
 	}
 }
 
+func TestDataTypeMarkerValues(t *testing.T) {
+	// the values of the markers are part of the API and must not change when types are added
+	markers := [][]byte{DataTypeFixed, DataTypeReal, DataTypeText, DataTypeDate, DataTypeVariant,
+		DataTypeTimestampLtz, DataTypeTimestampNtz, DataTypeTimestampTz, DataTypeObject, DataTypeArray,
+		nil, DataTypeBinary, DataTypeTime, DataTypeBoolean}
+	for i, marker := range markers {
+		if marker != nil {
+			assertEqualE(t, marker[0], byte(i))
+		}
+	}
+	assertEqualE(t, DataTypeNilObject[0], byte(18))
+	assertEqualE(t, DataTypeNilArray[0], byte(19))
+	assertEqualE(t, DataTypeNilMap[0], byte(20))
+	for _, st := range []snowflakeType{vectorType, geographyType, geometryType} {
+		assertTrueE(t, st > nilMapType)
+	}
+}
+
 func TestPopulateSnowflakeParameter(t *testing.T) {
 	columns := []string{"key", "value", "default", "level", "description", "set_by_user", "set_in_job", "set_on", "set_by_thread_id", "set_by_thread_name", "set_by_class", "parameter_comment", "type", "is_expired", "expires_at", "set_by_controlling_parameter", "activate_version", "partial_rollout"}
 	p := SnowflakeParameter{}
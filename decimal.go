@@ -0,0 +1,190 @@
+package gosnowflake
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// maxDecimalExponent bounds the exponent and the scale of a Decimal. It is far beyond the
+// range of NUMBER, precision 38 with scale up to 37, but covers every float64 in plain
+// notation and keeps 10^scale cheap to compute.
+const maxDecimalExponent = 400
+
+var bigTen = big.NewInt(10)
+
+// Decimal is an exact fixed-scale decimal number. Its value is Unscaled() * 10^-Scale().
+// It can be bound as a NUMBER parameter and scanned from NUMBER columns without losing precision.
+type Decimal struct {
+	unscaled *big.Int
+	scale    int
+}
+
+// NewDecimal creates a Decimal with the value unscaled * 10^-scale. The scale must be
+// between -400 and 400.
+func NewDecimal(unscaled *big.Int, scale int) (Decimal, error) {
+	if scale < -maxDecimalExponent || scale > maxDecimalExponent {
+		return Decimal{}, fmt.Errorf("decimal scale %v is out of range [%v, %v]", scale, -maxDecimalExponent, maxDecimalExponent)
+	}
+	u := new(big.Int)
+	if unscaled != nil {
+		u.Set(unscaled)
+	}
+	if scale < 0 {
+		u.Mul(u, new(big.Int).Exp(bigTen, big.NewInt(int64(-scale)), nil))
+		scale = 0
+	}
+	return Decimal{unscaled: u, scale: scale}, nil
+}
+
+// ParseDecimal parses a decimal number such as "-12.3400" or "1.5E-3". The scale of
+// the result is the number of digits after the decimal point.
+func ParseDecimal(s string) (Decimal, error) {
+	str := strings.TrimSpace(s)
+	exp := 0
+	if i := strings.IndexAny(str, "eE"); i >= 0 {
+		e, err := strconv.Atoi(str[i+1:])
+		if err != nil {
+			return Decimal{}, fmt.Errorf("invalid decimal: %q", s)
+		}
+		if e < -maxDecimalExponent || e > maxDecimalExponent {
+			return Decimal{}, fmt.Errorf("decimal exponent of %q is out of range [%v, %v]", s, -maxDecimalExponent, maxDecimalExponent)
+		}
+		exp = e
+		str = str[:i]
+	}
+	scale := 0
+	if i := strings.IndexByte(str, '.'); i >= 0 {
+		scale = len(str) - i - 1
+		str = str[:i] + str[i+1:]
+	}
+	digits := strings.TrimLeft(str, "+-")
+	if digits == "" || len(str)-len(digits) > 1 || strings.TrimLeft(digits, "0123456789") != "" {
+		return Decimal{}, fmt.Errorf("invalid decimal: %q", s)
+	}
+	unscaled, ok := new(big.Int).SetString(str, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("invalid decimal: %q", s)
+	}
+	return NewDecimal(unscaled, scale-exp)
+}
+
+// Unscaled returns the unscaled value of the decimal.
+func (d Decimal) Unscaled() *big.Int {
+	if d.unscaled == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(d.unscaled)
+}
+
+// Scale returns the number of digits after the decimal point.
+func (d Decimal) Scale() int {
+	return d.scale
+}
+
+// Float returns the value of the decimal as a big.Float.
+func (d Decimal) Float() *big.Float {
+	f, _, _ := big.ParseFloat(d.String(), 10, 0, big.ToNearestEven)
+	return f
+}
+
+// String returns the decimal in plain notation, e.g. "-12.3400".
+func (d Decimal) String() string {
+	digits := d.Unscaled().String()
+	if d.scale == 0 {
+		return digits
+	}
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+	if len(digits) <= d.scale {
+		digits = strings.Repeat("0", d.scale-len(digits)+1) + digits
+	}
+	point := len(digits) - d.scale
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// Value implements driver.Valuer.
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// Scan implements sql.Scanner. It accepts the values returned for NUMBER columns
+// with or without WithHigherPrecision.
+func (d *Decimal) Scan(src interface{}) error {
+	var err error
+	switch v := src.(type) {
+	case string:
+		*d, err = ParseDecimal(v)
+	case []byte:
+		*d, err = ParseDecimal(string(v))
+	case int64:
+		*d, err = NewDecimal(big.NewInt(v), 0)
+	case float64:
+		*d, err = ParseDecimal(strconv.FormatFloat(v, 'f', -1, 64))
+	case *big.Int:
+		*d, err = NewDecimal(v, 0)
+	case big.Int:
+		*d, err = NewDecimal(&v, 0)
+	case *big.Float:
+		*d, err = bigFloatToDecimal(v)
+	case big.Float:
+		*d, err = bigFloatToDecimal(&v)
+	default:
+		err = fmt.Errorf("cannot convert %T to Decimal", src)
+	}
+	return err
+}
+
+func bigFloatToDecimal(f *big.Float) (Decimal, error) {
+	if f == nil {
+		return Decimal{}, fmt.Errorf("cannot convert nil *big.Float to Decimal")
+	}
+	if f.IsInf() {
+		return Decimal{}, fmt.Errorf("cannot convert %v to Decimal", f)
+	}
+	return ParseDecimal(f.Text('f', -1))
+}
+
+// isBigNumber checks if v is one of the arbitrary precision types bound as NUMBER.
+func isBigNumber(v interface{}) bool {
+	switch v.(type) {
+	case *big.Int, *big.Float, Decimal, *Decimal:
+		return true
+	}
+	return false
+}
+
+// bigNumberToString converts *big.Int, *big.Float and Decimal values to the exact
+// decimal string sent to Snowflake. A nil pointer is converted to a nil string.
+func bigNumberToString(v interface{}) (*string, error) {
+	var s string
+	switch n := v.(type) {
+	case *big.Int:
+		if n == nil {
+			return nil, nil
+		}
+		s = n.String()
+	case *big.Float:
+		if n == nil {
+			return nil, nil
+		}
+		if n.IsInf() {
+			return nil, fmt.Errorf("cannot bind %v as NUMBER", n)
+		}
+		s = n.Text('f', -1)
+	case Decimal:
+		s = n.String()
+	case *Decimal:
+		if n == nil {
+			return nil, nil
+		}
+		s = n.String()
+	default:
+		return nil, fmt.Errorf("unsupported type: %T", v)
+	}
+	return &s, nil
+}
@@ -0,0 +1,181 @@
+package gosnowflake
+
+import (
+	"database/sql/driver"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	testcases := []struct {
+		in       string
+		unscaled string
+		scale    int
+		out      string
+	}{
+		{in: "0", unscaled: "0", scale: 0, out: "0"},
+		{in: "123", unscaled: "123", scale: 0, out: "123"},
+		{in: "-12.3400", unscaled: "-123400", scale: 4, out: "-12.3400"},
+		{in: "+0.001", unscaled: "1", scale: 3, out: "0.001"},
+		{in: "-.5", unscaled: "-5", scale: 1, out: "-0.5"},
+		{in: "1.5E3", unscaled: "1500", scale: 0, out: "1500"},
+		{in: "1.5e-3", unscaled: "15", scale: 4, out: "0.0015"},
+		{in: "12345678901234567890123456789012345678", unscaled: "12345678901234567890123456789012345678", scale: 0, out: "12345678901234567890123456789012345678"},
+		{in: "1234567890123456789012345.6789012345678", unscaled: "12345678901234567890123456789012345678", scale: 13, out: "1234567890123456789012345.6789012345678"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.in, func(t *testing.T) {
+			d, err := ParseDecimal(tc.in)
+			assertNilF(t, err)
+			assertEqualE(t, d.Unscaled().String(), tc.unscaled)
+			assertEqualE(t, d.Scale(), tc.scale)
+			assertEqualE(t, d.String(), tc.out)
+		})
+	}
+
+	for _, in := range []string{"", ".", "-", "1.2.3", "12a", "--1", "1e", "1e1.5",
+		"1e1000000000", "1e-1000000000", "1e9223372036854775807", "0.5e-400", "1e401"} {
+		t.Run("invalid "+in, func(t *testing.T) {
+			_, err := ParseDecimal(in)
+			assertNotNilE(t, err)
+		})
+	}
+
+	// the smallest float64 is still accepted
+	d, err := ParseDecimal("5e-324")
+	assertNilF(t, err)
+	assertEqualE(t, d.Scale(), 324)
+}
+
+func TestNewDecimalScaleOutOfRange(t *testing.T) {
+	for _, scale := range []int{-401, 401, -1000000000, math.MinInt} {
+		_, err := NewDecimal(big.NewInt(1), scale)
+		assertNotNilE(t, err)
+	}
+	d, err := NewDecimal(big.NewInt(1), -400)
+	assertNilF(t, err)
+	assertEqualE(t, len(d.String()), 401)
+}
+
+func mustNewDecimal(unscaled int64, scale int) Decimal {
+	d, err := NewDecimal(big.NewInt(unscaled), scale)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func TestDecimalScan(t *testing.T) {
+	bigInt, _ := new(big.Int).SetString("99999999999999999999999999999999999999", 10)
+	bigFloat, _, _ := big.ParseFloat("123456789.000000001", 10, 128, big.ToNearestEven)
+	testcases := []struct {
+		name string
+		src  interface{}
+		out  string
+	}{
+		{name: "string", src: "-1.2300", out: "-1.2300"},
+		{name: "bytes", src: []byte("42.5"), out: "42.5"},
+		{name: "int64", src: int64(-7), out: "-7"},
+		{name: "float64", src: float64(0.25), out: "0.25"},
+		{name: "big.Int", src: bigInt, out: "99999999999999999999999999999999999999"},
+		{name: "big.Float", src: bigFloat, out: "123456789.000000001"},
+		{name: "big.Int value", src: *bigInt, out: "99999999999999999999999999999999999999"},
+		{name: "big.Float value", src: *bigFloat, out: "123456789.000000001"},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var d Decimal
+			assertNilF(t, d.Scan(tc.src))
+			assertEqualE(t, d.String(), tc.out)
+		})
+	}
+
+	var d Decimal
+	assertNotNilE(t, d.Scan(true))
+}
+
+func TestDecimalBindValues(t *testing.T) {
+	bigInt, _ := new(big.Int).SetString("-12345678901234567890123456789012345678", 10)
+	bigFloat, _, _ := big.ParseFloat("0.1", 10, 200, big.ToNearestEven)
+	decimal, err := ParseDecimal("1234567890123456789012345.6789012345678")
+	assertNilF(t, err)
+
+	bindValues, err := getBindValues([]driver.NamedValue{
+		{Ordinal: 1, Value: bigInt},
+		{Ordinal: 2, Value: bigFloat},
+		{Ordinal: 3, Value: decimal},
+		{Ordinal: 4, Value: &decimal},
+		{Ordinal: 5, Value: (*big.Int)(nil)},
+	}, nil)
+	assertNilF(t, err)
+	expected := map[string]interface{}{
+		"1": "-12345678901234567890123456789012345678",
+		"2": "0.1",
+		"3": "1234567890123456789012345.6789012345678",
+		"4": "1234567890123456789012345.6789012345678",
+	}
+	for name, value := range expected {
+		assertEqualE(t, bindValues[name].Type, fixedType.String())
+		assertEqualE(t, *bindValues[name].Value.(*string), value)
+	}
+	assertEqualE(t, bindValues["5"].Type, fixedType.String())
+	assertNilE(t, bindValues["5"].Value.(*string))
+
+	_, err = getBindValues([]driver.NamedValue{{Ordinal: 1, Value: new(big.Float).SetInf(false)}}, nil)
+	assertNotNilE(t, err)
+}
+
+func TestDecimalArrayBindValues(t *testing.T) {
+	bigInts := []*big.Int{big.NewInt(1), nil, big.NewInt(-3)}
+	bigFloats := []*big.Float{big.NewFloat(1.5), big.NewFloat(-0.25), nil}
+	decimals := []Decimal{mustNewDecimal(100, 2), mustNewDecimal(-5, 3), mustNewDecimal(7, -2)}
+	mixed := []interface{}{big.NewInt(10), mustNewDecimal(11, 1), nil}
+
+	bindValues, err := getBindValues([]driver.NamedValue{
+		{Ordinal: 1, Value: Array(&bigInts)},
+		{Ordinal: 2, Value: Array(bigFloats)},
+		{Ordinal: 3, Value: Array(&decimals)},
+		{Ordinal: 4, Value: Array(&mixed)},
+	}, nil)
+	assertNilF(t, err)
+	expected := map[string][]interface{}{
+		"1": {"1", nil, "-3"},
+		"2": {"1.5", "-0.25", nil},
+		"3": {"1.00", "-0.005", "700"},
+		"4": {"10", "1.1", nil},
+	}
+	for name, values := range expected {
+		assertEqualE(t, bindValues[name].Type, fixedType.String())
+		arr := bindValues[name].Value.([]*string)
+		assertEqualF(t, len(arr), len(values))
+		for i, v := range values {
+			if v == nil {
+				assertNilE(t, arr[i])
+			} else {
+				assertEqualE(t, *arr[i], v)
+			}
+		}
+	}
+}
+
+func TestDecimalBulkArrayBindRows(t *testing.T) {
+	bigInts := []*big.Int{big.NewInt(1), nil}
+	decimals := []Decimal{mustNewDecimal(12345, 2), mustNewDecimal(-1, 0)}
+	bu := bindUploader{}
+	rows, err := bu.buildRowsAsBytes([]driver.NamedValue{
+		{Ordinal: 1, Value: Array(&bigInts)},
+		{Ordinal: 2, Value: Array(&decimals)},
+	})
+	assertNilF(t, err)
+	assertEqualF(t, len(rows), 2)
+	assertEqualE(t, string(rows[0]), "1,123.45\n")
+	assertEqualE(t, string(rows[1]), ",-1\n")
+}
+
+func TestDecimalCheckNamedValue(t *testing.T) {
+	sc := &snowflakeConn{}
+	for _, v := range []interface{}{big.NewInt(1), big.NewFloat(1), mustNewDecimal(1, 1), &Decimal{}} {
+		assertNilE(t, sc.CheckNamedValue(&driver.NamedValue{Value: v}))
+	}
+}
@@ -126,6 +126,10 @@ The following connection parameters are supported:
     such that the connection session will never expire. Care should be taken in using this option as it opens up
     the access forever as long as the process is alive.
 
+  - heartbeatInterval: Specifies the interval, in seconds, between the heartbeats sent when client_session_keep_alive
+    is set. The default is 3600 seconds. The interval is bounded by the CLIENT_SESSION_KEEP_ALIVE_HEARTBEAT_FREQUENCY
+    parameter of the session.
+
   - ocspFailOpen: true by default. Set to false to make OCSP check fail closed mode.
 
   - validateDefaultParameters: true by default. Set to false to disable checks on existence and privileges check for
@@ -137,6 +141,12 @@ The following connection parameters are supported:
   - disableQueryContextCache: disables parsing of query context returned from server and resending it to server as well.
     Default value is false.
 
+  - reauthenticateOnExpiry: set to true to log in again when the session expires, e.g. when its master token
+    expires, instead of failing the request. Only the key pair, OAuth client credentials, programmatic access
+    token and workload identity federation authenticators are supported, as well as OAuth with a
+    Config.TokenProvider and custom authenticators that implement NonInteractiveAuthenticator. Default value
+    is false.
+
   - clientConfigFile: specifies the location of the client configuration json file.
     In this file you can configure Easy Logging feature.
 
@@ -157,6 +167,13 @@ A complete connection string looks similar to the following:
 Session-level parameters can also be set by using the SQL command "ALTER SESSION"
 (https://docs.snowflake.com/en/sql-reference/sql/alter-session.html).
 
+Connections returned to the database/sql pool do not carry the session state a
+caller changed over to the next caller. Before a pooled connection is reused, the
+role, warehouse, database and schema changed with USE statements are restored to the
+ones the session had after login. A connection whose session was changed otherwise,
+e.g. with "ALTER SESSION", "SET" or "USE SECONDARY ROLES", is discarded instead, and
+so is a connection whose session is known to be expired.
+
 Alternatively, use OpenWithConfig() function to create a database handle with the specified Config.
 
 # Connection Config
@@ -201,6 +218,65 @@ If more than one value is specified, values should be separated by commas, for e
 
 	no_proxy=localhost,.my_company.com,xy12345.snowflakecomputing.com,192.168.1.15,192.168.1.16
 
+# Retry policy
+
+Failed HTTP requests, e.g. on connection errors or 5xx, 408 and 429 responses, are retried with a jittered
+exponential backoff. A RetryPolicy set in Config.RetryPolicy can change which requests are retried, how long
+to wait between attempts and how long to keep retrying. Each decision is made for a kind of request: login,
+query, query result polling, result chunk download or cloud storage transfer. DefaultRetryPolicy implements
+the default behavior and can be wrapped, for example to stop retrying statements after 30 seconds:
+
+	type shortQueryRetries struct {
+		sf.RetryPolicy
+	}
+
+	func (p shortQueryRetries) MaxElapsedTime(kind sf.RetryRequestKind) time.Duration {
+		if kind == sf.QueryRetryRequest {
+			return 30 * time.Second
+		}
+		return p.RetryPolicy.MaxElapsedTime(kind)
+	}
+
+	cfg.RetryPolicy = shortQueryRetries{sf.DefaultRetryPolicy}
+
+The number of retries is still limited by Config.MaxRetryCount.
+
+# Circuit breaker
+
+When an account or region is degraded, every connection retries its failed requests independently. Setting
+Config.CircuitBreaker enables a circuit breaker shared by all connections of the process, kept per host and
+endpoint class: login, query and result chunk storage. After a number of consecutive failed attempts the
+circuit breaker opens, and requests fail immediately with a SnowflakeError with the ErrCircuitBreakerOpen code.
+Once the open timeout has passed, a single probe request is let through and closes the circuit breaker if it
+succeeds:
+
+	cfg.CircuitBreaker = &sf.CircuitBreakerConfig{
+		FailureThreshold: 5,
+		OpenTimeout:      30 * time.Second,
+		OnStateChange: func(host string, endpoint sf.CircuitBreakerEndpoint, from, to sf.CircuitBreakerState) {
+			log.Printf("circuit breaker for %v requests to %v is %v", endpoint, host, to)
+		},
+	}
+
+# Session keep-alive
+
+When client_session_keep_alive is set, a heartbeat is sent in the background to keep the session of each
+connection alive. Config.HeartbeatInterval sets the interval between heartbeats, bounded by the
+CLIENT_SESSION_KEEP_ALIVE_HEARTBEAT_FREQUENCY parameter of the session. The heartbeats of all the connections of the
+process are sent by a single scheduler, with at most eight requests in flight. The first heartbeat of a session is
+sent at a random point of the second half of the interval and the next ones up to a tenth of the interval early, so
+that the connections of a pool do not heartbeat at the same time. Config.OnHeartbeat is called
+after each heartbeat. A connection whose heartbeats failed three times in a row is discarded the next time it is
+returned to the pool:
+
+	cfg.Params["client_session_keep_alive"] = &keepAlive
+	cfg.HeartbeatInterval = 15 * time.Minute
+	cfg.OnHeartbeat = func(result sf.HeartbeatResult) {
+		if result.Err != nil {
+			log.Printf("heartbeat of session %v failed %v times: %v", result.SessionID, result.ConsecutiveFailures, result.Err)
+		}
+	}
+
 # Logging
 
 By default, the driver's builtin logger is exposing logrus's FieldLogger and default at INFO level.
@@ -231,6 +307,35 @@ column in the Query History log. For example:
 	ctxWithQueryTag := WithQueryTag(ctx, queryTag)
 	rows, err := db.QueryContext(ctxWithQueryTag, query)
 
+# Statement timeout
+
+A statement timeout can be set in the context. Each query run with this context is
+sent with the STATEMENT_TIMEOUT_IN_SECONDS parameter, so Snowflake aborts it after the
+given time, without changing the statement timeout of the session:
+
+	ctxWithTimeout := WithStatementTimeout(ctx, 5*time.Minute)
+	rows, err := db.QueryContext(ctxWithTimeout, query)
+
+The timeout must be positive. To run a query without the statement timeout of the
+session, use WithoutStatementTimeout instead.
+
+When the context of a running synchronous query is canceled or reaches its deadline,
+the driver also asks Snowflake to abort the query.
+
+# Session parameters per query
+
+Session parameters can be set in the context for the queries run with it only, instead
+of with ALTER SESSION, which would leak into other uses of the pooled connection:
+
+	ctxWithParams := WithSessionParameters(ctx, map[string]string{
+		"TIMEZONE":          "UTC",
+		"USE_CACHED_RESULT": "false",
+	})
+	rows, err := db.QueryContext(ctxWithParams, query)
+
+The results are converted according to the overridden parameters, e.g. TIMESTAMP_LTZ
+values are returned in the overridden TIMEZONE.
+
 # Query request ID
 
 A specific query request ID can be set in the context and will be passed through
@@ -240,6 +345,30 @@ in place of the default randomized request ID. For example:
 	ctxWithID := WithRequestID(ctx, requestID)
 	rows, err := db.QueryContext(ctxWithID, query)
 
+# Query interceptors
+
+A QueryInterceptor set in Config.QueryInterceptor is invoked around every statement executed by the
+connections, including PUT and GET commands. BeforeExecute receives the query text, bindings, request ID and
+session parameters of the statement. It can rewrite them, or return an error to reject the statement.
+AfterExecute receives the query ID, duration, number of affected rows and error once the statement completes.
+It is also called for each statement of a multi-statement request. For example, to allow only SELECT statements:
+
+	type selectOnly struct{}
+
+	func (selectOnly) BeforeExecute(ctx context.Context, info *sf.QueryInfo) error {
+		if !info.IsInternal && !strings.HasPrefix(strings.ToUpper(strings.TrimSpace(info.Query)), "SELECT") {
+			return fmt.Errorf("statement not allowed: %v", info.Query)
+		}
+		info.Parameters["QUERY_TAG"] = "reporting"
+		return nil
+	}
+
+	func (selectOnly) AfterExecute(ctx context.Context, info *sf.QueryInfo, result *sf.QueryExecutionResult) {
+		log.Printf("query %v took %v, error: %v", result.QueryID, result.Duration, result.Err)
+	}
+
+	cfg.QueryInterceptor = selectOnly{}
+
 # Last query ID
 
 If you need query ID for your query you have to use raw connection.
@@ -270,6 +399,26 @@ For execs:
 
 ```
 
+# Query statistics
+
+The execution statistics Snowflake returns with the result of a statement, e.g. the
+statement type, the number of rows inserted, updated and deleted, or the database,
+schema, role and warehouse in use after the statement, are available from the raw
+result or rows without another round trip:
+
+	err := conn.Raw(func(x any) error {
+		result, err := x.(driver.ExecerContext).ExecContext(ctx, "MERGE INTO ...", nil)
+		if err != nil {
+			return err
+		}
+		stats, err := result.(sf.QueryStatsProvider).Stats()
+		if err != nil {
+			return err
+		}
+		log.Printf("%v: %v inserted, %v updated", stats.StatementType, stats.RowsInserted, stats.RowsUpdated)
+		return nil
+	})
+
 # Fetch Results by Query ID
 
 The result of your query can be retrieved by setting the query ID in the WithFetchResultByID context.
@@ -338,6 +487,36 @@ For example:
 	defer parent_span.End()
 	rows, err := db.QueryContext(ctx, query)
 
+# OpenTelemetry tracing
+
+The driver creates OpenTelemetry spans for its own work. Each call to ExecContext
+or QueryContext produces a "snowflake.exec" or "snowflake.query" span decorated
+with the query ID, statement type ID, number of rows and result format. Polling for
+the result of a long running query, logging in, downloading result chunks and
+uploading or downloading files during PUT and GET are recorded as child spans.
+
+Spans are created with the TracerProvider set in Config. If it is not set, the
+globally registered provider is used:
+
+	cfg.TracerProvider = sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter))
+	connector := NewConnector(SnowflakeDriver{}, *cfg)
+	db := sql.OpenDB(connector)
+
+# OpenTelemetry metrics
+
+The driver reports OpenTelemetry metrics through the MeterProvider set in Config,
+or the globally registered provider if it is not set. The following instruments are available:
+
+  - snowflake.http.attempts, snowflake.http.retries and snowflake.http.requests count HTTP
+    requests sent to Snowflake and cloud storage, the retries and the HTTP status that caused them,
+    and the final HTTP status of each request.
+  - snowflake.chunk.size, snowflake.chunk.decode.duration and snowflake.chunk.wait.duration describe
+    downloaded result chunks, the time spent decoding them and the time spent waiting for them while
+    iterating over rows.
+  - snowflake.session.heartbeats and snowflake.session.renewals count session heartbeats and token renewals.
+  - snowflake.file_transfer.files, snowflake.file_transfer.size and snowflake.file_transfer.duration
+    describe files uploaded and downloaded by PUT and GET.
+
 # Supported Data Types
 
 The Go Snowflake Driver now supports the Arrow data format for data transfers
@@ -436,6 +615,12 @@ data types. The columns are:
     -------------------------------------------------------------------------------------------------------------------
     BINARY               | []byte                                      | string                 | []byte
     -------------------------------------------------------------------------------------------------------------------
+    VECTOR [7]           | []float32, []int32                          | string                 | []float32, []int32
+    -------------------------------------------------------------------------------------------------------------------
+    GEOGRAPHY [8]        | string, []byte, Geography                   | string                 | string, Geography
+    -------------------------------------------------------------------------------------------------------------------
+    GEOMETRY [8]         | string, []byte, Geometry                    | string                 | string, Geometry
+    -------------------------------------------------------------------------------------------------------------------
     ARRAY [6]            | string / array                              | string / array
     -------------------------------------------------------------------------------------------------------------------
     OBJECT [6]           | string / struct                             | string / struct
@@ -461,6 +646,21 @@ data types. The columns are:
 
     [6] Arrays and objects can be either semistructured or structured, see more info in section below.
 
+    [7] VECTOR(FLOAT, N) columns are returned as []float32 and VECTOR(INT, N) as []int32. In Arrow batches they
+    are fixed-size list columns. To bind a vector, pass DataTypeVector before the []float32 or []int32 value and
+    cast the parameter in SQL:
+
+    db.Exec("INSERT INTO t SELECT ?::VECTOR(FLOAT, 3)", sf.DataTypeVector, []float32{1.1, 2.2, 3.3})
+
+    [8] GEOGRAPHY and GEOMETRY values are returned in the GEOGRAPHY_OUTPUT_FORMAT and GEOMETRY_OUTPUT_FORMAT,
+    as a string, or as []byte for WKB in Arrow. Scanning into Geography or Geometry decodes GeoJSON, WKT, EWKT, WKB
+    and EWKB into a GeoShape: GeoPoint, GeoLineString, GeoPolygon, GeoMultiPoint, GeoMultiLineString,
+    GeoMultiPolygon or GeoCollection. SRID is set for EWKT and EWKB. Arrow batches keep the columns as returned,
+    a string column for GeoJSON and WKT or a binary column for WKB, whose values can be scanned the same way.
+    Geography and Geometry are bound as WKT, or EWKT when SRID is set:
+
+    db.Exec("INSERT INTO t VALUES (?)", sf.Geography{Shape: sf.GeoPoint{-122.35, 37.55}})
+
 Note: SQL NULL values are converted to Golang nil values, and vice-versa.
 
 # Semistructured and structured types
@@ -775,6 +975,33 @@ All values are returned as strings.
 Alternative approach is to rerun a query, but without enabling Arrow batches and use a general Go SQL API instead of driver API.
 It can be optimized by using `WithRequestID`, so backend returns results from cache.
 
+# Prepared statements
+
+The result columns and bind parameters of a prepared statement are available through the StatementDescriber
+interface of the driver statement. The first time they are requested, the statement is sent to Snowflake in
+describe only mode, without being executed, so preparing a statement does not cost a round trip:
+
+	err = conn.Raw(func(x any) error {
+		stmt, err := x.(driver.ConnPrepareContext).PrepareContext(ctx, "SELECT id, name FROM users WHERE id = ?")
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+		columns, err := stmt.(StatementDescriber).ColumnTypes(ctx)
+		if err != nil {
+			return err
+		}
+		for _, column := range columns {
+			fmt.Println(column.Name, column.DatabaseTypeName, column.ScanType)
+		}
+		return nil
+	})
+
+PUT and GET commands and multi-statement queries cannot be described. NumInput describes the statement and returns
+the number of bind parameters, so database/sql checks the number of arguments before executing it. Type markers such
+as DataTypeBinary are not counted, as they are bound along with the value after them. NumInput returns -1 if the
+statement cannot be described, and the arguments are then checked when the statement is executed.
+
 # Binding Parameters
 
 Binding allows a SQL statement to use a value that is stored in a Golang variable.
@@ -794,6 +1021,23 @@ The “?“ inside the “VALUES“ clause specifies that the SQL statement uses
 Binding data that involves time zones can require special handling. For details, see the section
 titled "Timestamps with Time Zones".
 
+To bind NUMBER values without losing precision, use *big.Int, *big.Float or Decimal. Decimal is an exact
+fixed-scale decimal number that can be created by NewDecimal or ParseDecimal, which reject scales and
+exponents outside [-400, 400]. All of them are bound as NUMBER, both as single values and in arrays created
+by the Array() function:
+
+	amount, err := sf.ParseDecimal("12345678901234567890123456789.123456789")
+	_, err = db.Exec("INSERT INTO payments(amount) VALUES (?)", amount)
+
+	fee, err := sf.NewDecimal(big.NewInt(1050), 2)
+	amounts := []sf.Decimal{fee, amount}
+	_, err = db.Exec("INSERT INTO payments(amount) VALUES (?)", sf.Array(&amounts))
+
+Decimal also implements sql.Scanner, so NUMBER columns with a scale can be read back exactly:
+
+	var amount sf.Decimal
+	err = db.QueryRow("SELECT amount FROM payments").Scan(&amount)
+
 Version 1.6.23 (and later) of the driver takes advantage of sql.Null types which enables the proper handling of null parameters inside function calls, i.e.:
 
 	rows, err := db.Query("SELECT * FROM TABLE(SOMEFUNCTION(?))", sql.NullBool{})
@@ -998,9 +1242,44 @@ To generate the valid key pair, you can execute the following commands in the sh
 	    	-in rsa-2048-private-key.p8 \
 	    	-out rsa-2048-public-key.spki
 
-Note: As of February 2020, Golang's official library does not support passcode-encrypted PKCS8 private key.
-For security purposes, Snowflake highly recommends that you store the passcode-encrypted private key on the disk and
-decrypt the key in your application using a library you trust.
+For security purposes, Snowflake highly recommends that you store the passcode-encrypted private key on the disk.
+Instead of privateKey, set "privateKeyFile" to the path of a PKCS8 PEM private key file, encrypted or not, and set the
+passphrase with "privateKeyFilePwd", or with "privateKeyFilePwdEnv" naming the environment variable holding it.
+In connections.toml, "private_key_file", "private_key_file_pwd" and "private_key_file_pwd_env" are accepted as well.
+The key is read when the DSN is parsed and the DSN keeps referencing the file:
+
+	# encrypt the private key with a passphrase
+	openssl pkcs8 -topk8 -v2 aes256 -in rsa-2048-private-key.p8 -inform der -out rsa-2048-private-key-encrypted.p8
+
+	db, err := sql.Open("snowflake", "user@account/db?authenticator=SNOWFLAKE_JWT&privateKeyFile=%2Fkeys%2Frsa-2048-private-key-encrypted.p8&privateKeyFilePwdEnv=PRIVATE_KEY_PWD")
+
+Keys encrypted with PBES2, using PBKDF2 and AES-CBC or DES-EDE3-CBC, are supported. Keys encrypted with scrypt or
+PBES1, and legacy OpenSSL encrypted PEM keys, are rejected with ErrCodeUnsupportedPrivateKeyFormat, while a missing or
+wrong passphrase is reported with ErrCodeInvalidPrivateKeyPassphrase.
+
+To keep the private key out of the process memory, e.g. in a KMS or an HSM, set Config.PrivateKeySigner to a
+crypto.Signer with an RSA public key instead. The JWT is signed with RS256 by calling its Sign method. A DSN or
+connections.toml references a signer registered under a name with the "privateKeySigner" parameter:
+
+	if err := sf.RegisterPrivateKeySigner("service-account", kmsSigner); err != nil {
+		log.Fatal(err)
+	}
+	db, err := sql.Open("snowflake", "user@account/db?authenticator=SNOWFLAKE_JWT&privateKeySigner=service-account")
+
+To rotate the key pair without downtime, set the new key as RSA_PUBLIC_KEY_2 of the user and set
+Config.PrivateKeySource to return both private keys. At each login, the driver signs the JWT with the key the
+last successful login of the account and user used, the first key at first, and tries the next key when Snowflake
+rejects the JWT (error 390144), until each key was tried once. The key that worked is remembered by the driver,
+so that the new connections of a pool start with it. The fingerprint of the key in use is logged, to compare with
+RSA_PUBLIC_KEY_FP and RSA_PUBLIC_KEY_2_FP. PrivateKeysFromFiles reads the keys from files at each login, so that
+a rotated file is picked up without a restart:
+
+	config.PrivateKeySource = sf.PrivateKeysFromFiles("/etc/snowflake/new-key.p8", "/etc/snowflake/old-key.p8")
+
+EncryptedPrivateKeysFromFiles does the same for keys encrypted with a passphrase:
+
+	config.PrivateKeySource = sf.EncryptedPrivateKeysFromFiles(os.Getenv("PRIVATE_KEY_PASSPHRASE"),
+		"/etc/snowflake/new-key.p8", "/etc/snowflake/old-key.p8")
 
 JWT tokens are recreated on each retry and they are valid (`exp` claim) for `jwtTimeout` seconds.
 Each retry timeout is configured by `jwtClientTimeout`.
@@ -1030,6 +1309,64 @@ or using a Config structure with following ExternalBrowserTimeout specified:
 		ExternalBrowserTimeout: 240 * time.Second, // Requires time.Duration
 	}
 
+# Token provider
+
+Config.Token is read once, so a short-lived token, e.g. one refreshed by a sidecar, expires while the process keeps
+opening connections. Config.TokenProvider is called instead on every login of the OAuth, programmatic access token
+and OIDC workload identity authenticators, and once more when Snowflake rejects the token as invalid or expired:
+
+	cfg.Authenticator = sf.AuthTypeOAuth
+	cfg.TokenProvider = func(ctx context.Context) (string, error) {
+		token, err := os.ReadFile("/var/run/secrets/snowflake/token")
+		return strings.TrimSpace(string(token)), err
+	}
+
+With a token provider, connections using the OAuth authenticator can also log in again when their session expires,
+if reauthenticateOnExpiry is set.
+
+# Custom authenticators
+
+A login flow the driver does not support, e.g. with a token issued by an internal token broker, is plugged in by
+implementing the Authenticator interface and setting it in Config.CustomAuthenticator. PrepareLogin fills in the
+login request before each attempt: the authenticator name, the token and any extra values reported in the client
+environment. When Snowflake rejects the login, HandleLoginError may refresh the credentials or answer the
+challenge and ask for another attempt, up to two more times:
+
+	type brokerAuthenticator struct {
+		broker *tokenBroker
+	}
+
+	func (a *brokerAuthenticator) PrepareLogin(ctx context.Context, req *sf.LoginRequest) error {
+		token, err := a.broker.Token(ctx, req.User)
+		if err != nil {
+			return err
+		}
+		req.Authenticator = "OAUTH"
+		req.LoginName = req.User
+		req.Token = token
+		req.ClientEnvironment = map[string]string{"TOKEN_BROKER": a.broker.Name()}
+		return nil
+	}
+
+	func (a *brokerAuthenticator) HandleLoginError(ctx context.Context, err *sf.SnowflakeError, attempt int) (bool, error) {
+		if err.Number != 390303 && err.Number != 390318 {
+			return false, nil
+		}
+		// the token is invalid or expired
+		return true, a.broker.Invalidate(ctx)
+	}
+
+	cfg.CustomAuthenticator = &brokerAuthenticator{broker}
+
+A connection logs in again with a custom authenticator when its session expires and reauthenticateOnExpiry is
+set only if the authenticator implements NonInteractiveAuthenticator and needs no user interaction:
+
+	func (a *brokerAuthenticator) NonInteractive() bool {
+		return true
+	}
+
+The built-in authenticators selected with Config.Authenticator implement the same interface.
+
 # Executing Multiple Statements in One Call
 
 This feature is available in version 1.3.8 or later of the driver.
@@ -1250,6 +1587,46 @@ and before retrieving the results. For a more elaborative example please see cmd
 			...
 		}
 
+# Query handles
+
+QueryHandleConnection.SubmitQuery submits a statement in asynchronous mode and returns
+as soon as Snowflake accepted it, with a QueryHandle to the running query. The handle
+tells the status of the query, waits for it, cancels it or fetches its result as rows
+or Arrow batches:
+
+	conn, _ := db.Conn(ctx)
+	defer conn.Close()
+	var handle sf.QueryHandle
+	err := conn.Raw(func(x any) error {
+		var err error
+		handle, err = x.(sf.QueryHandleConnection).SubmitQuery(ctx, "CALL long_running_procedure()")
+		return err
+	})
+	...
+	if err = handle.Wait(ctx); err != nil {
+		// handle error
+	}
+	rows, err := handle.Rows(ctx)
+
+The result of a query is kept by Snowflake after the query completes, so a handle can
+also be recovered from the query ID on another connection, e.g. in another process,
+with QueryHandleConnection.GetQueryHandle. The handle is bound to the connection that
+returned it and must not be used after the connection is closed.
+
+A running query can also be canceled by its query ID alone, from any connection of the
+same user, with QueryHandleConnection.CancelQuery:
+
+	err := conn.Raw(func(x any) error {
+		return x.(sf.QueryHandleConnection).CancelQuery(ctx, queryID)
+	})
+
+CancelQuery checks the status of the query afterwards. If the query is not aborted, e.g. because it already
+completed, CancelQuery returns an error with the ErrQueryNotCanceled code.
+
+These methods are on QueryHandleConnection rather than on SnowflakeConnection, so that the types implementing
+SnowflakeConnection outside the driver, e.g. mocks in tests, keep compiling. The driver connections implement both
+interfaces.
+
 # Support For PUT and GET
 
 The Go Snowflake Driver supports the PUT and GET commands.
@@ -1338,6 +1715,24 @@ Using custom configuration for PUT/GET:
 If you want to override some default configuration options, you can use `WithFileTransferOptions` context.
 There are multiple config parameters including progress bars or compression.
 
+# Error classification
+
+Errors returned by Snowflake or by the driver are *SnowflakeError values carrying the error Number and SQLState.
+Instead of checking the numbers, errors can be classified with IsRetryable, IsAuthenticationError,
+IsSessionExpired, IsQueryTimeout, IsCanceled, IsObjectNotFound, IsPermissionDenied and IsSyntaxError, or with
+errors.Is against the error class sentinels ErrRetryable, ErrAuthenticationFailed, ErrSessionExpired,
+ErrQueryTimeout, ErrQueryCanceled, ErrObjectNotFound, ErrPermissionDenied and ErrSyntax:
+
+	_, err := db.ExecContext(ctx, "INSERT INTO t SELECT * FROM s")
+	switch {
+	case sf.IsRetryable(err):
+		// retry later
+	case errors.Is(err, sf.ErrObjectNotFound):
+		// create the table
+	}
+
+errors.Is also matches a *SnowflakeError with the same Number, e.g. errors.Is(err, &sf.SnowflakeError{Number: 2003}).
+
 # Surfacing errors originating from PUT and GET commands
 
 Default behaviour is to propagate the potential underlying errors encountered during executing calls associated with the PUT or GET commands to the caller, for increased awareness and easier handling or troubleshooting them.
@@ -1,10 +1,10 @@
 package gosnowflake
 
 import (
+	"context"
+	"crypto"
 	"crypto/rsa"
-	"crypto/x509"
 	"encoding/base64"
-	"encoding/pem"
 	"errors"
 	"fmt"
 	"net"
@@ -14,6 +14,9 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -96,10 +99,36 @@ type Config struct {
 	TokenAccessor    TokenAccessor // Optional token accessor to use
 	KeepSessionAlive bool          // Enables the session to persist even after the connection is closed
 
-	PrivateKey *rsa.PrivateKey // Private key used to sign JWT
+	TokenProvider func(ctx context.Context) (string, error) // Returns the token at each login instead of Token, for OAuth, programmatic access token and OIDC workload identity
+
+	ReauthenticateOnExpiry bool // Logs in again when the session expires, for non-interactive authenticators only
+
+	HeartbeatInterval time.Duration         // Interval between heartbeats when client_session_keep_alive is set. One hour if not set
+	OnHeartbeat       func(HeartbeatResult) // Called after each heartbeat when client_session_keep_alive is set
+
+	CustomAuthenticator Authenticator // Login flow used instead of the built-in authenticators if set
+
+	PrivateKey       *rsa.PrivateKey // Private key used to sign JWT
+	PrivateKeySigner crypto.Signer   // Signs the JWT instead of PrivateKey, e.g. with a key held by a KMS or an HSM. Its public key must be an RSA key
+
+	PrivateKeySource func(ctx context.Context) ([]crypto.Signer, error) // Returns the keys tried in order at each login instead of PrivateKey or PrivateKeySigner, e.g. during a key rotation
+
+	PrivateKeyFile       string // Path of a PKCS #8 PEM private key file PrivateKey is read from if not set. The file may be encrypted with PBES2
+	PrivateKeyFilePwd    string // Passphrase of an encrypted PrivateKeyFile
+	PrivateKeyFilePwdEnv string // Name of the environment variable holding the passphrase of PrivateKeyFile if PrivateKeyFilePwd is not set
 
 	Transporter http.RoundTripper // RoundTripper to intercept HTTP requests and responses
 
+	TracerProvider trace.TracerProvider // OpenTelemetry tracer provider used to create driver spans. The global provider is used if not set
+	MeterProvider  metric.MeterProvider // OpenTelemetry meter provider used to report driver metrics. The global provider is used if not set
+
+	metrics *metricsCache // instruments of MeterProvider, shared by the connections created with the config
+
+	QueryInterceptor QueryInterceptor // Invoked before and after every statement executed by the connection
+
+	RetryPolicy    RetryPolicy           // Decides which HTTP requests are retried and how long to wait. DefaultRetryPolicy is used if not set
+	CircuitBreaker *CircuitBreakerConfig // Enables a circuit breaker around Snowflake endpoints if set
+
 	DisableTelemetry bool // indicates whether to disable telemetry
 
 	Tracing string // sets logging level
@@ -223,7 +252,7 @@ func DSN(cfg *Config) (dsn string, err error) {
 	if cfg.WorkloadIdentityEntraResource != "" {
 		params.Add("workloadIdentityEntraResource", cfg.WorkloadIdentityEntraResource)
 	}
-	if cfg.Authenticator != AuthTypeSnowflake {
+	if cfg.Authenticator != AuthTypeSnowflake && cfg.Authenticator != AuthTypeCustom {
 		if cfg.Authenticator == AuthTypeOkta {
 			params.Add("authenticator", strings.ToLower(cfg.OktaURL.String()))
 		} else {
@@ -257,6 +286,9 @@ func DSN(cfg *Config) (dsn string, err error) {
 	if cfg.CloudStorageTimeout != defaultCloudStorageTimeout {
 		params.Add("cloudStorageTimeout", strconv.FormatInt(int64(cfg.CloudStorageTimeout/time.Second), 10))
 	}
+	if cfg.HeartbeatInterval != 0 {
+		params.Add("heartbeatInterval", strconv.FormatInt(int64(cfg.HeartbeatInterval/time.Second), 10))
+	}
 	if cfg.MaxRetryCount != defaultMaxRetryCount {
 		params.Add("maxRetryCount", strconv.Itoa(cfg.MaxRetryCount))
 	}
@@ -274,7 +306,16 @@ func DSN(cfg *Config) (dsn string, err error) {
 			params.Add(k, *v)
 		}
 	}
-	if cfg.PrivateKey != nil {
+	if cfg.PrivateKeyFile != "" {
+		// the key stays encrypted at rest instead of being written in the DSN
+		params.Add("privateKeyFile", cfg.PrivateKeyFile)
+		if cfg.PrivateKeyFilePwd != "" {
+			params.Add("privateKeyFilePwd", cfg.PrivateKeyFilePwd)
+		}
+		if cfg.PrivateKeyFilePwdEnv != "" {
+			params.Add("privateKeyFilePwdEnv", cfg.PrivateKeyFilePwdEnv)
+		}
+	} else if cfg.PrivateKey != nil {
 		privateKeyInBytes, err := marshalPKCS8PrivateKey(cfg.PrivateKey)
 		if err != nil {
 			return "", err
@@ -282,6 +323,13 @@ func DSN(cfg *Config) (dsn string, err error) {
 		keyBase64 := base64.URLEncoding.EncodeToString(privateKeyInBytes)
 		params.Add("privateKey", keyBase64)
 	}
+	if cfg.PrivateKeySigner != nil {
+		name, ok := getPrivateKeySignerName(cfg.PrivateKeySigner)
+		if !ok {
+			return "", errUnregisteredPrivateKeySigner()
+		}
+		params.Add("privateKeySigner", name)
+	}
 	if cfg.InsecureMode {
 		params.Add("insecureMode", strconv.FormatBool(cfg.InsecureMode))
 	}
@@ -297,6 +345,9 @@ func DSN(cfg *Config) (dsn string, err error) {
 	if cfg.DisableQueryContextCache {
 		params.Add("disableQueryContextCache", "true")
 	}
+	if cfg.ReauthenticateOnExpiry {
+		params.Add("reauthenticateOnExpiry", "true")
+	}
 	if cfg.IncludeRetryReason == ConfigBoolFalse {
 		params.Add("includeRetryReason", "false")
 	}
@@ -485,6 +536,16 @@ func fillMissingConfigParameters(cfg *Config) error {
 		return errEmptyAccount()
 	}
 
+	if cfg.CustomAuthenticator != nil {
+		cfg.Authenticator = AuthTypeCustom
+	} else if cfg.Authenticator == AuthTypeCustom {
+		return errEmptyCustomAuthenticator()
+	}
+
+	if err := cfg.loadPrivateKeyFile(); err != nil {
+		return err
+	}
+
 	if authRequiresUser(cfg) && strings.TrimSpace(cfg.User) == "" {
 		return errEmptyUsername()
 	}
@@ -493,7 +554,7 @@ func fillMissingConfigParameters(cfg *Config) error {
 		return errEmptyPassword()
 	}
 
-	if authRequiresEitherPasswordOrToken(cfg) && strings.TrimSpace(cfg.Password) == "" && strings.TrimSpace(cfg.Token) == "" {
+	if authRequiresEitherPasswordOrToken(cfg) && strings.TrimSpace(cfg.Password) == "" && strings.TrimSpace(cfg.Token) == "" && cfg.TokenProvider == nil {
 		return errEmptyPasswordAndToken()
 	}
 
@@ -614,7 +675,8 @@ func buildHostFromAccountAndRegion(account, region string) string {
 }
 
 func authRequiresUser(cfg *Config) bool {
-	return cfg.Authenticator != AuthTypeOAuth &&
+	return cfg.Authenticator != AuthTypeCustom &&
+		cfg.Authenticator != AuthTypeOAuth &&
 		cfg.Authenticator != AuthTypeTokenAccessor &&
 		cfg.Authenticator != AuthTypeExternalBrowser &&
 		cfg.Authenticator != AuthTypePat &&
@@ -623,7 +685,8 @@ func authRequiresUser(cfg *Config) bool {
 }
 
 func authRequiresPassword(cfg *Config) bool {
-	return cfg.Authenticator != AuthTypeOAuth &&
+	return cfg.Authenticator != AuthTypeCustom &&
+		cfg.Authenticator != AuthTypeOAuth &&
 		cfg.Authenticator != AuthTypeTokenAccessor &&
 		cfg.Authenticator != AuthTypeExternalBrowser &&
 		cfg.Authenticator != AuthTypeJwt &&
@@ -778,6 +841,11 @@ func parseDSNParams(cfg *Config, params string) (err error) {
 			if err != nil {
 				return
 			}
+		case "heartbeatInterval":
+			cfg.HeartbeatInterval, err = parseTimeout(value)
+			if err != nil {
+				return
+			}
 		case "loginTimeout":
 			cfg.LoginTimeout, err = parseTimeout(value)
 			if err != nil {
@@ -862,6 +930,17 @@ func parseDSNParams(cfg *Config, params string) (err error) {
 			if err != nil {
 				return err
 			}
+		case "privateKeyFile":
+			cfg.PrivateKeyFile = value
+		case "privateKeyFilePwd":
+			cfg.PrivateKeyFilePwd = value
+		case "privateKeyFilePwdEnv":
+			cfg.PrivateKeyFilePwdEnv = value
+		case "privateKeySigner":
+			cfg.PrivateKeySigner, err = getPrivateKeySigner(value)
+			if err != nil {
+				return err
+			}
 		case "validateDefaultParameters":
 			var vv bool
 			vv, err = strconv.ParseBool(value)
@@ -906,6 +985,13 @@ func parseDSNParams(cfg *Config, params string) (err error) {
 				return
 			}
 			cfg.DisableQueryContextCache = b
+		case "reauthenticateOnExpiry":
+			var b bool
+			b, err = strconv.ParseBool(value)
+			if err != nil {
+				return
+			}
+			cfg.ReauthenticateOnExpiry = b
 		case "includeRetryReason":
 			var vv bool
 			vv, err = strconv.ParseBool(value)
@@ -977,7 +1063,7 @@ type ConfigParam struct {
 func GetConfigFromEnv(properties []*ConfigParam) (*Config, error) {
 	var account, user, password, token, role, host, portStr, protocol, warehouse, database, schema, region, passcode, application string
 	var oauthClientID, oauthClientSecret, oauthAuthorizationURL, oauthTokenRequestURL, oauthRedirectURI, oauthScope string
-	var privateKey *rsa.PrivateKey
+	var privateKeyFile, privateKeyFilePwd string
 	var err error
 	if len(properties) == 0 || properties == nil {
 		return nil, errors.New("missing configuration parameters for the connection")
@@ -1017,10 +1103,9 @@ func GetConfigFromEnv(properties []*ConfigParam) (*Config, error) {
 		case "Application":
 			application = value
 		case "PrivateKey":
-			privateKey, err = parsePrivateKeyFromFile(value)
-			if err != nil {
-				return nil, err
-			}
+			privateKeyFile = value
+		case "PrivateKeyFilePwd":
+			privateKeyFilePwd = value
 		case "OAuthClientId":
 			oauthClientID = value
 		case "OAuthClientSecret":
@@ -1058,7 +1143,8 @@ func GetConfigFromEnv(properties []*ConfigParam) (*Config, error) {
 		Warehouse:             warehouse,
 		Database:              database,
 		Schema:                schema,
-		PrivateKey:            privateKey,
+		PrivateKeyFile:        privateKeyFile,
+		PrivateKeyFilePwd:     privateKeyFilePwd,
 		Region:                region,
 		Passcode:              passcode,
 		Application:           application,
@@ -1070,27 +1156,11 @@ func GetConfigFromEnv(properties []*ConfigParam) (*Config, error) {
 		OauthScope:            oauthScope,
 		Params:                map[string]*string{},
 	}
-	return cfg, nil
-}
-
-func parsePrivateKeyFromFile(path string) (*rsa.PrivateKey, error) {
-	bytes, err := os.ReadFile(path)
-	if err != nil {
+	// read after all the properties, as the passphrase may come after the file
+	if err = cfg.loadPrivateKeyFile(); err != nil {
 		return nil, err
 	}
-	block, _ := pem.Decode(bytes)
-	if block == nil {
-		return nil, errors.New("failed to parse PEM block containing the private key")
-	}
-	privateKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
-	if err != nil {
-		return nil, err
-	}
-	pk, ok := privateKey.(*rsa.PrivateKey)
-	if !ok {
-		return nil, fmt.Errorf("interface convertion. expected type *rsa.PrivateKey, but got %T", privateKey)
-	}
-	return pk, nil
+	return cfg, nil
 }
 
 func extractAccountName(rawAccount string) string {
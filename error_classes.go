@@ -0,0 +1,160 @@
+package gosnowflake
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"syscall"
+)
+
+// Snowflake server error numbers used to classify errors
+const (
+	sqlExecutionInternalErrorNumber     = 603
+	sqlExecutionCanceledNumber          = 604
+	statementTimeoutNumber              = 630
+	syntaxErrorNumber                   = 1003
+	objectNotExistNumber                = 2003
+	objectNotExistOrCannotOperateNumber = 2043
+	insufficientPrivilegesNumber        = 3001
+	incorrectUsernameOrPasswordNumber   = 390100
+	masterTokenExpiredNumber            = 390114
+	invalidJWTTokenNumber               = 390144
+	roleNotGrantedNumber                = 390186
+)
+
+// Error class sentinels. They match, with errors.Is, any SnowflakeError of their class,
+// e.g. errors.Is(err, ErrSessionExpired).
+var (
+	// ErrRetryable matches errors caused by transient conditions, e.g. an unavailable service.
+	ErrRetryable = &SnowflakeError{Message: "retryable error", matches: isRetryableSnowflakeError}
+	// ErrAuthenticationFailed matches errors returned when the connection could not authenticate.
+	ErrAuthenticationFailed = &SnowflakeError{Message: "authentication failed", matches: isAuthenticationSnowflakeError}
+	// ErrSessionExpired matches errors returned when the session is closed or its tokens expired.
+	ErrSessionExpired = &SnowflakeError{Message: "session expired", matches: isSessionExpiredSnowflakeError}
+	// ErrQueryTimeout matches errors returned when a statement reached its statement or warehouse timeout.
+	ErrQueryTimeout = &SnowflakeError{Message: "query timeout", matches: isNumber(statementTimeoutNumber)}
+	// ErrQueryCanceled matches errors returned when a statement was canceled.
+	ErrQueryCanceled = &SnowflakeError{Message: "query canceled", matches: isNumber(sqlExecutionCanceledNumber)}
+	// ErrObjectNotFound matches errors returned when an object does not exist or is not authorized.
+	ErrObjectNotFound = &SnowflakeError{Message: "object not found", matches: isObjectNotFoundSnowflakeError}
+	// ErrPermissionDenied matches errors returned when the role lacks privileges.
+	ErrPermissionDenied = &SnowflakeError{Message: "permission denied", matches: isPermissionDeniedSnowflakeError}
+	// ErrSyntax matches SQL compilation errors caused by the statement syntax.
+	ErrSyntax = &SnowflakeError{Message: "syntax error", matches: isNumber(syntaxErrorNumber)}
+)
+
+// IsRetryable tells whether err is caused by a transient condition, so the failed
+// operation may succeed if it is retried, possibly on a new connection. Besides
+// ErrRetryable, it includes network errors such as connection resets and timeouts.
+// Context cancellation and deadlines are not retryable.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, ErrRetryable) || errors.Is(err, driver.ErrBadConn) {
+		return true
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// IsAuthenticationError tells whether err is returned because the connection could not
+// authenticate, e.g. wrong credentials or an invalid key pair.
+func IsAuthenticationError(err error) bool {
+	return errors.Is(err, ErrAuthenticationFailed)
+}
+
+// IsSessionExpired tells whether err is returned because the session is closed or its
+// tokens expired. A new connection is required.
+func IsSessionExpired(err error) bool {
+	return errors.Is(err, ErrSessionExpired)
+}
+
+// IsQueryTimeout tells whether err is returned because a statement reached its
+// statement or warehouse timeout, or the deadline of its context.
+func IsQueryTimeout(err error) bool {
+	return errors.Is(err, ErrQueryTimeout) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// IsCanceled tells whether err is returned because a statement was canceled, on the
+// server or by canceling its context.
+func IsCanceled(err error) bool {
+	return errors.Is(err, ErrQueryCanceled) || errors.Is(err, context.Canceled)
+}
+
+// IsObjectNotFound tells whether err is returned because an object does not exist or
+// is not authorized.
+func IsObjectNotFound(err error) bool {
+	return errors.Is(err, ErrObjectNotFound)
+}
+
+// IsPermissionDenied tells whether err is returned because the role lacks privileges.
+func IsPermissionDenied(err error) bool {
+	return errors.Is(err, ErrPermissionDenied)
+}
+
+// IsSyntaxError tells whether err is a SQL compilation error caused by the statement syntax.
+func IsSyntaxError(err error) bool {
+	return errors.Is(err, ErrSyntax)
+}
+
+func isNumber(numbers ...int) func(*SnowflakeError) bool {
+	return func(se *SnowflakeError) bool {
+		return contains(numbers, se.Number)
+	}
+}
+
+func isRetryableSnowflakeError(se *SnowflakeError) bool {
+	switch se.Number {
+	case ErrCodeServiceUnavailable, ErrCircuitBreakerOpen, ErrFailedToGetChunk, sqlExecutionInternalErrorNumber:
+		return true
+	case ErrFailedToPostQuery, ErrFailedToRenewSession, ErrFailedToCloseSession, ErrFailedToCancelQuery:
+		// the HTTP status is the first message argument
+		if len(se.MessageArgs) > 0 {
+			if status, ok := se.MessageArgs[0].(int); ok {
+				return isRetryableStatus(status)
+			}
+		}
+	}
+	return false
+}
+
+func isAuthenticationSnowflakeError(se *SnowflakeError) bool {
+	switch se.Number {
+	case ErrCodeFailedToConnect, ErrFailedToAuth, ErrFailedToAuthSAML, ErrFailedToAuthOKTA, ErrFailedToGetSSO,
+		ErrCodeIdpConnectionError, ErrCodeSSOURLNotMatch, ErrCodePrivateKeyParseError,
+		incorrectUsernameOrPasswordNumber, invalidJWTTokenNumber:
+		return true
+	}
+	if se.Number == 0 || isSessionExpiredSnowflakeError(se) {
+		return false
+	}
+	return se.SQLState == SQLStateConnectionRejected
+}
+
+func isSessionExpiredSnowflakeError(se *SnowflakeError) bool {
+	switch se.Number {
+	case ErrSessionGone, masterTokenExpiredNumber:
+		return true
+	}
+	return se.Number != 0 && strconv.Itoa(se.Number) == sessionExpiredCode
+}
+
+func isObjectNotFoundSnowflakeError(se *SnowflakeError) bool {
+	switch se.Number {
+	case objectNotExistNumber, objectNotExistOrCannotOperateNumber, ErrObjectNotExistOrAuthorized, ErrRoleNotExist:
+		return true
+	}
+	return se.SQLState == SQLStateObjectNotFound
+}
+
+func isPermissionDeniedSnowflakeError(se *SnowflakeError) bool {
+	return se.Number == insufficientPrivilegesNumber || se.Number == roleNotGrantedNumber ||
+		se.SQLState == SQLStateInsufficientPrivilege
+}
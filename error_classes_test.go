@@ -0,0 +1,83 @@
+package gosnowflake
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"testing"
+)
+
+func TestSnowflakeErrorIs(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", &SnowflakeError{Number: ErrSessionGone, SQLState: SQLStateConnectionRejected})
+	assertTrueE(t, errors.Is(err, &SnowflakeError{Number: ErrSessionGone}))
+	assertTrueE(t, errors.Is(err, &SnowflakeError{Number: ErrSessionGone, SQLState: SQLStateConnectionRejected}))
+	assertFalseE(t, errors.Is(err, &SnowflakeError{Number: ErrSessionGone, SQLState: SQLStateConnectionFailure}))
+	assertFalseE(t, errors.Is(err, &SnowflakeError{Number: ErrRoleNotExist}))
+	assertFalseE(t, errors.Is(err, &SnowflakeError{}))
+	assertTrueE(t, errors.Is(err, ErrSessionExpired))
+	assertFalseE(t, errors.Is(err, ErrObjectNotFound))
+	assertFalseE(t, errors.Is(errors.New("session expired"), ErrSessionExpired))
+}
+
+func TestErrorClasses(t *testing.T) {
+	type classifier struct {
+		name string
+		fn   func(error) bool
+	}
+	classifiers := []classifier{
+		{"IsRetryable", IsRetryable},
+		{"IsAuthenticationError", IsAuthenticationError},
+		{"IsSessionExpired", IsSessionExpired},
+		{"IsQueryTimeout", IsQueryTimeout},
+		{"IsCanceled", IsCanceled},
+		{"IsObjectNotFound", IsObjectNotFound},
+		{"IsPermissionDenied", IsPermissionDenied},
+		{"IsSyntaxError", IsSyntaxError},
+	}
+	testcases := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{name: "service unavailable", err: &SnowflakeError{Number: ErrCodeServiceUnavailable, SQLState: SQLStateConnectionWasNotEstablished, MessageArgs: []interface{}{http.StatusServiceUnavailable, "url"}}, expected: "IsRetryable"},
+		{name: "circuit breaker open", err: errCircuitBreakerOpen(CircuitBreakerQuery, "host"), expected: "IsRetryable"},
+		{name: "POST failed with 503", err: &SnowflakeError{Number: ErrFailedToPostQuery, MessageArgs: []interface{}{http.StatusServiceUnavailable, "url"}}, expected: "IsRetryable"},
+		{name: "POST failed with 400", err: &SnowflakeError{Number: ErrFailedToPostQuery, MessageArgs: []interface{}{http.StatusBadRequest, "url"}}},
+		{name: "internal error", err: &SnowflakeError{Number: 603, SQLState: "XX000"}, expected: "IsRetryable"},
+		{name: "connection reset", err: fmt.Errorf("read: %w", syscall.ECONNRESET), expected: "IsRetryable"},
+		{name: "unexpected EOF", err: io.ErrUnexpectedEOF, expected: "IsRetryable"},
+		{name: "bad connection", err: driver.ErrBadConn, expected: "IsRetryable"},
+		{name: "network timeout", err: &net.OpError{Op: "dial", Err: &fakeHTTPError{err: "i/o timeout", timeout: true}}, expected: "IsRetryable"},
+		{name: "wrong password", err: &SnowflakeError{Number: 390100, SQLState: SQLStateConnectionRejected}, expected: "IsAuthenticationError"},
+		{name: "invalid JWT", err: &SnowflakeError{Number: 390144, SQLState: SQLStateConnectionRejected}, expected: "IsAuthenticationError"},
+		{name: "failed to connect", err: &SnowflakeError{Number: ErrCodeFailedToConnect, SQLState: SQLStateConnectionRejected}, expected: "IsAuthenticationError"},
+		{name: "user locked", err: &SnowflakeError{Number: 390102, SQLState: SQLStateConnectionRejected}, expected: "IsAuthenticationError"},
+		{name: "session gone", err: &SnowflakeError{Number: ErrSessionGone}, expected: "IsSessionExpired"},
+		{name: "session token expired", err: &SnowflakeError{Number: 390112}, expected: "IsSessionExpired"},
+		{name: "master token expired", err: &SnowflakeError{Number: 390114, SQLState: SQLStateConnectionRejected}, expected: "IsSessionExpired"},
+		{name: "statement timeout", err: &SnowflakeError{Number: 630, SQLState: SQLStateQueryCanceled}, expected: "IsQueryTimeout"},
+		{name: "deadline exceeded", err: fmt.Errorf("query: %w", context.DeadlineExceeded), expected: "IsQueryTimeout"},
+		{name: "execution canceled", err: &SnowflakeError{Number: 604, SQLState: SQLStateQueryCanceled}, expected: "IsCanceled"},
+		{name: "context canceled", err: context.Canceled, expected: "IsCanceled"},
+		{name: "object does not exist", err: &SnowflakeError{Number: 2003, SQLState: SQLStateObjectNotFound}, expected: "IsObjectNotFound"},
+		{name: "role does not exist", err: &SnowflakeError{Number: ErrRoleNotExist}, expected: "IsObjectNotFound"},
+		{name: "insufficient privileges", err: &SnowflakeError{Number: 3001, SQLState: SQLStateInsufficientPrivilege}, expected: "IsPermissionDenied"},
+		{name: "syntax error", err: &SnowflakeError{Number: 1003, SQLState: SQLStateSyntaxErrorOrAccessRuleViolation}, expected: "IsSyntaxError"},
+		{name: "division by zero", err: &SnowflakeError{Number: 100051, SQLState: "22012"}},
+		{name: "other error", err: errors.New("other")},
+		{name: "nil", err: nil},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, c := range classifiers {
+				assertEqualE(t, c.fn(tc.err), c.name == tc.expected, c.name)
+				assertEqualE(t, c.fn(fmt.Errorf("wrapped: %w", tc.err)), c.name == tc.expected && tc.err != nil, c.name+" wrapped")
+			}
+		})
+	}
+}
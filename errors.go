@@ -15,6 +15,8 @@ type SnowflakeError struct {
 	Message        string
 	MessageArgs    []interface{}
 	IncludeQueryID bool // TODO: populate this in connection
+
+	matches func(*SnowflakeError) bool // set for the error class sentinels, e.g. ErrSessionExpired
 }
 
 func (se *SnowflakeError) Error() string {
@@ -34,6 +36,20 @@ func (se *SnowflakeError) Error() string {
 	return fmt.Sprintf("%06d: %s", se.Number, message)
 }
 
+// Is makes errors.Is work with SnowflakeError. An error matches one of the error class
+// sentinels, e.g. ErrSessionExpired, if it belongs to that class. It matches another
+// SnowflakeError with the same Number, and the same SQLState if the target has one.
+func (se *SnowflakeError) Is(target error) bool {
+	t, ok := target.(*SnowflakeError)
+	if !ok || t == nil {
+		return false
+	}
+	if t.matches != nil {
+		return t.matches(se)
+	}
+	return t.Number != 0 && t.Number == se.Number && (t.SQLState == "" || t.SQLState == se.SQLState)
+}
+
 func (se *SnowflakeError) generateTelemetryExceptionData() *telemetryData {
 	data := &telemetryData{
 		Message: map[string]string{
@@ -148,6 +164,16 @@ const (
 	ErrCodeEmptyOAuthParameters = 260017
 	// ErrMissingAccessATokenButRefreshTokenPresent is an error code for the case when access token is not found in cache, but the refresh token is present.
 	ErrMissingAccessATokenButRefreshTokenPresent = 260018
+	// ErrCodeEmptyCustomAuthenticator is an error code for the case where the custom authenticator type is used without Config.CustomAuthenticator.
+	ErrCodeEmptyCustomAuthenticator = 260019
+	// ErrCodeUnknownPrivateKeySigner is an error code for the case where a private key signer is not registered under the name referenced by a DSN or connections.toml.
+	ErrCodeUnknownPrivateKeySigner = 260020
+	// ErrCodeInvalidPrivateKeyPassphrase is an error code for the case where an encrypted private key file cannot be decrypted because the passphrase is missing or wrong.
+	ErrCodeInvalidPrivateKeyPassphrase = 260021
+	// ErrCodeUnsupportedPrivateKeyFormat is an error code for the case where a private key file is in a format or encrypted with an algorithm the driver does not support.
+	ErrCodeUnsupportedPrivateKeyFormat = 260022
+	// ErrCodeInvalidStatementTimeout is an error code for the case where a statement timeout set with WithStatementTimeout is not positive.
+	ErrCodeInvalidStatementTimeout = 260023
 
 	/* network */
 
@@ -173,6 +199,8 @@ const (
 	ErrFailedToGetExternalBrowserResponse = 261009
 	// ErrFailedToHeartbeat is an error code when a heartbeat fails.
 	ErrFailedToHeartbeat = 261010
+	// ErrCircuitBreakerOpen is an error code for the case where a request is rejected because the circuit breaker is open.
+	ErrCircuitBreakerOpen = 261011
 
 	/* rows */
 
@@ -263,6 +291,8 @@ const (
 
 	// ErrQueryStatus when check the status of a query, receive error or no status
 	ErrQueryStatus = 279001
+	// ErrQueryNotCanceled the query was not canceled, e.g. because it was not running
+	ErrQueryNotCanceled = 279002
 	// ErrQueryIDFormat the query ID given to fetch its result is not valid
 	ErrQueryIDFormat = 279101
 	// ErrQueryReportedError server side reports the query failed with error
@@ -326,6 +356,9 @@ const (
 	errMsgFailedToFindDSNInTomlFile          = "failed to find DSN in toml file."
 	errMsgInvalidPermissionToTomlFile        = "file permissions different than read/write for user. Your Permission: %v"
 	errMsgNonArrowResponseInArrowBatches     = "arrow batches enabled, but the response is not Arrow based"
+	errMsgCircuitBreakerOpen                 = "circuit breaker is open for %v requests to %v"
+	errMsgQueryNotCanceled                   = "query %v was not canceled. status from server: [%v]"
+	errMsgInvalidStatementTimeout            = "statement timeout must be positive, got %v. use WithoutStatementTimeout to remove the statement timeout"
 )
 
 // Returned if a DNS doesn't include account parameter.
@@ -367,6 +400,73 @@ func errEmptyOAuthParameters() *SnowflakeError {
 	}
 }
 
+// Returned if the custom authenticator type is used but Config.CustomAuthenticator is not set.
+func errEmptyCustomAuthenticator() *SnowflakeError {
+	return &SnowflakeError{
+		Number:  ErrCodeEmptyCustomAuthenticator,
+		Message: "custom authenticator is empty",
+	}
+}
+
+// Returned if a DSN or connections.toml references a private key signer that is not registered.
+func errUnknownPrivateKeySigner(name string) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrCodeUnknownPrivateKeySigner,
+		Message:     "private key signer %v is not registered",
+		MessageArgs: []interface{}{name},
+	}
+}
+
+// Returned if a DSN is created for a private key signer that is not registered.
+func errUnregisteredPrivateKeySigner() *SnowflakeError {
+	return &SnowflakeError{
+		Number:  ErrCodeUnknownPrivateKeySigner,
+		Message: "private key signer must be registered with RegisterPrivateKeySigner to be referenced in a DSN",
+	}
+}
+
+// Returned if a private key file cannot be parsed.
+func errPrivateKeyParse(message string) *SnowflakeError {
+	return &SnowflakeError{
+		Number:  ErrCodePrivateKeyParseError,
+		Message: message,
+	}
+}
+
+// Returned if a private key file is encrypted, but no passphrase was provided.
+func errPrivateKeyPassphraseRequired() *SnowflakeError {
+	return &SnowflakeError{
+		Number:  ErrCodeInvalidPrivateKeyPassphrase,
+		Message: "private key file is encrypted, but no passphrase was provided with privateKeyFilePwd or privateKeyFilePwdEnv",
+	}
+}
+
+// Returned if the environment variable referenced by privateKeyFilePwdEnv is not set.
+func errPrivateKeyPassphraseEnvNotSet(name string) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrCodeInvalidPrivateKeyPassphrase,
+		Message:     "environment variable %v holding the passphrase of the private key file is not set",
+		MessageArgs: []interface{}{name},
+	}
+}
+
+// Returned if an encrypted private key file cannot be decrypted with the passphrase.
+func errInvalidPrivateKeyPassphrase() *SnowflakeError {
+	return &SnowflakeError{
+		Number:  ErrCodeInvalidPrivateKeyPassphrase,
+		Message: "failed to decrypt the private key file. the passphrase is wrong",
+	}
+}
+
+// Returned if a private key file is in a format the driver does not support.
+func errUnsupportedPrivateKeyFormat(message string, args ...interface{}) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrCodeUnsupportedPrivateKeyFormat,
+		Message:     message,
+		MessageArgs: args,
+	}
+}
+
 // Returned if a DSN's implicit region from account parameter and explicit region parameter conflict.
 func errRegionConflict() *SnowflakeError {
 	return &SnowflakeError{
@@ -414,3 +514,28 @@ func errNonArrowResponseForArrowBatches(queryID string) *SnowflakeError {
 		Message: errMsgNonArrowResponseInArrowBatches,
 	}
 }
+
+func errCircuitBreakerOpen(endpoint CircuitBreakerEndpoint, host string) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrCircuitBreakerOpen,
+		Message:     errMsgCircuitBreakerOpen,
+		MessageArgs: []interface{}{endpoint, host},
+	}
+}
+
+func errQueryNotCanceled(queryID string, status string) *SnowflakeError {
+	return &SnowflakeError{
+		QueryID:     queryID,
+		Number:      ErrQueryNotCanceled,
+		Message:     errMsgQueryNotCanceled,
+		MessageArgs: []interface{}{queryID, status},
+	}
+}
+
+func errInvalidStatementTimeout(timeout time.Duration) *SnowflakeError {
+	return &SnowflakeError{
+		Number:      ErrCodeInvalidStatementTimeout,
+		Message:     errMsgInvalidStatementTimeout,
+		MessageArgs: []interface{}{timeout},
+	}
+}
@@ -856,7 +856,17 @@ func (sfa *snowflakeFileTransferAgent) uploadFilesSequential(fileMetas []*fileMe
 	return nil
 }
 
-func (sfa *snowflakeFileTransferAgent) uploadOneFile(meta *fileMetadata) (*fileMetadata, error) {
+func (sfa *snowflakeFileTransferAgent) uploadOneFile(meta *fileMetadata) (_ *fileMetadata, err error) {
+	_, span := startSpan(sfa.ctx, sfa.sc.cfg, fileUploadSpanName,
+		fileNameAttribute.String(meta.name),
+		fileSizeAttribute.Int64(meta.srcFileSize),
+		stageTypeAttribute.String(string(sfa.stageLocationType)))
+	start := time.Now()
+	defer func() {
+		getMetrics(sfa.sc.cfg).recordFileTransfer(sfa.ctx, "upload", meta.resStatus, meta.uploadSize, time.Since(start), err)
+		endSpan(span, err)
+	}()
+
 	meta.realSrcFileName = meta.srcFileName
 	tmpDir, err := os.MkdirTemp(sfa.sc.cfg.TmpDirPath, "")
 	if err != nil {
@@ -965,7 +975,16 @@ func (sfa *snowflakeFileTransferAgent) downloadFilesParallel(fileMetas []*fileMe
 	return err
 }
 
-func (sfa *snowflakeFileTransferAgent) downloadOneFile(meta *fileMetadata) (*fileMetadata, error) {
+func (sfa *snowflakeFileTransferAgent) downloadOneFile(meta *fileMetadata) (_ *fileMetadata, err error) {
+	_, span := startSpan(sfa.ctx, sfa.sc.cfg, fileDownloadSpanName,
+		fileNameAttribute.String(meta.name),
+		stageTypeAttribute.String(string(sfa.stageLocationType)))
+	start := time.Now()
+	defer func() {
+		getMetrics(sfa.sc.cfg).recordFileTransfer(sfa.ctx, "download", meta.resStatus, meta.dstFileSize, time.Since(start), err)
+		endSpan(span, err)
+	}()
+
 	tmpDir, err := os.MkdirTemp(sfa.sc.cfg.TmpDirPath, "")
 	if err != nil {
 		return nil, err
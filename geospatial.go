@@ -0,0 +1,780 @@
+package gosnowflake
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// GeoShape is a decoded GEOGRAPHY or GEOMETRY object. It is one of GeoPoint, GeoLineString,
+// GeoPolygon, GeoMultiPoint, GeoMultiLineString, GeoMultiPolygon or GeoCollection.
+type GeoShape interface {
+	// GeoType returns the GeoJSON type of the shape, e.g. "Point".
+	GeoType() string
+}
+
+// GeoPoint holds the X and Y coordinates of a position, followed by Z for three-dimensional
+// shapes. For GEOGRAPHY, X is the longitude and Y the latitude. An empty point has no coordinates.
+type GeoPoint []float64
+
+// GeoLineString is a line connecting its points.
+type GeoLineString []GeoPoint
+
+// GeoPolygon is a list of linear rings, the exterior ring followed by the holes.
+type GeoPolygon []GeoLineString
+
+// GeoMultiPoint is a collection of points.
+type GeoMultiPoint []GeoPoint
+
+// GeoMultiLineString is a collection of line strings.
+type GeoMultiLineString []GeoLineString
+
+// GeoMultiPolygon is a collection of polygons.
+type GeoMultiPolygon []GeoPolygon
+
+// GeoCollection is a collection of shapes of any type.
+type GeoCollection []GeoShape
+
+// GeoType returns "Point".
+func (GeoPoint) GeoType() string { return "Point" }
+
+// GeoType returns "LineString".
+func (GeoLineString) GeoType() string { return "LineString" }
+
+// GeoType returns "Polygon".
+func (GeoPolygon) GeoType() string { return "Polygon" }
+
+// GeoType returns "MultiPoint".
+func (GeoMultiPoint) GeoType() string { return "MultiPoint" }
+
+// GeoType returns "MultiLineString".
+func (GeoMultiLineString) GeoType() string { return "MultiLineString" }
+
+// GeoType returns "MultiPolygon".
+func (GeoMultiPolygon) GeoType() string { return "MultiPolygon" }
+
+// GeoType returns "GeometryCollection".
+func (GeoCollection) GeoType() string { return "GeometryCollection" }
+
+// Geography is a value of a GEOGRAPHY column. A Geography with a nil Shape is SQL NULL.
+type Geography struct {
+	Shape GeoShape
+	// SRID is the spatial reference system of EWKT and EWKB values, 0 if the value had none.
+	SRID int
+}
+
+// Scan implements sql.Scanner. It decodes GeoJSON, WKT, EWKT, WKB and EWKB values, so it
+// works with any GEOGRAPHY_OUTPUT_FORMAT.
+func (g *Geography) Scan(src interface{}) error {
+	shape, srid, err := scanGeoValue(src)
+	if err != nil {
+		return err
+	}
+	g.Shape, g.SRID = shape, srid
+	return nil
+}
+
+// Value implements driver.Valuer. The shape is bound as WKT, or EWKT if SRID is set.
+func (g Geography) Value() (driver.Value, error) {
+	return geoValue(g.Shape, g.SRID), nil
+}
+
+// String returns the shape as WKT, or EWKT if SRID is set.
+func (g Geography) String() string {
+	return geoString(g.Shape, g.SRID)
+}
+
+// Geometry is a value of a GEOMETRY column. A Geometry with a nil Shape is SQL NULL.
+type Geometry struct {
+	Shape GeoShape
+	// SRID is the spatial reference system of EWKT and EWKB values, 0 if the value had none.
+	SRID int
+}
+
+// Scan implements sql.Scanner. It decodes GeoJSON, WKT, EWKT, WKB and EWKB values, so it
+// works with any GEOMETRY_OUTPUT_FORMAT.
+func (g *Geometry) Scan(src interface{}) error {
+	shape, srid, err := scanGeoValue(src)
+	if err != nil {
+		return err
+	}
+	g.Shape, g.SRID = shape, srid
+	return nil
+}
+
+// Value implements driver.Valuer. The shape is bound as WKT, or EWKT if SRID is set.
+func (g Geometry) Value() (driver.Value, error) {
+	return geoValue(g.Shape, g.SRID), nil
+}
+
+// String returns the shape as WKT, or EWKT if SRID is set.
+func (g Geometry) String() string {
+	return geoString(g.Shape, g.SRID)
+}
+
+func scanGeoValue(src interface{}) (GeoShape, int, error) {
+	switch v := src.(type) {
+	case nil:
+		return nil, 0, nil
+	case string:
+		return parseGeoText(v)
+	case []byte:
+		// WKB returned as BINARY in Arrow results
+		if len(v) > 0 && (v[0] == 0 || v[0] == 1) || !utf8.Valid(v) {
+			return parseWKB(v)
+		}
+		return parseGeoText(string(v))
+	}
+	return nil, 0, fmt.Errorf("cannot convert %T to a geospatial value", src)
+}
+
+// parseGeoText decodes a geospatial value returned as text in any output format.
+func parseGeoText(s string) (GeoShape, int, error) {
+	trimmed := strings.TrimSpace(s)
+	if strings.HasPrefix(trimmed, "{") {
+		shape, err := parseGeoJSON([]byte(trimmed))
+		return shape, 0, err
+	}
+	if isHexString(trimmed) {
+		b, err := hex.DecodeString(trimmed)
+		if err != nil {
+			return nil, 0, err
+		}
+		return parseWKB(b)
+	}
+	return parseWKT(trimmed)
+}
+
+func isHexString(s string) bool {
+	if s == "" || len(s)%2 != 0 {
+		return false
+	}
+	for _, c := range s {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+func geoValue(shape GeoShape, srid int) driver.Value {
+	if shape == nil {
+		return nil
+	}
+	return geoString(shape, srid)
+}
+
+func geoString(shape GeoShape, srid int) string {
+	if shape == nil {
+		return ""
+	}
+	var sb strings.Builder
+	if srid != 0 {
+		fmt.Fprintf(&sb, "SRID=%d;", srid)
+	}
+	writeWKT(&sb, shape)
+	return sb.String()
+}
+
+type geoJSONObject struct {
+	Type        string            `json:"type"`
+	Coordinates json.RawMessage   `json:"coordinates"`
+	Geometries  []json.RawMessage `json:"geometries"`
+}
+
+func parseGeoJSON(data []byte) (GeoShape, error) {
+	var obj geoJSONObject
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, fmt.Errorf("invalid GeoJSON: %w", err)
+	}
+	var shape GeoShape
+	switch obj.Type {
+	case "Point":
+		shape = &GeoPoint{}
+	case "LineString":
+		shape = &GeoLineString{}
+	case "Polygon":
+		shape = &GeoPolygon{}
+	case "MultiPoint":
+		shape = &GeoMultiPoint{}
+	case "MultiLineString":
+		shape = &GeoMultiLineString{}
+	case "MultiPolygon":
+		shape = &GeoMultiPolygon{}
+	case "GeometryCollection":
+		collection := make(GeoCollection, 0, len(obj.Geometries))
+		for _, geometry := range obj.Geometries {
+			member, err := parseGeoJSON(geometry)
+			if err != nil {
+				return nil, err
+			}
+			collection = append(collection, member)
+		}
+		return collection, nil
+	default:
+		return nil, fmt.Errorf("unsupported GeoJSON type: %q", obj.Type)
+	}
+	if len(obj.Coordinates) > 0 {
+		if err := json.Unmarshal(obj.Coordinates, shape); err != nil {
+			return nil, fmt.Errorf("invalid GeoJSON coordinates of %v: %w", obj.Type, err)
+		}
+	}
+	switch s := shape.(type) {
+	case *GeoPoint:
+		return *s, nil
+	case *GeoLineString:
+		return *s, nil
+	case *GeoPolygon:
+		return *s, nil
+	case *GeoMultiPoint:
+		return *s, nil
+	case *GeoMultiLineString:
+		return *s, nil
+	case *GeoMultiPolygon:
+		return *s, nil
+	}
+	return nil, fmt.Errorf("unsupported GeoJSON type: %q", obj.Type)
+}
+
+// wktParser decodes WKT and EWKT, e.g. "SRID=4326;POINT Z(-122.35 37.55 10)".
+type wktParser struct {
+	s   string
+	pos int
+}
+
+func parseWKT(s string) (GeoShape, int, error) {
+	p := &wktParser{s: s}
+	srid := 0
+	if len(s) > 5 && strings.EqualFold(s[:5], "SRID=") {
+		end := strings.IndexByte(s, ';')
+		if end < 0 {
+			return nil, 0, fmt.Errorf("invalid EWKT: %q", s)
+		}
+		var err error
+		if srid, err = strconv.Atoi(strings.TrimSpace(s[5:end])); err != nil {
+			return nil, 0, fmt.Errorf("invalid EWKT SRID: %q", s)
+		}
+		p.pos = end + 1
+	}
+	shape, err := p.shape()
+	if err != nil {
+		return nil, 0, err
+	}
+	if p.skipSpaces(); p.pos != len(p.s) {
+		return nil, 0, p.errorf("unexpected trailing characters")
+	}
+	return shape, srid, nil
+}
+
+func (p *wktParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid WKT at position %v: %v", p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *wktParser) skipSpaces() {
+	for p.pos < len(p.s) && strings.IndexByte(" \t\r\n", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// word reads the next keyword in upper case, or returns "" if there is none.
+func (p *wktParser) word() string {
+	p.skipSpaces()
+	start := p.pos
+	for p.pos < len(p.s) && ('a' <= p.s[p.pos]|0x20 && p.s[p.pos]|0x20 <= 'z') {
+		p.pos++
+	}
+	return strings.ToUpper(p.s[start:p.pos])
+}
+
+// accept consumes c if it is the next character.
+func (p *wktParser) accept(c byte) bool {
+	p.skipSpaces()
+	if p.pos < len(p.s) && p.s[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *wktParser) expect(c byte) error {
+	if !p.accept(c) {
+		return p.errorf("expected %q", c)
+	}
+	return nil
+}
+
+func (p *wktParser) shape() (GeoShape, error) {
+	typ := p.word()
+	start := p.pos
+	switch dims := p.word(); dims {
+	case "Z":
+	case "M", "ZM":
+		return nil, p.errorf("%v coordinates are not supported", dims)
+	default:
+		// EMPTY or no dimensions
+		p.pos = start
+	}
+	start = p.pos
+	empty := p.word() == "EMPTY"
+	if !empty {
+		p.pos = start
+	}
+	switch typ {
+	case "POINT":
+		if empty {
+			return GeoPoint{}, nil
+		}
+		if err := p.expect('('); err != nil {
+			return nil, err
+		}
+		point, err := p.point()
+		if err != nil {
+			return nil, err
+		}
+		return point, p.expect(')')
+	case "LINESTRING":
+		if empty {
+			return GeoLineString{}, nil
+		}
+		return p.points()
+	case "POLYGON":
+		if empty {
+			return GeoPolygon{}, nil
+		}
+		return p.rings()
+	case "MULTIPOINT":
+		if empty {
+			return GeoMultiPoint{}, nil
+		}
+		// the points may or may not be enclosed in parentheses
+		var multiPoint GeoMultiPoint
+		err := p.list(func() error {
+			enclosed := p.accept('(')
+			point, err := p.point()
+			if err != nil {
+				return err
+			}
+			if enclosed {
+				if err = p.expect(')'); err != nil {
+					return err
+				}
+			}
+			multiPoint = append(multiPoint, point)
+			return nil
+		})
+		return multiPoint, err
+	case "MULTILINESTRING":
+		if empty {
+			return GeoMultiLineString{}, nil
+		}
+		var multiLineString GeoMultiLineString
+		err := p.list(func() error {
+			lineString, err := p.points()
+			multiLineString = append(multiLineString, lineString)
+			return err
+		})
+		return multiLineString, err
+	case "MULTIPOLYGON":
+		if empty {
+			return GeoMultiPolygon{}, nil
+		}
+		var multiPolygon GeoMultiPolygon
+		err := p.list(func() error {
+			polygon, err := p.rings()
+			multiPolygon = append(multiPolygon, polygon)
+			return err
+		})
+		return multiPolygon, err
+	case "GEOMETRYCOLLECTION":
+		if empty {
+			return GeoCollection{}, nil
+		}
+		var collection GeoCollection
+		err := p.list(func() error {
+			member, err := p.shape()
+			collection = append(collection, member)
+			return err
+		})
+		return collection, err
+	}
+	return nil, p.errorf("unsupported type %q", typ)
+}
+
+// list reads a parenthesized, comma separated list of elements.
+func (p *wktParser) list(element func() error) error {
+	if err := p.expect('('); err != nil {
+		return err
+	}
+	for {
+		if err := element(); err != nil {
+			return err
+		}
+		if !p.accept(',') {
+			return p.expect(')')
+		}
+	}
+}
+
+func (p *wktParser) points() (GeoLineString, error) {
+	lineString := GeoLineString{}
+	err := p.list(func() error {
+		point, err := p.point()
+		lineString = append(lineString, point)
+		return err
+	})
+	return lineString, err
+}
+
+func (p *wktParser) rings() (GeoPolygon, error) {
+	polygon := GeoPolygon{}
+	err := p.list(func() error {
+		ring, err := p.points()
+		polygon = append(polygon, ring)
+		return err
+	})
+	return polygon, err
+}
+
+func (p *wktParser) point() (GeoPoint, error) {
+	var point GeoPoint
+	for {
+		p.skipSpaces()
+		start := p.pos
+		for p.pos < len(p.s) && strings.IndexByte("+-.0123456789eE", p.s[p.pos]) >= 0 {
+			p.pos++
+		}
+		if start == p.pos {
+			break
+		}
+		v, err := strconv.ParseFloat(p.s[start:p.pos], 64)
+		if err != nil {
+			p.pos = start
+			return nil, p.errorf("invalid coordinate")
+		}
+		point = append(point, v)
+	}
+	if len(point) != 2 && len(point) != 3 {
+		return nil, p.errorf("expected 2 or 3 coordinates, got %v", len(point))
+	}
+	return point, nil
+}
+
+func writeWKT(sb *strings.Builder, shape GeoShape) {
+	sb.WriteString(strings.ToUpper(shape.GeoType()))
+	if first := firstGeoPoint(shape); len(first) == 3 {
+		sb.WriteString(" Z")
+	}
+	switch s := shape.(type) {
+	case GeoPoint:
+		if len(s) == 0 {
+			sb.WriteString(" EMPTY")
+			return
+		}
+		sb.WriteByte('(')
+		writeWKTPoint(sb, s)
+		sb.WriteByte(')')
+	case GeoLineString:
+		writeWKTList(sb, len(s), func(i int) { writeWKTPoint(sb, s[i]) })
+	case GeoMultiPoint:
+		writeWKTList(sb, len(s), func(i int) { writeWKTPoint(sb, s[i]) })
+	case GeoPolygon:
+		writeWKTList(sb, len(s), func(i int) { writeWKTPoints(sb, s[i]) })
+	case GeoMultiLineString:
+		writeWKTList(sb, len(s), func(i int) { writeWKTPoints(sb, s[i]) })
+	case GeoMultiPolygon:
+		writeWKTList(sb, len(s), func(i int) {
+			writeWKTList(sb, len(s[i]), func(j int) { writeWKTPoints(sb, s[i][j]) })
+		})
+	case GeoCollection:
+		writeWKTList(sb, len(s), func(i int) { writeWKT(sb, s[i]) })
+	}
+}
+
+func writeWKTList(sb *strings.Builder, n int, element func(i int)) {
+	if n == 0 {
+		sb.WriteString(" EMPTY")
+		return
+	}
+	sb.WriteByte('(')
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		element(i)
+	}
+	sb.WriteByte(')')
+}
+
+func writeWKTPoints(sb *strings.Builder, points GeoLineString) {
+	writeWKTList(sb, len(points), func(i int) { writeWKTPoint(sb, points[i]) })
+}
+
+func writeWKTPoint(sb *strings.Builder, point GeoPoint) {
+	for i, v := range point {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(strconv.FormatFloat(v, 'f', -1, 64))
+	}
+}
+
+// firstGeoPoint returns the first point of a shape other than a collection, or nil if it is empty.
+func firstGeoPoint(shape GeoShape) GeoPoint {
+	switch s := shape.(type) {
+	case GeoPoint:
+		return s
+	case GeoLineString:
+		if len(s) > 0 {
+			return s[0]
+		}
+	case GeoMultiPoint:
+		if len(s) > 0 {
+			return s[0]
+		}
+	case GeoPolygon:
+		if len(s) > 0 {
+			return firstGeoPoint(s[0])
+		}
+	case GeoMultiLineString:
+		if len(s) > 0 {
+			return firstGeoPoint(s[0])
+		}
+	case GeoMultiPolygon:
+		if len(s) > 0 {
+			return firstGeoPoint(s[0])
+		}
+	}
+	return nil
+}
+
+const (
+	wkbPoint = iota + 1
+	wkbLineString
+	wkbPolygon
+	wkbMultiPoint
+	wkbMultiLineString
+	wkbMultiPolygon
+	wkbGeometryCollection
+)
+
+const (
+	ewkbFlagZ    = 0x80000000
+	ewkbFlagM    = 0x40000000
+	ewkbFlagSRID = 0x20000000
+)
+
+// wkbReader decodes WKB, including the ISO Z types and the EWKB extensions of PostGIS.
+type wkbReader struct {
+	b     []byte
+	order binary.ByteOrder
+}
+
+func parseWKB(b []byte) (GeoShape, int, error) {
+	r := &wkbReader{b: b}
+	shape, srid, err := r.shape()
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(r.b) != 0 {
+		return nil, 0, fmt.Errorf("invalid WKB: %v trailing bytes", len(r.b))
+	}
+	return shape, srid, nil
+}
+
+func (r *wkbReader) uint32() (uint32, error) {
+	if len(r.b) < 4 {
+		return 0, fmt.Errorf("invalid WKB: unexpected end of data")
+	}
+	v := r.order.Uint32(r.b)
+	r.b = r.b[4:]
+	return v, nil
+}
+
+// count reads the number of elements that follow, each taking at least minSize bytes.
+func (r *wkbReader) count(minSize int) (int, error) {
+	n, err := r.uint32()
+	if err != nil {
+		return 0, err
+	}
+	if uint64(n)*uint64(minSize) > uint64(len(r.b)) {
+		return 0, fmt.Errorf("invalid WKB: %v elements exceed the data", n)
+	}
+	return int(n), nil
+}
+
+func (r *wkbReader) header() (typ uint32, dims int, srid int, err error) {
+	if len(r.b) < 1 {
+		return 0, 0, 0, fmt.Errorf("invalid WKB: unexpected end of data")
+	}
+	switch r.b[0] {
+	case 0:
+		r.order = binary.BigEndian
+	case 1:
+		r.order = binary.LittleEndian
+	default:
+		return 0, 0, 0, fmt.Errorf("invalid WKB byte order: %v", r.b[0])
+	}
+	r.b = r.b[1:]
+	if typ, err = r.uint32(); err != nil {
+		return 0, 0, 0, err
+	}
+	dims = 2
+	if typ&ewkbFlagSRID != 0 {
+		s, err := r.uint32()
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		srid = int(int32(s))
+	}
+	if typ&ewkbFlagZ != 0 {
+		dims = 3
+	}
+	if typ&ewkbFlagM != 0 {
+		return 0, 0, 0, fmt.Errorf("invalid WKB: M coordinates are not supported")
+	}
+	typ &^= ewkbFlagZ | ewkbFlagM | ewkbFlagSRID
+	switch typ / 1000 {
+	case 0:
+	case 1:
+		dims = 3
+	default:
+		return 0, 0, 0, fmt.Errorf("invalid WKB: M coordinates are not supported")
+	}
+	return typ % 1000, dims, srid, nil
+}
+
+func (r *wkbReader) shape() (GeoShape, int, error) {
+	typ, dims, srid, err := r.header()
+	if err != nil {
+		return nil, 0, err
+	}
+	var shape GeoShape
+	switch typ {
+	case wkbPoint:
+		point, err := r.point(dims)
+		if err != nil {
+			return nil, 0, err
+		}
+		if isEmptyWKBPoint(point) {
+			point = GeoPoint{}
+		}
+		shape = point
+	case wkbLineString:
+		shape, err = r.points(dims)
+	case wkbPolygon:
+		shape, err = r.rings(dims)
+	case wkbMultiPoint, wkbMultiLineString, wkbMultiPolygon, wkbGeometryCollection:
+		shape, err = r.collection(typ)
+	default:
+		return nil, 0, fmt.Errorf("invalid WKB: unsupported type %v", typ)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	return shape, srid, nil
+}
+
+// collection reads the members of a multi shape or a geometry collection. Each member
+// has its own header.
+func (r *wkbReader) collection(typ uint32) (GeoShape, error) {
+	n, err := r.count(5)
+	if err != nil {
+		return nil, err
+	}
+	var multiPoint GeoMultiPoint
+	var multiLineString GeoMultiLineString
+	var multiPolygon GeoMultiPolygon
+	var collection GeoCollection
+	for i := 0; i < n; i++ {
+		member, _, err := r.shape()
+		if err != nil {
+			return nil, err
+		}
+		var ok bool
+		switch typ {
+		case wkbMultiPoint:
+			var point GeoPoint
+			point, ok = member.(GeoPoint)
+			multiPoint = append(multiPoint, point)
+		case wkbMultiLineString:
+			var lineString GeoLineString
+			lineString, ok = member.(GeoLineString)
+			multiLineString = append(multiLineString, lineString)
+		case wkbMultiPolygon:
+			var polygon GeoPolygon
+			polygon, ok = member.(GeoPolygon)
+			multiPolygon = append(multiPolygon, polygon)
+		default:
+			ok = true
+			collection = append(collection, member)
+		}
+		if !ok {
+			return nil, fmt.Errorf("invalid WKB: unexpected %v in a multi shape", member.GeoType())
+		}
+	}
+	switch typ {
+	case wkbMultiPoint:
+		return append(GeoMultiPoint{}, multiPoint...), nil
+	case wkbMultiLineString:
+		return append(GeoMultiLineString{}, multiLineString...), nil
+	case wkbMultiPolygon:
+		return append(GeoMultiPolygon{}, multiPolygon...), nil
+	}
+	return append(GeoCollection{}, collection...), nil
+}
+
+func (r *wkbReader) rings(dims int) (GeoPolygon, error) {
+	n, err := r.count(4)
+	if err != nil {
+		return nil, err
+	}
+	polygon := make(GeoPolygon, n)
+	for i := range polygon {
+		if polygon[i], err = r.points(dims); err != nil {
+			return nil, err
+		}
+	}
+	return polygon, nil
+}
+
+func (r *wkbReader) points(dims int) (GeoLineString, error) {
+	n, err := r.count(8 * dims)
+	if err != nil {
+		return nil, err
+	}
+	lineString := make(GeoLineString, n)
+	for i := range lineString {
+		if lineString[i], err = r.point(dims); err != nil {
+			return nil, err
+		}
+	}
+	return lineString, nil
+}
+
+func (r *wkbReader) point(dims int) (GeoPoint, error) {
+	if len(r.b) < 8*dims {
+		return nil, fmt.Errorf("invalid WKB: unexpected end of data")
+	}
+	point := make(GeoPoint, dims)
+	for i := range point {
+		point[i] = math.Float64frombits(r.order.Uint64(r.b[8*i:]))
+	}
+	r.b = r.b[8*dims:]
+	return point, nil
+}
+
+// isEmptyWKBPoint reports whether the point is POINT EMPTY, which WKB encodes with NaN coordinates.
+func isEmptyWKBPoint(point GeoPoint) bool {
+	for _, v := range point {
+		if !math.IsNaN(v) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,334 @@
+package gosnowflake
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+)
+
+const (
+	pointGeoJSON = `{"coordinates": [-122.35, 37.55], "type": "Point"}`
+	pointWKT     = "POINT(-122.35 37.55)"
+	pointWKBHex  = "01010000006666666666965ec06666666666c64240"
+	pointEWKBHex = "0101000020e61000006666666666965ec06666666666c64240"
+)
+
+var testPoint = GeoPoint{-122.35, 37.55}
+
+// wkbTestWriter builds little endian WKB for the tests.
+type wkbTestWriter struct {
+	bytes.Buffer
+}
+
+func (w *wkbTestWriter) header(typ uint32) *wkbTestWriter {
+	w.WriteByte(1)
+	_ = binary.Write(w, binary.LittleEndian, typ)
+	return w
+}
+
+func (w *wkbTestWriter) uint32(v uint32) *wkbTestWriter {
+	_ = binary.Write(w, binary.LittleEndian, v)
+	return w
+}
+
+func (w *wkbTestWriter) floats(values ...float64) *wkbTestWriter {
+	for _, v := range values {
+		_ = binary.Write(w, binary.LittleEndian, v)
+	}
+	return w
+}
+
+func TestGeospatialScan(t *testing.T) {
+	pointWKB, err := hex.DecodeString(pointWKBHex)
+	assertNilF(t, err)
+	testcases := []struct {
+		name string
+		src  interface{}
+		srid int
+	}{
+		{name: "GeoJSON", src: pointGeoJSON},
+		{name: "WKT", src: pointWKT},
+		{name: "EWKT", src: "SRID=4326;" + pointWKT, srid: 4326},
+		{name: "WKB hex", src: pointWKBHex},
+		{name: "EWKB hex", src: pointEWKBHex, srid: 4326},
+		{name: "WKB binary", src: pointWKB},
+		{name: "WKT bytes", src: []byte(pointWKT)},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var g Geography
+			assertNilF(t, g.Scan(tc.src))
+			assertDeepEqualE(t, g.Shape, GeoShape(testPoint))
+			assertEqualE(t, g.SRID, tc.srid)
+
+			var m Geometry
+			assertNilF(t, m.Scan(tc.src))
+			assertDeepEqualE(t, m.Shape, GeoShape(testPoint))
+			assertEqualE(t, m.SRID, tc.srid)
+		})
+	}
+
+	t.Run("NULL", func(t *testing.T) {
+		g := Geography{Shape: testPoint, SRID: 4326}
+		assertNilF(t, g.Scan(nil))
+		assertNilE(t, g.Shape)
+		v, err := g.Value()
+		assertNilF(t, err)
+		assertNilE(t, v)
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		var g Geography
+		assertNotNilE(t, g.Scan(int64(1)))
+	})
+}
+
+func TestGeospatialShapes(t *testing.T) {
+	square := GeoLineString{{0, 0}, {4, 0}, {4, 4}, {0, 4}, {0, 0}}
+	hole := GeoLineString{{1, 1}, {2, 1}, {2, 2}, {1, 1}}
+	testcases := []struct {
+		shape   GeoShape
+		wkt     string
+		geoJSON string
+	}{
+		{
+			shape:   GeoPoint{1.5, -2, 3},
+			wkt:     "POINT Z(1.5 -2 3)",
+			geoJSON: `{"type":"Point","coordinates":[1.5,-2,3]}`,
+		},
+		{
+			shape:   GeoPoint{},
+			wkt:     "POINT EMPTY",
+			geoJSON: `{"type":"Point","coordinates":[]}`,
+		},
+		{
+			shape:   GeoLineString{{1, 2}, {3, 4}},
+			wkt:     "LINESTRING(1 2,3 4)",
+			geoJSON: `{"type":"LineString","coordinates":[[1,2],[3,4]]}`,
+		},
+		{
+			shape:   GeoPolygon{square, hole},
+			wkt:     "POLYGON((0 0,4 0,4 4,0 4,0 0),(1 1,2 1,2 2,1 1))",
+			geoJSON: `{"type":"Polygon","coordinates":[[[0,0],[4,0],[4,4],[0,4],[0,0]],[[1,1],[2,1],[2,2],[1,1]]]}`,
+		},
+		{
+			shape:   GeoMultiPoint{{1, 2}, {3, 4}},
+			wkt:     "MULTIPOINT(1 2,3 4)",
+			geoJSON: `{"type":"MultiPoint","coordinates":[[1,2],[3,4]]}`,
+		},
+		{
+			shape:   GeoMultiLineString{{{1, 2}, {3, 4}}, {{5, 6}, {7, 8}}},
+			wkt:     "MULTILINESTRING((1 2,3 4),(5 6,7 8))",
+			geoJSON: `{"type":"MultiLineString","coordinates":[[[1,2],[3,4]],[[5,6],[7,8]]]}`,
+		},
+		{
+			shape:   GeoMultiPolygon{{square}, {hole}},
+			wkt:     "MULTIPOLYGON(((0 0,4 0,4 4,0 4,0 0)),((1 1,2 1,2 2,1 1)))",
+			geoJSON: `{"type":"MultiPolygon","coordinates":[[[[0,0],[4,0],[4,4],[0,4],[0,0]]],[[[1,1],[2,1],[2,2],[1,1]]]]}`,
+		},
+		{
+			shape:   GeoCollection{GeoPoint{1, 2}, GeoLineString{{1, 2}, {3, 4}}},
+			wkt:     "GEOMETRYCOLLECTION(POINT(1 2),LINESTRING(1 2,3 4))",
+			geoJSON: `{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2]},{"type":"LineString","coordinates":[[1,2],[3,4]]}]}`,
+		},
+		{
+			shape:   GeoCollection{},
+			wkt:     "GEOMETRYCOLLECTION EMPTY",
+			geoJSON: `{"type":"GeometryCollection","geometries":[]}`,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.wkt, func(t *testing.T) {
+			g := Geometry{Shape: tc.shape}
+			assertEqualE(t, g.String(), tc.wkt)
+
+			var fromWKT, fromGeoJSON Geometry
+			assertNilF(t, fromWKT.Scan(tc.wkt))
+			assertDeepEqualE(t, fromWKT.Shape, tc.shape)
+			assertNilF(t, fromGeoJSON.Scan(tc.geoJSON))
+			assertDeepEqualE(t, fromGeoJSON.Shape, tc.shape)
+		})
+	}
+
+	t.Run("WKT variants", func(t *testing.T) {
+		var g Geometry
+		assertNilF(t, g.Scan(" multipoint ((1 2), (3 4)) "))
+		assertDeepEqualE(t, g.Shape, GeoShape(GeoMultiPoint{{1, 2}, {3, 4}}))
+		assertNilF(t, g.Scan("SRID=3857;LINESTRING Z (1 2 3, 4 5 6)"))
+		assertDeepEqualE(t, g.Shape, GeoShape(GeoLineString{{1, 2, 3}, {4, 5, 6}}))
+		assertEqualE(t, g.SRID, 3857)
+		assertEqualE(t, g.String(), "SRID=3857;LINESTRING Z(1 2 3,4 5 6)")
+		v, err := g.Value()
+		assertNilF(t, err)
+		assertEqualE(t, v, "SRID=3857;LINESTRING Z(1 2 3,4 5 6)")
+	})
+
+	t.Run("WKB", func(t *testing.T) {
+		w := &wkbTestWriter{}
+		w.header(wkbMultiPolygon).uint32(1)
+		w.header(wkbPolygon).uint32(1).uint32(4).floats(0, 0, 1, 0, 1, 1, 0, 0)
+		var g Geometry
+		assertNilF(t, g.Scan(w.Bytes()))
+		assertDeepEqualE(t, g.Shape, GeoShape(GeoMultiPolygon{{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}}))
+
+		// ISO WKB with Z coordinates
+		w = &wkbTestWriter{}
+		w.header(1000+wkbLineString).uint32(2).floats(1, 2, 3, 4, 5, 6)
+		assertNilF(t, g.Scan(hex.EncodeToString(w.Bytes())))
+		assertDeepEqualE(t, g.Shape, GeoShape(GeoLineString{{1, 2, 3}, {4, 5, 6}}))
+
+		// EWKB with SRID and Z coordinates in a collection
+		w = &wkbTestWriter{}
+		w.header(ewkbFlagSRID | wkbGeometryCollection).uint32(4326).uint32(2)
+		w.header(ewkbFlagZ|wkbPoint).floats(1, 2, 3)
+		w.header(wkbPoint).floats(math.NaN(), math.NaN())
+		assertNilF(t, g.Scan(w.Bytes()))
+		assertDeepEqualE(t, g.Shape, GeoShape(GeoCollection{GeoPoint{1, 2, 3}, GeoPoint{}}))
+		assertEqualE(t, g.SRID, 4326)
+
+		// big endian
+		b, err := hex.DecodeString("000000000140000000000000004010000000000000")
+		assertNilF(t, err)
+		assertNilF(t, g.Scan(b))
+		assertDeepEqualE(t, g.Shape, GeoShape(GeoPoint{2, 4}))
+	})
+}
+
+func TestGeospatialScanInvalid(t *testing.T) {
+	pointWKB, err := hex.DecodeString(pointWKBHex)
+	assertNilF(t, err)
+	for name, src := range map[string]interface{}{
+		"truncated WKB":       pointWKB[:len(pointWKB)-1],
+		"trailing WKB":        append(append([]byte{}, pointWKB...), 0),
+		"WKB count too large": (&wkbTestWriter{}).header(wkbLineString).uint32(math.MaxUint32).floats(1, 2).Bytes(),
+		"WKB M coordinates":   (&wkbTestWriter{}).header(2000+wkbPoint).floats(1, 2, 3).Bytes(),
+		"WKB multi member":    (&wkbTestWriter{}).header(wkbMultiPoint).uint32(1).header(wkbLineString).uint32(0).Bytes(),
+		"WKB type":            (&wkbTestWriter{}).header(17).Bytes(),
+		"WKT type":            "CIRCLE(1 2)",
+		"WKT coordinates":     "POINT(1)",
+		"WKT M coordinates":   "POINT M(1 2 3)",
+		"WKT parentheses":     "LINESTRING(1 2,3 4",
+		"WKT trailing":        "POINT(1 2))",
+		"EWKT SRID":           "SRID=x;POINT(1 2)",
+		"GeoJSON":             `{"type":"Point","coordinates":"1 2"}`,
+		"GeoJSON type":        `{"type":"Feature"}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var g Geography
+			assertNotNilE(t, g.Scan(src))
+		})
+	}
+}
+
+func TestGeospatialConversion(t *testing.T) {
+	// the driver returns the value in the output format, so the scan type is unchanged
+	assertEqualE(t, snowflakeTypeToGo(context.Background(), geographyType, 0, 0, nil), reflect.TypeOf(""))
+	assertEqualE(t, snowflakeTypeToGo(context.Background(), geometryType, 0, 0, nil), reflect.TypeOf(""))
+
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+	sb := array.NewStringBuilder(pool)
+	defer sb.Release()
+	sb.Append(pointWKT)
+	sb.AppendNull()
+	arr := sb.NewArray()
+	defer arr.Release()
+
+	dest := make([]snowflakeValue, 2)
+	assertNilF(t, arrowToValues(context.Background(), dest, execResponseRowType{Type: "geography"}, arr, nil, false, nil))
+	assertEqualE(t, dest[0], pointWKT)
+	assertNilE(t, dest[1])
+	var g Geography
+	assertNilF(t, g.Scan(dest[0]))
+	assertDeepEqualE(t, g.Shape, GeoShape(testPoint))
+}
+
+func TestArrowToRecordGeospatial(t *testing.T) {
+	pool := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer pool.AssertSize(t, 0)
+	rowType := []execResponseRowType{
+		{Name: "G", Type: "geography"},
+		{Name: "M", Type: "geometry"},
+	}
+	pointWKB, err := hex.DecodeString(pointWKBHex)
+	assertNilF(t, err)
+
+	sb := array.NewStringBuilder(pool)
+	defer sb.Release()
+	sb.Append(pointWKT)
+	sb.AppendNull()
+	texts := sb.NewArray()
+	defer texts.Release()
+
+	bb := array.NewBinaryBuilder(pool, arrow.BinaryTypes.Binary)
+	defer bb.Release()
+	bb.Append(pointWKB)
+	bb.AppendNull()
+	binaries := bb.NewArray()
+	defer binaries.Release()
+
+	sc := arrow.NewSchema([]arrow.Field{{Name: "G", Type: arrow.BinaryTypes.String, Nullable: true}, {Name: "M", Type: arrow.BinaryTypes.Binary, Nullable: true}}, nil)
+	record := array.NewRecord(sc, []arrow.Array{texts, binaries}, 2)
+	defer record.Release()
+
+	ctx := WithArrowBatchesUtf8Validation(context.Background())
+	converted, err := arrowToRecord(ctx, record, pool, rowType, time.UTC)
+	assertNilF(t, err)
+	defer converted.Release()
+
+	assertEqualE(t, converted.Schema().Field(0).Type.ID(), arrow.STRING)
+	assertEqualE(t, converted.Schema().Field(1).Type.ID(), arrow.BINARY)
+	assertEqualE(t, converted.Column(0).(*array.String).Value(0), pointWKT)
+	assertTrueE(t, converted.Column(0).IsNull(1))
+	assertBytesEqualE(t, converted.Column(1).(*array.Binary).Value(0), pointWKB)
+	assertTrueE(t, converted.Column(1).IsNull(1))
+
+	var g Geography
+	var m Geometry
+	assertNilF(t, g.Scan(converted.Column(0).(*array.String).Value(0)))
+	assertNilF(t, m.Scan(converted.Column(1).(*array.Binary).Value(0)))
+	assertDeepEqualE(t, g.Shape, GeoShape(testPoint))
+	assertDeepEqualE(t, m.Shape, GeoShape(testPoint))
+}
+
+func TestGeospatialTypes(t *testing.T) {
+	for _, forceFormat := range []string{forceJSON, forceARROW} {
+		t.Run(forceFormat, func(t *testing.T) {
+			runDBTest(t, func(dbt *DBTest) {
+				dbt.mustExecT(t, forceFormat)
+				dbt.mustExec("create or replace table test_geo (g geography, m geometry)")
+				defer dbt.mustExec("drop table if exists test_geo")
+				dbt.mustExec("insert into test_geo values (?, ?)",
+					Geography{Shape: testPoint},
+					Geometry{Shape: GeoLineString{{1, 2}, {3, 4}}})
+
+				for _, outputFormat := range []string{"GeoJSON", "WKT", "EWKT", "WKB", "EWKB"} {
+					t.Run(outputFormat, func(t *testing.T) {
+						dbt.mustExec(fmt.Sprintf("alter session set GEOGRAPHY_OUTPUT_FORMAT = '%v', GEOMETRY_OUTPUT_FORMAT = '%v'", outputFormat, outputFormat))
+						rows := dbt.mustQuery("select g, m from test_geo")
+						defer rows.Close()
+						rows.mustNext()
+						var g Geography
+						var m Geometry
+						rows.mustScan(&g, &m)
+						assertDeepEqualE(t, g.Shape, GeoShape(testPoint))
+						assertDeepEqualE(t, m.Shape, GeoShape(GeoLineString{{1, 2}, {3, 4}}))
+						if outputFormat == "EWKT" || outputFormat == "EWKB" {
+							assertEqualE(t, g.SRID, 4326)
+						}
+					})
+				}
+			})
+		})
+	}
+}
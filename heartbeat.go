@@ -7,49 +7,98 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 const (
 	// One hour interval should be good enough to renew tokens for four hours master token validity
 	heartBeatInterval = 3600 * time.Second
+	// Heartbeats are sent up to a tenth of the interval early, so that the connections of a pool spread out
+	heartBeatJitterRatio = 0.1
+	// A connection whose heartbeats failed that many times in a row is discarded by database/sql
+	maxConsecutiveHeartbeatFailures = 3
 )
 
+// HeartbeatResult describes a heartbeat sent to keep a session alive. It is passed to
+// Config.OnHeartbeat.
+type HeartbeatResult struct {
+	SessionID           int64
+	Start               time.Time
+	Duration            time.Duration
+	Err                 error // nil if the heartbeat succeeded
+	ConsecutiveFailures int   // number of heartbeats failed in a row, including this one
+}
+
 type heartbeat struct {
-	restful      *snowflakeRestful
-	shutdownChan chan bool
+	restful     *snowflakeRestful
+	interval    time.Duration
+	onHeartbeat func(HeartbeatResult)
+	failures    atomic.Int32
+	scheduler   *heartbeatScheduler // the driver-wide scheduler if not set
+
+	// guarded by the scheduler
+	due        time.Time
+	index      int
+	registered bool
+	inFlight   sync.WaitGroup
 }
 
-func (hc *heartbeat) run() {
-	hbTicker := time.NewTicker(heartBeatInterval)
-	defer hbTicker.Stop()
-	for {
-		select {
-		case <-hbTicker.C:
-			err := hc.heartbeatMain()
-			if err != nil {
-				logger.Error("failed to heartbeat")
-			}
-		case <-hc.shutdownChan:
-			logger.Info("stopping heartbeat")
-			return
-		}
+func (hc *heartbeat) getInterval() time.Duration {
+	if hc.interval <= 0 {
+		return heartBeatInterval
+	}
+	return hc.interval
+}
+
+// nextInterval returns the interval shortened by a random jitter.
+func (hc *heartbeat) nextInterval() time.Duration {
+	interval := hc.getInterval()
+	return interval - time.Duration(chooseRandomFromRange(0, heartBeatJitterRatio)*float64(interval))
+}
+
+// beat sends a heartbeat, keeps track of the failures and reports the result.
+func (hc *heartbeat) beat() {
+	start := time.Now()
+	err := hc.heartbeatMain()
+	result := HeartbeatResult{Start: start, Duration: time.Since(start), Err: err}
+	if err != nil {
+		result.ConsecutiveFailures = int(hc.failures.Add(1))
+		logger.Errorf("failed to heartbeat. consecutive failures: %v, err: %v", result.ConsecutiveFailures, err)
+	} else {
+		hc.failures.Store(0)
+	}
+	if hc.onHeartbeat != nil {
+		_, _, result.SessionID = hc.restful.TokenAccessor.GetTokens()
+		hc.onHeartbeat(result)
+	}
+}
+
+// healthy tells whether the session is still expected to be alive.
+func (hc *heartbeat) healthy() bool {
+	return hc.failures.Load() < maxConsecutiveHeartbeatFailures
+}
+
+func (hc *heartbeat) getScheduler() *heartbeatScheduler {
+	if hc.scheduler == nil {
+		return defaultHeartbeatScheduler
 	}
+	return hc.scheduler
 }
 
 func (hc *heartbeat) start() {
-	hc.shutdownChan = make(chan bool)
-	go hc.run()
-	logger.Info("heartbeat started")
+	hc.getScheduler().register(hc)
+	logger.Infof("heartbeat started. interval: %v", hc.getInterval())
 }
 
 func (hc *heartbeat) stop() {
-	hc.shutdownChan <- true
-	close(hc.shutdownChan)
+	hc.getScheduler().deregister(hc)
 	logger.Info("heartbeat stopped")
 }
 
-func (hc *heartbeat) heartbeatMain() error {
+func (hc *heartbeat) heartbeatMain() (err error) {
+	defer func() { getMetrics(hc.restful.getConfig()).recordHeartbeat(context.Background(), err) }()
 	logger.Info("Heartbeating!")
 	params := &url.Values{}
 	params.Set(requestIDKey, NewUUID().String())
@@ -60,7 +109,7 @@ func (hc *heartbeat) heartbeatMain() error {
 
 	fullURL := hc.restful.getFullURL(heartBeatPath, params)
 	timeout := hc.restful.RequestTimeout
-	resp, err := hc.restful.FuncPost(context.Background(), hc.restful, fullURL, headers, nil, timeout, defaultTimeProvider, nil)
+	resp, err := hc.restful.FuncPost(context.Background(), hc.restful, fullURL, headers, nil, timeout, defaultTimeProvider, hc.restful.getConfig())
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,196 @@
+package gosnowflake
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// Number of heartbeats sent at the same time by the driver, whatever the number of connections
+const maxConcurrentHeartbeats = 8
+
+// defaultHeartbeatScheduler sends the heartbeats of all the connections of the process.
+var defaultHeartbeatScheduler = newHeartbeatScheduler(maxConcurrentHeartbeats)
+
+// heartbeatScheduler sends the heartbeats of many sessions from a single goroutine. The
+// heartbeats due at the same time are sent as a batch, with a bounded number of requests
+// in flight. The goroutine runs only while sessions are registered.
+type heartbeatScheduler struct {
+	mu         sync.Mutex
+	queue      heartbeatQueue
+	registered int
+	running    bool
+	wakeup     chan struct{}
+	slots      chan struct{}
+}
+
+func newHeartbeatScheduler(maxConcurrent int) *heartbeatScheduler {
+	return &heartbeatScheduler{
+		wakeup: make(chan struct{}, 1),
+		slots:  make(chan struct{}, maxConcurrent),
+	}
+}
+
+// register schedules the heartbeats of a session. The first heartbeat is sent at a random
+// point of the second half of the interval, so that the sessions opened together spread out.
+func (hs *heartbeatScheduler) register(hc *heartbeat) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	if hc.registered {
+		return
+	}
+	hc.registered = true
+	hs.registered++
+	hc.due = time.Now().Add(time.Duration(chooseRandomFromRange(0.5, 1) * float64(hc.getInterval())))
+	heap.Push(&hs.queue, hc)
+	if !hs.running {
+		hs.running = true
+		go hs.run()
+	} else {
+		hs.notify()
+	}
+}
+
+// deregister stops the heartbeats of a session, waiting for the one in flight if any. A
+// heartbeat that is due but not sent yet is dropped.
+func (hs *heartbeatScheduler) deregister(hc *heartbeat) {
+	hs.mu.Lock()
+	if !hc.registered {
+		hs.mu.Unlock()
+		return
+	}
+	hc.registered = false
+	hs.registered--
+	if hc.index >= 0 {
+		heap.Remove(&hs.queue, hc.index)
+	}
+	hs.notify()
+	hs.mu.Unlock()
+	hc.inFlight.Wait()
+}
+
+func (hs *heartbeatScheduler) run() {
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+	for {
+		hs.mu.Lock()
+		if hs.registered == 0 {
+			hs.running = false
+			hs.mu.Unlock()
+			return
+		}
+		batch := hs.popDue(time.Now())
+		var wait <-chan time.Time
+		if len(batch) == 0 && len(hs.queue) > 0 {
+			timer.Reset(time.Until(hs.queue[0].due))
+			wait = timer.C
+		}
+		hs.mu.Unlock()
+
+		if len(batch) > 0 {
+			logger.Debugf("sending %v heartbeats", len(batch))
+			for _, hc := range batch {
+				hs.send(hc)
+			}
+			continue
+		}
+		// with no heartbeat queued, all of them are in flight and wait stays nil
+		select {
+		case <-wait:
+		case <-hs.wakeup:
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+		}
+	}
+}
+
+// popDue removes the heartbeats due at now from the queue. The caller must hold hs.mu.
+func (hs *heartbeatScheduler) popDue(now time.Time) []*heartbeat {
+	var batch []*heartbeat
+	for len(hs.queue) > 0 && !hs.queue[0].due.After(now) {
+		batch = append(batch, heap.Pop(&hs.queue).(*heartbeat))
+	}
+	return batch
+}
+
+// send waits for a free slot and sends the heartbeat in the background.
+func (hs *heartbeatScheduler) send(hc *heartbeat) {
+	hs.slots <- struct{}{}
+	go func() {
+		defer func() { <-hs.slots }()
+		if !hs.startBeat(hc) {
+			return
+		}
+		defer hc.inFlight.Done()
+		hc.beat()
+		hs.reschedule(hc)
+	}()
+}
+
+// startBeat marks the heartbeat of a session in flight, unless the session was
+// deregistered while the heartbeat waited for a slot. A session registered again
+// meanwhile is back in the queue and is not sent twice.
+func (hs *heartbeatScheduler) startBeat(hc *heartbeat) bool {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	if !hc.registered || hc.index >= 0 {
+		return false
+	}
+	hc.inFlight.Add(1)
+	return true
+}
+
+func (hs *heartbeatScheduler) reschedule(hc *heartbeat) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	if !hc.registered {
+		return
+	}
+	hc.due = time.Now().Add(hc.nextInterval())
+	heap.Push(&hs.queue, hc)
+	hs.notify()
+}
+
+// notify wakes up the scheduler goroutine to look at the queue again.
+func (hs *heartbeatScheduler) notify() {
+	select {
+	case hs.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// heartbeatQueue is a heap of heartbeats ordered by their due time.
+type heartbeatQueue []*heartbeat
+
+func (q heartbeatQueue) Len() int {
+	return len(q)
+}
+
+func (q heartbeatQueue) Less(i, j int) bool {
+	return q[i].due.Before(q[j].due)
+}
+
+func (q heartbeatQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *heartbeatQueue) Push(x any) {
+	hc := x.(*heartbeat)
+	hc.index = len(*q)
+	*q = append(*q, hc)
+}
+
+func (q *heartbeatQueue) Pop() any {
+	old := *q
+	hc := old[len(old)-1]
+	old[len(old)-1] = nil
+	hc.index = -1
+	*q = old[:len(old)-1]
+	return hc
+}
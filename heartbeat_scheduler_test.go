@@ -0,0 +1,157 @@
+package gosnowflake
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestUnitHeartbeatSchedulerSpreadsHeartbeats(t *testing.T) {
+	const sessions = 20
+	const maxConcurrent = 3
+	var inFlight, maxInFlight atomic.Int32
+	var mu sync.Mutex
+	beats := make(map[int64]int)
+	post := func(context.Context, *snowflakeRestful, *url.URL, map[string]string, []byte, time.Duration, currentTimeProvider, *Config) (*http.Response, error) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		return jsonResponse(`{"success": true}`), nil
+	}
+
+	scheduler := newHeartbeatScheduler(maxConcurrent)
+	var heartbeats []*heartbeat
+	for i := 0; i < sessions; i++ {
+		sr := &snowflakeRestful{FuncPost: post, TokenAccessor: getSimpleTokenAccessor()}
+		sr.TokenAccessor.SetTokens("token", "master token", int64(i))
+		hc := &heartbeat{
+			restful:   sr,
+			interval:  50 * time.Millisecond,
+			scheduler: scheduler,
+			onHeartbeat: func(result HeartbeatResult) {
+				assertNilE(t, result.Err)
+				mu.Lock()
+				beats[result.SessionID]++
+				mu.Unlock()
+			},
+		}
+		hc.start()
+		heartbeats = append(heartbeats, hc)
+	}
+
+	time.Sleep(300 * time.Millisecond)
+	for _, hc := range heartbeats {
+		hc.stop()
+	}
+	mu.Lock()
+	assertEqualE(t, len(beats), sessions)
+	for sessionID, n := range beats {
+		assertTrueE(t, n >= 2, fmt.Sprintf("session %v sent %v heartbeats", sessionID, n))
+	}
+	mu.Unlock()
+	assertTrueE(t, maxInFlight.Load() <= maxConcurrent)
+
+	// no heartbeat is sent after stop
+	mu.Lock()
+	sent := len(beats)
+	total := 0
+	for _, n := range beats {
+		total += n
+	}
+	mu.Unlock()
+	time.Sleep(100 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	after := 0
+	for _, n := range beats {
+		after += n
+	}
+	assertEqualE(t, len(beats), sent)
+	assertEqualE(t, after, total)
+	scheduler.mu.Lock()
+	defer scheduler.mu.Unlock()
+	assertEqualE(t, scheduler.registered, 0)
+	assertEqualE(t, len(scheduler.queue), 0)
+}
+
+func TestUnitHeartbeatSchedulerStopsWhenIdle(t *testing.T) {
+	scheduler := newHeartbeatScheduler(1)
+	sr := &snowflakeRestful{FuncPost: postTestError, TokenAccessor: getSimpleTokenAccessor()}
+	hc := &heartbeat{restful: sr, interval: time.Hour, scheduler: scheduler}
+	hc.start()
+	hc.start()
+	scheduler.mu.Lock()
+	assertEqualE(t, scheduler.registered, 1)
+	assertTrueE(t, scheduler.running)
+	scheduler.mu.Unlock()
+
+	hc.stop()
+	hc.stop()
+	for i := 0; i < 100; i++ {
+		scheduler.mu.Lock()
+		running := scheduler.running
+		scheduler.mu.Unlock()
+		if !running {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	scheduler.mu.Lock()
+	defer scheduler.mu.Unlock()
+	assertFalseE(t, scheduler.running)
+	assertEqualE(t, scheduler.registered, 0)
+}
+
+func TestUnitHeartbeatSchedulerDropsHeartbeatsOfDeregisteredSessions(t *testing.T) {
+	var posts atomic.Int32
+	post := func(context.Context, *snowflakeRestful, *url.URL, map[string]string, []byte, time.Duration, currentTimeProvider, *Config) (*http.Response, error) {
+		posts.Add(1)
+		return jsonResponse(`{"success": true}`), nil
+	}
+	scheduler := newHeartbeatScheduler(1)
+	// the heartbeats are sent by the test instead of the scheduler goroutine
+	scheduler.running = true
+	sr := &snowflakeRestful{FuncPost: post, TokenAccessor: getSimpleTokenAccessor()}
+	hc := &heartbeat{restful: sr, interval: time.Hour, scheduler: scheduler}
+	scheduler.register(hc)
+	scheduler.mu.Lock()
+	batch := scheduler.popDue(time.Now().Add(time.Hour))
+	scheduler.mu.Unlock()
+	assertEqualF(t, len(batch), 1)
+
+	// the heartbeat waits for a slot while the session is deregistered
+	scheduler.slots <- struct{}{}
+	sent := make(chan struct{})
+	go func() {
+		scheduler.send(hc)
+		close(sent)
+	}()
+	deregistered := make(chan struct{})
+	go func() {
+		scheduler.deregister(hc)
+		close(deregistered)
+	}()
+	select {
+	case <-deregistered:
+	case <-time.After(time.Second):
+		t.Fatal("deregister waited for a heartbeat that was not sent yet")
+	}
+	<-scheduler.slots
+	<-sent
+	for i := 0; i < 100 && len(scheduler.slots) > 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assertEqualE(t, len(scheduler.slots), 0)
+	assertEqualE(t, posts.Load(), int32(0))
+}
@@ -2,7 +2,10 @@ package gosnowflake
 
 import (
 	"context"
+	"net/http"
+	"net/url"
 	"testing"
+	"time"
 )
 
 func TestUnitPostHeartbeat(t *testing.T) {
@@ -60,3 +63,65 @@ func TestHeartbeatStartAndStop(t *testing.T) {
 	assertNilF(t, err, "should not cause error in Close")
 	assertNilF(t, conn.rest.HeartBeat, "heartbeat should be nil")
 }
+
+func TestUnitHeartbeatInterval(t *testing.T) {
+	sc := &snowflakeConn{cfg: &Config{Params: map[string]*string{}}}
+	assertEqualE(t, sc.getHeartbeatInterval(), heartBeatInterval)
+
+	sc.cfg.HeartbeatInterval = 20 * time.Minute
+	assertEqualE(t, sc.getHeartbeatInterval(), 20*time.Minute)
+
+	frequency := "900"
+	sc.cfg.Params[sessionClientSessionKeepAliveHeartbeatFrequency] = &frequency
+	assertEqualE(t, sc.getHeartbeatInterval(), 15*time.Minute)
+
+	sc.cfg.HeartbeatInterval = 5 * time.Minute
+	assertEqualE(t, sc.getHeartbeatInterval(), 5*time.Minute)
+
+	invalid := "often"
+	sc.cfg.Params[sessionClientSessionKeepAliveHeartbeatFrequency] = &invalid
+	assertEqualE(t, sc.getHeartbeatInterval(), 5*time.Minute)
+}
+
+func TestUnitHeartbeatJitter(t *testing.T) {
+	hc := &heartbeat{interval: 10 * time.Minute}
+	for i := 0; i < 100; i++ {
+		interval := hc.nextInterval()
+		assertTrueE(t, interval > 9*time.Minute && interval <= 10*time.Minute, interval.String())
+	}
+}
+
+func TestUnitHeartbeatFailuresInvalidateConnection(t *testing.T) {
+	postHeartbeatSuccess := func(context.Context, *snowflakeRestful, *url.URL, map[string]string, []byte, time.Duration, currentTimeProvider, *Config) (*http.Response, error) {
+		return jsonResponse(`{"success": true}`), nil
+	}
+	var results []HeartbeatResult
+	sc := &snowflakeConn{
+		cfg: &Config{Params: map[string]*string{}},
+		rest: &snowflakeRestful{
+			FuncPost:      postTestError,
+			TokenAccessor: getSimpleTokenAccessor(),
+		},
+	}
+	sc.rest.TokenAccessor.SetTokens("token", "master token", 123)
+	sc.rest.HeartBeat = &heartbeat{
+		restful:     sc.rest,
+		onHeartbeat: func(result HeartbeatResult) { results = append(results, result) },
+	}
+
+	for i := 1; i <= maxConsecutiveHeartbeatFailures; i++ {
+		assertTrueE(t, sc.IsValid())
+		sc.rest.HeartBeat.beat()
+		assertEqualF(t, len(results), i)
+		assertNotNilE(t, results[i-1].Err)
+		assertEqualE(t, results[i-1].ConsecutiveFailures, i)
+		assertEqualE(t, results[i-1].SessionID, int64(123))
+	}
+	assertFalseE(t, sc.IsValid())
+
+	sc.rest.FuncPost = postHeartbeatSuccess
+	sc.rest.HeartBeat.beat()
+	assertNilE(t, results[len(results)-1].Err)
+	assertEqualE(t, results[len(results)-1].ConsecutiveFailures, 0)
+	assertTrueE(t, sc.IsValid())
+}
@@ -0,0 +1,106 @@
+package gosnowflake
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"time"
+)
+
+// QueryKind describes how a statement reported to a QueryInterceptor was executed.
+type QueryKind int
+
+const (
+	// StatementQuery is a single statement, or a multi-statement request as a whole.
+	StatementQuery QueryKind = iota
+	// MultiStatementChildQuery is one of the statements of a multi-statement request.
+	MultiStatementChildQuery
+	// FileTransferQuery is a PUT or GET command, including the file transfer itself.
+	FileTransferQuery
+)
+
+// QueryInfo describes a statement sent to Snowflake.
+type QueryInfo struct {
+	Kind      QueryKind
+	Query     string              // SQL text. It can be rewritten in BeforeExecute
+	Bindings  []driver.NamedValue // bound parameters. They can be replaced in BeforeExecute
+	RequestID UUID
+	// Parameters are the session parameters sent along with the statement, e.g. QUERY_TAG.
+	// Parameters added in BeforeExecute apply to this statement only.
+	Parameters    map[string]interface{}
+	IsInternal    bool   // the statement is issued by the driver itself
+	DescribeOnly  bool   // the statement is only described, e.g. when it is prepared
+	ParentQueryID string // query ID of the multi-statement request, set for MultiStatementChildQuery
+}
+
+// QueryExecutionResult describes the outcome of a statement sent to Snowflake.
+type QueryExecutionResult struct {
+	QueryID      string
+	Duration     time.Duration
+	RowsAffected int64 // -1 if not known or not applicable
+	Err          error
+}
+
+// QueryInterceptor is invoked around the statements a connection executes. It is
+// registered with Config.QueryInterceptor.
+//
+// BeforeExecute is called before a statement is sent to Snowflake. It can rewrite the
+// query, replace the bindings or add session parameters by modifying info. Returning an
+// error rejects the statement, and the error is returned to the caller as is.
+//
+// AfterExecute is called once the statement has completed or has been rejected. For
+// multi-statement requests it is also called for each child statement, with Kind set to
+// MultiStatementChildQuery. Such calls are not preceded by BeforeExecute since the child
+// statements are executed by the server.
+type QueryInterceptor interface {
+	BeforeExecute(ctx context.Context, info *QueryInfo) error
+	AfterExecute(ctx context.Context, info *QueryInfo, result *QueryExecutionResult)
+}
+
+func (sc *snowflakeConn) queryInterceptor() QueryInterceptor {
+	if sc.cfg == nil {
+		return nil
+	}
+	return sc.cfg.QueryInterceptor
+}
+
+// interceptedResult builds the result passed to AfterExecute from the server response.
+func interceptedResult(data *execResponse, err error, start time.Time) *QueryExecutionResult {
+	result := &QueryExecutionResult{
+		Duration:     time.Since(start),
+		RowsAffected: -1,
+		Err:          err,
+	}
+	if data == nil {
+		var se *SnowflakeError
+		if errors.As(err, &se) {
+			result.QueryID = se.QueryID
+		}
+		return result
+	}
+	result.QueryID = data.Data.QueryID
+	if err == nil && isDml(data.Data.StatementTypeID) {
+		if count, err := updateRows(data.Data); err == nil {
+			result.RowsAffected = count
+		}
+	}
+	return result
+}
+
+// afterChildExecute reports a child statement of a multi-statement request.
+func (sc *snowflakeConn) afterChildExecute(ctx context.Context, parentQueryID string, childQueryID string, rowsAffected int64, err error) {
+	interceptor := sc.queryInterceptor()
+	if interceptor == nil {
+		return
+	}
+	info := &QueryInfo{
+		Kind:          MultiStatementChildQuery,
+		ParentQueryID: parentQueryID,
+		Parameters:    map[string]interface{}{},
+	}
+	interceptor.AfterExecute(ctx, info, &QueryExecutionResult{
+		QueryID:      childQueryID,
+		RowsAffected: rowsAffected,
+		Err:          err,
+	})
+}
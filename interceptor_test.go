@@ -0,0 +1,218 @@
+package gosnowflake
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+type recordingInterceptor struct {
+	before  func(ctx context.Context, info *QueryInfo) error
+	infos   []QueryInfo
+	results []QueryExecutionResult
+}
+
+func (ri *recordingInterceptor) BeforeExecute(ctx context.Context, info *QueryInfo) error {
+	if ri.before != nil {
+		return ri.before(ctx, info)
+	}
+	return nil
+}
+
+func (ri *recordingInterceptor) AfterExecute(_ context.Context, info *QueryInfo, result *QueryExecutionResult) {
+	ri.infos = append(ri.infos, *info)
+	ri.results = append(ri.results, *result)
+}
+
+func TestUnitQueryInterceptorRewritesStatement(t *testing.T) {
+	var sentRequest execRequest
+	postQueryMock := func(_ context.Context, _ *snowflakeRestful, _ *url.Values,
+		_ map[string]string, body []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		assertNilF(t, json.Unmarshal(body, &sentRequest))
+		inserted := "2"
+		return &execResponse{
+			Data: execResponseData{
+				QueryID:         "01b2c3d4-0000-0000-0000-000000000010",
+				StatementTypeID: statementTypeIDDml,
+				RowType:         []execResponseRowType{{Name: "number of rows inserted"}},
+				RowSet:          [][]*string{{&inserted}},
+			},
+			Success: true,
+		}, nil
+	}
+	interceptor := &recordingInterceptor{
+		before: func(_ context.Context, info *QueryInfo) error {
+			info.Query = "/* audited */ " + info.Query
+			info.Parameters["QUERY_TAG"] = "audit"
+			return nil
+		},
+	}
+	sc := &snowflakeConn{
+		cfg:               &Config{Params: map[string]*string{}, QueryInterceptor: interceptor},
+		rest:              &snowflakeRestful{FuncPostQuery: postQueryMock},
+		queryContextCache: (&queryContextCache{}).init(),
+	}
+
+	result, err := sc.ExecContext(context.Background(), "INSERT INTO t VALUES (?), (?)", []driver.NamedValue{
+		{Ordinal: 1, Value: int64(1)},
+		{Ordinal: 2, Value: int64(2)},
+	})
+	assertNilF(t, err)
+	rows, err := result.RowsAffected()
+	assertNilF(t, err)
+	assertEqualE(t, rows, int64(2))
+
+	assertEqualE(t, sentRequest.SQLText, "/* audited */ INSERT INTO t VALUES (?), (?)")
+	assertEqualE(t, sentRequest.Parameters["QUERY_TAG"], "audit")
+
+	assertEqualF(t, len(interceptor.results), 1)
+	info := interceptor.infos[0]
+	assertEqualE(t, info.Kind, StatementQuery)
+	assertEqualE(t, len(info.Bindings), 2)
+	assertFalseE(t, info.RequestID == nilUUID)
+	res := interceptor.results[0]
+	assertNilE(t, res.Err)
+	assertEqualE(t, res.QueryID, "01b2c3d4-0000-0000-0000-000000000010")
+	assertEqualE(t, res.RowsAffected, int64(2))
+}
+
+func TestUnitQueryInterceptorParametersApplyToOneStatement(t *testing.T) {
+	var sentRequest execRequest
+	postQueryMock := func(_ context.Context, _ *snowflakeRestful, _ *url.Values,
+		_ map[string]string, body []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		sentRequest = execRequest{}
+		assertNilF(t, json.Unmarshal(body, &sentRequest))
+		// like Snowflake, echo the parameters the statement ran with
+		parameters := []nameValueParameter{{Name: "CLIENT_RESULT_CHUNK_SIZE", Value: float64(160)}}
+		for name, value := range sentRequest.Parameters {
+			parameters = append(parameters, nameValueParameter{Name: name, Value: value})
+		}
+		return &execResponse{
+			Data:    execResponseData{Parameters: parameters, StatementTypeID: statementTypeIDDdl},
+			Success: true,
+		}, nil
+	}
+	intercepted := false
+	interceptor := &recordingInterceptor{
+		before: func(_ context.Context, info *QueryInfo) error {
+			if !intercepted {
+				info.Parameters["TIMEZONE"] = "Asia/Tokyo"
+				info.Parameters["QUERY_TAG"] = "audit"
+				intercepted = true
+			}
+			return nil
+		},
+	}
+	sessionTimezone := "UTC"
+	sc := &snowflakeConn{
+		cfg:               &Config{Params: map[string]*string{"timezone": &sessionTimezone}, QueryInterceptor: interceptor},
+		rest:              &snowflakeRestful{FuncPostQuery: postQueryMock},
+		queryContextCache: (&queryContextCache{}).init(),
+	}
+
+	_, err := sc.ExecContext(context.Background(), "CREATE TABLE t (c int)", nil)
+	assertNilF(t, err)
+	assertEqualE(t, sentRequest.Parameters["TIMEZONE"], "Asia/Tokyo")
+	assertEqualE(t, *sc.cfg.Params["timezone"], "UTC")
+	_, ok := sc.cfg.Params["query_tag"]
+	assertFalseE(t, ok, "query_tag should not be kept")
+	assertEqualE(t, *sc.cfg.Params["client_result_chunk_size"], "160")
+
+	_, err = sc.ExecContext(context.Background(), "CREATE TABLE u (c int)", nil)
+	assertNilF(t, err)
+	assertEqualE(t, len(sentRequest.Parameters), 0)
+	assertEqualE(t, *sc.cfg.Params["timezone"], "UTC")
+}
+
+func TestUnitQueryInterceptorRejectsStatement(t *testing.T) {
+	rejected := errors.New("statement is not allowed")
+	posted := false
+	postQueryMock := func(_ context.Context, _ *snowflakeRestful, _ *url.Values,
+		_ map[string]string, _ []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		posted = true
+		return nil, errors.New("should not be called")
+	}
+	interceptor := &recordingInterceptor{
+		before: func(_ context.Context, _ *QueryInfo) error {
+			return rejected
+		},
+	}
+	sc := &snowflakeConn{
+		cfg:               &Config{Params: map[string]*string{}, QueryInterceptor: interceptor},
+		rest:              &snowflakeRestful{FuncPostQuery: postQueryMock},
+		queryContextCache: (&queryContextCache{}).init(),
+	}
+
+	_, err := sc.QueryContext(context.Background(), "DROP TABLE t", nil)
+	assertTrueF(t, errors.Is(err, rejected))
+	assertFalseE(t, posted)
+	assertEqualF(t, len(interceptor.results), 1)
+	assertTrueE(t, errors.Is(interceptor.results[0].Err, rejected))
+	assertEqualE(t, interceptor.results[0].RowsAffected, int64(-1))
+}
+
+func TestUnitQueryInterceptorReportsFailedStatement(t *testing.T) {
+	postQueryMock := func(_ context.Context, _ *snowflakeRestful, _ *url.Values,
+		_ map[string]string, _ []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		return &execResponse{
+			Data:    execResponseData{QueryID: "01b2c3d4-0000-0000-0000-000000000011"},
+			Message: "SQL compilation error",
+			Code:    "1003",
+			Success: false,
+		}, nil
+	}
+	interceptor := &recordingInterceptor{}
+	sc := &snowflakeConn{
+		cfg:               &Config{Params: map[string]*string{}, QueryInterceptor: interceptor},
+		rest:              &snowflakeRestful{FuncPostQuery: postQueryMock},
+		queryContextCache: (&queryContextCache{}).init(),
+	}
+
+	_, err := sc.ExecContext(context.Background(), "SELEC 1", nil)
+	assertNotNilF(t, err)
+	assertEqualF(t, len(interceptor.results), 1)
+	assertEqualE(t, interceptor.results[0].QueryID, "01b2c3d4-0000-0000-0000-000000000011")
+	assertEqualE(t, interceptor.results[0].Err, err)
+}
+
+func TestUnitQueryInterceptorMultiStatementChildren(t *testing.T) {
+	getMock := func(_ context.Context, _ *snowflakeRestful, _ *url.URL, _ map[string]string, _ time.Duration) (*http.Response, error) {
+		body := `{"success": true, "data": {"queryId": "child", "rowtype": [{"name": "number of rows updated"}], "rowset": [["5"]]}}`
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(bytes.NewBufferString(body)),
+		}, nil
+	}
+	interceptor := &recordingInterceptor{}
+	sc := &snowflakeConn{
+		cfg:                 &Config{Params: map[string]*string{}, QueryInterceptor: interceptor},
+		rest:                &snowflakeRestful{FuncGet: getMock, TokenAccessor: getSimpleTokenAccessor()},
+		currentTimeProvider: defaultTimeProvider,
+	}
+
+	result, err := sc.handleMultiExec(context.Background(), execResponseData{
+		QueryID:     "parent",
+		ResultIDs:   "child1,child2",
+		ResultTypes: "12544,4096",
+	})
+	assertNilF(t, err)
+	rows, err := result.RowsAffected()
+	assertNilF(t, err)
+	assertEqualE(t, rows, int64(5))
+
+	assertEqualF(t, len(interceptor.results), 2)
+	for i, queryID := range []string{"child1", "child2"} {
+		assertEqualE(t, interceptor.infos[i].Kind, MultiStatementChildQuery)
+		assertEqualE(t, interceptor.infos[i].ParentQueryID, "parent")
+		assertEqualE(t, interceptor.results[i].QueryID, queryID)
+	}
+	assertEqualE(t, interceptor.results[0].RowsAffected, int64(5))
+	assertEqualE(t, interceptor.results[1].RowsAffected, int64(-1))
+}
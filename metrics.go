@@ -0,0 +1,251 @@
+package gosnowflake
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+const meterName = tracerName
+
+// metric attribute keys
+const (
+	endpointAttribute   = attribute.Key("snowflake.endpoint")
+	statusCodeAttribute = attribute.Key("http.response.status_code")
+	outcomeAttribute    = attribute.Key("snowflake.outcome")
+	directionAttribute  = attribute.Key("snowflake.file.direction")
+	fileStatusAttribute = attribute.Key("snowflake.file.status")
+)
+
+const (
+	outcomeSuccess = "success"
+	outcomeFailure = "failure"
+)
+
+// driverMetrics holds the instruments the driver reports to a MeterProvider.
+type driverMetrics struct {
+	httpAttempts         metric.Int64Counter
+	httpRetries          metric.Int64Counter
+	httpRequests         metric.Int64Counter
+	chunkSize            metric.Int64Histogram
+	chunkDecodeDuration  metric.Float64Histogram
+	chunkWaitDuration    metric.Float64Histogram
+	heartbeats           metric.Int64Counter
+	sessionRenewals      metric.Int64Counter
+	fileTransfers        metric.Int64Counter
+	fileTransferSize     metric.Int64Counter
+	fileTransferDuration metric.Float64Histogram
+}
+
+// metricsCache keeps the instruments created by the last MeterProvider it was asked for.
+// A Config holds one, shared by its copies, e.g. the connections of a Connector, so that
+// the instruments of a provider are released with the connections using it.
+type metricsCache struct {
+	mu       sync.Mutex
+	provider metric.MeterProvider
+	metrics  *driverMetrics
+}
+
+// globalMetrics caches the instruments of the globally registered MeterProvider.
+var globalMetrics metricsCache
+
+func newMetricsCache() *metricsCache {
+	return &metricsCache{}
+}
+
+// get returns the instruments of the provider, created again when the provider changed.
+func (mc *metricsCache) get(mp metric.MeterProvider) *driverMetrics {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	if mc.metrics == nil || !sameMeterProvider(mc.provider, mp) {
+		mc.provider = mp
+		mc.metrics = newProviderMetrics(mp)
+	}
+	return mc.metrics
+}
+
+func sameMeterProvider(a metric.MeterProvider, b metric.MeterProvider) bool {
+	// comparing two providers of an uncomparable type panics
+	return reflect.TypeOf(a) == reflect.TypeOf(b) && reflect.TypeOf(a).Comparable() && a == b
+}
+
+// getMetrics returns the instruments created by the MeterProvider configured in cfg.
+// The globally registered provider is used when none is configured.
+func getMetrics(cfg *Config) *driverMetrics {
+	if cfg == nil || cfg.MeterProvider == nil {
+		return globalMetrics.get(otel.GetMeterProvider())
+	}
+	if cfg.metrics == nil {
+		return newProviderMetrics(cfg.MeterProvider)
+	}
+	return cfg.metrics.get(cfg.MeterProvider)
+}
+
+func newProviderMetrics(mp metric.MeterProvider) *driverMetrics {
+	return newDriverMetrics(mp.Meter(meterName, metric.WithInstrumentationVersion(SnowflakeGoDriverVersion)))
+}
+
+func newDriverMetrics(meter metric.Meter) *driverMetrics {
+	return &driverMetrics{
+		httpAttempts: newInt64Counter(meter, "snowflake.http.attempts", "{attempt}",
+			"Number of HTTP requests sent, including retries."),
+		httpRetries: newInt64Counter(meter, "snowflake.http.retries", "{retry}",
+			"Number of HTTP requests retried, by the HTTP status that caused the retry (0 for connection errors)."),
+		httpRequests: newInt64Counter(meter, "snowflake.http.requests", "{request}",
+			"Number of HTTP requests completed after all retries, by final HTTP status."),
+		chunkSize: newInt64Histogram(meter, "snowflake.chunk.size", "By",
+			"Size of downloaded result chunks as transferred over the network."),
+		chunkDecodeDuration: newFloat64Histogram(meter, "snowflake.chunk.decode.duration", "s",
+			"Time spent decoding downloaded result chunks."),
+		chunkWaitDuration: newFloat64Histogram(meter, "snowflake.chunk.wait.duration", "s",
+			"Time spent waiting for the next result chunk while iterating over rows."),
+		heartbeats: newInt64Counter(meter, "snowflake.session.heartbeats", "{heartbeat}",
+			"Number of session heartbeats sent."),
+		sessionRenewals: newInt64Counter(meter, "snowflake.session.renewals", "{renewal}",
+			"Number of session token renewals."),
+		fileTransfers: newInt64Counter(meter, "snowflake.file_transfer.files", "{file}",
+			"Number of files uploaded or downloaded by PUT and GET."),
+		fileTransferSize: newInt64Counter(meter, "snowflake.file_transfer.size", "By",
+			"Number of bytes uploaded or downloaded by PUT and GET."),
+		fileTransferDuration: newFloat64Histogram(meter, "snowflake.file_transfer.duration", "s",
+			"Time spent uploading or downloading a single file."),
+	}
+}
+
+func newInt64Counter(meter metric.Meter, name string, unit string, description string) metric.Int64Counter {
+	c, err := meter.Int64Counter(name, metric.WithUnit(unit), metric.WithDescription(description))
+	if err != nil || c == nil {
+		logger.Warnf("failed to create metric %v. %v", name, err)
+		return noop.Int64Counter{}
+	}
+	return c
+}
+
+func newInt64Histogram(meter metric.Meter, name string, unit string, description string) metric.Int64Histogram {
+	h, err := meter.Int64Histogram(name, metric.WithUnit(unit), metric.WithDescription(description))
+	if err != nil || h == nil {
+		logger.Warnf("failed to create metric %v. %v", name, err)
+		return noop.Int64Histogram{}
+	}
+	return h
+}
+
+func newFloat64Histogram(meter metric.Meter, name string, unit string, description string) metric.Float64Histogram {
+	h, err := meter.Float64Histogram(name, metric.WithUnit(unit), metric.WithDescription(description))
+	if err != nil || h == nil {
+		logger.Warnf("failed to create metric %v. %v", name, err)
+		return noop.Float64Histogram{}
+	}
+	return h
+}
+
+func outcomeOf(err error) attribute.KeyValue {
+	if err != nil {
+		return outcomeAttribute.String(outcomeFailure)
+	}
+	return outcomeAttribute.String(outcomeSuccess)
+}
+
+func (m *driverMetrics) recordHTTPAttempt(ctx context.Context, endpoint string) {
+	m.httpAttempts.Add(ctx, 1, metric.WithAttributes(endpointAttribute.String(endpoint)))
+}
+
+func (m *driverMetrics) recordHTTPRetry(ctx context.Context, endpoint string, statusCode int) {
+	m.httpRetries.Add(ctx, 1, metric.WithAttributes(
+		endpointAttribute.String(endpoint),
+		statusCodeAttribute.Int(statusCode)))
+}
+
+func (m *driverMetrics) recordHTTPRequest(ctx context.Context, endpoint string, statusCode int, err error) {
+	m.httpRequests.Add(ctx, 1, metric.WithAttributes(
+		endpointAttribute.String(endpoint),
+		statusCodeAttribute.Int(statusCode),
+		outcomeOf(err)))
+}
+
+func (m *driverMetrics) recordChunkDownload(ctx context.Context, format resultFormat, size int64, decodeDuration time.Duration) {
+	attrs := metric.WithAttributes(resultFormatAttribute.String(string(format)))
+	m.chunkSize.Record(ctx, size, attrs)
+	m.chunkDecodeDuration.Record(ctx, decodeDuration.Seconds(), attrs)
+}
+
+func (m *driverMetrics) recordChunkWait(ctx context.Context, wait time.Duration) {
+	m.chunkWaitDuration.Record(ctx, wait.Seconds())
+}
+
+func (m *driverMetrics) recordHeartbeat(ctx context.Context, err error) {
+	m.heartbeats.Add(ctx, 1, metric.WithAttributes(outcomeOf(err)))
+}
+
+func (m *driverMetrics) recordSessionRenewal(ctx context.Context, err error) {
+	m.sessionRenewals.Add(ctx, 1, metric.WithAttributes(outcomeOf(err)))
+}
+
+func (m *driverMetrics) recordFileTransfer(ctx context.Context, direction string, status resultStatus, size int64, duration time.Duration, err error) {
+	statusName := errStatus.String()
+	if status.isSet() {
+		statusName = status.String()
+	}
+	attrs := metric.WithAttributes(
+		directionAttribute.String(direction),
+		fileStatusAttribute.String(statusName),
+		outcomeOf(err))
+	m.fileTransfers.Add(ctx, 1, attrs)
+	if err == nil && size > 0 {
+		m.fileTransferSize.Add(ctx, size, attrs)
+	}
+	m.fileTransferDuration.Record(ctx, duration.Seconds(), attrs)
+}
+
+// endpointName maps a request URL to a low cardinality name used as a metric attribute.
+func endpointName(u *url.URL) string {
+	if u == nil {
+		return "other"
+	}
+	switch u.Path {
+	case loginRequestPath:
+		return "login"
+	case tokenRequestPath:
+		return "token"
+	case authenticatorRequestPath:
+		return "authenticator"
+	case queryRequestPath:
+		return "query"
+	case abortRequestPath:
+		return "abort"
+	case heartBeatPath:
+		return "heartbeat"
+	case sessionRequestPath:
+		return "session"
+	}
+	switch {
+	case strings.HasPrefix(u.Path, monitoringQueriesPath):
+		return "monitoring"
+	case strings.HasPrefix(u.Path, "/queries/"):
+		return "query_result"
+	case strings.HasPrefix(u.Path, "/telemetry/"):
+		return "telemetry"
+	}
+	return "other"
+}
+
+// countingReader counts the bytes read from the underlying reader.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,202 @@
+package gosnowflake
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+// recordingMeterProvider keeps every measurement reported through its instruments.
+type recordingMeterProvider struct {
+	noop.MeterProvider
+	meter *recordingMeter
+}
+
+func newRecordingMeterProvider() *recordingMeterProvider {
+	return &recordingMeterProvider{meter: &recordingMeter{}}
+}
+
+func (p *recordingMeterProvider) Meter(string, ...metric.MeterOption) metric.Meter {
+	return p.meter
+}
+
+type measurement struct {
+	value float64
+	attrs attribute.Set
+}
+
+type recordingMeter struct {
+	noop.Meter
+	mu           sync.Mutex
+	measurements map[string][]measurement
+}
+
+func (m *recordingMeter) record(name string, value float64, attrs attribute.Set) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.measurements == nil {
+		m.measurements = make(map[string][]measurement)
+	}
+	m.measurements[name] = append(m.measurements[name], measurement{value, attrs})
+}
+
+func (m *recordingMeter) get(name string) []measurement {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.measurements[name]
+}
+
+func (m *recordingMeter) Int64Counter(name string, _ ...metric.Int64CounterOption) (metric.Int64Counter, error) {
+	return &recordingInt64Counter{name: name, meter: m}, nil
+}
+
+func (m *recordingMeter) Int64Histogram(name string, _ ...metric.Int64HistogramOption) (metric.Int64Histogram, error) {
+	return &recordingInt64Histogram{name: name, meter: m}, nil
+}
+
+func (m *recordingMeter) Float64Histogram(name string, _ ...metric.Float64HistogramOption) (metric.Float64Histogram, error) {
+	return &recordingFloat64Histogram{name: name, meter: m}, nil
+}
+
+type recordingInt64Counter struct {
+	noop.Int64Counter
+	name  string
+	meter *recordingMeter
+}
+
+func (c *recordingInt64Counter) Add(_ context.Context, incr int64, options ...metric.AddOption) {
+	c.meter.record(c.name, float64(incr), metric.NewAddConfig(options).Attributes())
+}
+
+type recordingInt64Histogram struct {
+	noop.Int64Histogram
+	name  string
+	meter *recordingMeter
+}
+
+func (h *recordingInt64Histogram) Record(_ context.Context, value int64, options ...metric.RecordOption) {
+	h.meter.record(h.name, float64(value), metric.NewRecordConfig(options).Attributes())
+}
+
+type recordingFloat64Histogram struct {
+	noop.Float64Histogram
+	name  string
+	meter *recordingMeter
+}
+
+func (h *recordingFloat64Histogram) Record(_ context.Context, value float64, options ...metric.RecordOption) {
+	h.meter.record(h.name, value, metric.NewRecordConfig(options).Attributes())
+}
+
+func attributeValue(attrs attribute.Set, key attribute.Key) attribute.Value {
+	v, _ := attrs.Value(key)
+	return v
+}
+
+func TestUnitRetryHTTPReportsMetrics(t *testing.T) {
+	mp := newRecordingMeterProvider()
+	client := &fakeHTTPClient{
+		cnt:        2,
+		success:    true,
+		statusCode: http.StatusServiceUnavailable,
+		t:          t,
+	}
+	urlPtr, err := url.Parse("https://fakeaccountmetrics.snowflakecomputing.com:443/queries/v1/query-request?" + requestIDKey + "=testid")
+	assertNilF(t, err)
+	_, err = newRetryHTTP(context.Background(), client, emptyRequest, urlPtr, make(map[string]string),
+		60*time.Second, 3, defaultTimeProvider, &Config{MeterProvider: mp}).doPost().setBody([]byte{0}).execute()
+	assertNilF(t, err)
+
+	attempts := mp.meter.get("snowflake.http.attempts")
+	assertEqualE(t, len(attempts), 2)
+	assertEqualE(t, attributeValue(attempts[0].attrs, endpointAttribute).AsString(), "query")
+
+	retries := mp.meter.get("snowflake.http.retries")
+	assertEqualF(t, len(retries), 1)
+	assertEqualE(t, attributeValue(retries[0].attrs, statusCodeAttribute).AsInt64(), int64(http.StatusServiceUnavailable))
+
+	requests := mp.meter.get("snowflake.http.requests")
+	assertEqualF(t, len(requests), 1)
+	assertEqualE(t, attributeValue(requests[0].attrs, statusCodeAttribute).AsInt64(), int64(http.StatusOK))
+	assertEqualE(t, attributeValue(requests[0].attrs, outcomeAttribute).AsString(), outcomeSuccess)
+}
+
+func TestUnitHeartbeatAndRenewalReportMetrics(t *testing.T) {
+	mp := newRecordingMeterProvider()
+	postMock := func(_ context.Context, _ *snowflakeRestful, fullURL *url.URL, _ map[string]string, _ []byte, _ time.Duration, _ currentTimeProvider, _ *Config) (*http.Response, error) {
+		if fullURL.Path == tokenRequestPath {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(`{"code": "390114", "message": "expired", "success": false}`)),
+			}, nil
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(bytes.NewBufferString(`{"code": null, "success": true}`)),
+		}, nil
+	}
+	sr := &snowflakeRestful{
+		FuncPost:      postMock,
+		TokenAccessor: getSimpleTokenAccessor(),
+		Connection:    &snowflakeConn{cfg: &Config{MeterProvider: mp}},
+	}
+
+	hb := &heartbeat{restful: sr}
+	assertNilF(t, hb.heartbeatMain())
+	assertNotNilF(t, renewRestfulSession(context.Background(), sr, time.Second))
+
+	heartbeats := mp.meter.get("snowflake.session.heartbeats")
+	assertEqualF(t, len(heartbeats), 1)
+	assertEqualE(t, attributeValue(heartbeats[0].attrs, outcomeAttribute).AsString(), outcomeSuccess)
+
+	renewals := mp.meter.get("snowflake.session.renewals")
+	assertEqualF(t, len(renewals), 1)
+	assertEqualE(t, attributeValue(renewals[0].attrs, outcomeAttribute).AsString(), outcomeFailure)
+}
+
+func TestUnitMetricsCachedPerConfig(t *testing.T) {
+	mp := newRecordingMeterProvider()
+	connector := NewConnector(SnowflakeDriver{}, Config{MeterProvider: mp}).(Connector)
+	cfg := connector.cfg
+	m := getMetrics(&cfg)
+	// the connections of a connector share the instruments
+	other := connector.cfg
+	assertTrueE(t, getMetrics(&other) == m)
+
+	// the instruments follow the provider of the config
+	cfg.MeterProvider = newRecordingMeterProvider()
+	assertFalseE(t, getMetrics(&cfg) == m)
+	assertTrueE(t, getMetrics(&cfg) == getMetrics(&cfg))
+
+	// nothing is kept for a config that is not used to connect
+	assertFalseE(t, getMetrics(&Config{MeterProvider: mp}) == getMetrics(&Config{MeterProvider: mp}))
+
+	assertTrueE(t, getMetrics(nil) == getMetrics(&Config{}))
+}
+
+func TestUnitEndpointName(t *testing.T) {
+	testcases := map[string]string{
+		"https://a.snowflakecomputing.com/session/v1/login-request":               "login",
+		"https://a.snowflakecomputing.com/queries/v1/query-request?requestId=1":   "query",
+		"https://a.snowflakecomputing.com/queries/01abc/result":                   "query_result",
+		"https://a.snowflakecomputing.com/monitoring/queries/01abc":               "monitoring",
+		"https://a.snowflakecomputing.com/session/heartbeat":                      "heartbeat",
+		"https://sfc-stage.s3.amazonaws.com/results/01abc/main/data_0_0_0?x-amz=": "other",
+	}
+	for rawURL, expected := range testcases {
+		t.Run(expected, func(t *testing.T) {
+			u, err := url.Parse(rawURL)
+			assertNilF(t, err)
+			assertEqualE(t, endpointName(u), expected)
+		})
+	}
+}
@@ -72,18 +72,38 @@ var strQueryStatusMap = map[string]queryResultStatus{"RUNNING": SFQueryRunning,
 	"BLOCKED":                    SFQueryBlocked, "NO_DATA": SFQueryNoData}
 
 type retStatus struct {
-	Status       string   `json:"status"`
-	SQLText      string   `json:"sqlText"`
-	StartTime    int64    `json:"startTime"`
-	EndTime      int64    `json:"endTime"`
-	ErrorCode    string   `json:"errorCode"`
-	ErrorMessage string   `json:"errorMessage"`
-	Stats        retStats `json:"stats"`
+	ID            string   `json:"id"`
+	Status        string   `json:"status"`
+	SQLText       string   `json:"sqlText"`
+	StartTime     int64    `json:"startTime"`
+	EndTime       int64    `json:"endTime"`
+	TotalDuration int64    `json:"totalDuration"`
+	ErrorCode     string   `json:"errorCode"`
+	ErrorMessage  string   `json:"errorMessage"`
+	SessionID     int64    `json:"sessionId"`
+	WarehouseID   int64    `json:"warehouseId"`
+	WarehouseName string   `json:"warehouseName"`
+	QueryTag      string   `json:"queryTag"`
+	Stats         retStats `json:"stats"`
 }
 
 type retStats struct {
-	ScanBytes    int64 `json:"scanBytes"`
-	ProducedRows int64 `json:"producedRows"`
+	ScanBytes               int64   `json:"scanBytes"`
+	ProducedRows            int64   `json:"producedRows"`
+	CompilationTime         int64   `json:"compilationTime"`
+	ExecutionTime           int64   `json:"executionTime"`
+	QueuedProvisioningTime  int64   `json:"queuedProvisioningTime"`
+	QueuedRepairTime        int64   `json:"queuedRepairTime"`
+	QueuedOverloadTime      int64   `json:"queuedOverloadTime"`
+	TransactionBlockedTime  int64   `json:"transactionBlockedTime"`
+	IOLocalTempWriteBytes   int64   `json:"ioLocalTempWriteBytes"`
+	IORemoteTempWriteBytes  int64   `json:"ioRemoteTempWriteBytes"`
+	ScanAssignedPartitions  int64   `json:"scanAssignedPartitions"`
+	ScanOriginalPartitions  int64   `json:"scanOriginalPartitions"`
+	NumRowsInserted         int64   `json:"numRowsInserted"`
+	NumRowsUpdated          int64   `json:"numRowsUpdated"`
+	NumRowsDeleted          int64   `json:"numRowsDeleted"`
+	ScanPercentageFromCache float64 `json:"scanPercentageFromCache"`
 }
 
 type statusResponse struct {
@@ -99,7 +119,8 @@ func strToQueryStatus(in string) queryResultStatus {
 	return strQueryStatusMap[in]
 }
 
-// SnowflakeQueryStatus is the query status metadata of a snowflake query
+// SnowflakeQueryStatus is the query status metadata of a snowflake query.
+// Times are in milliseconds, and start and end times are since the Unix epoch.
 type SnowflakeQueryStatus struct {
 	SQLText      string
 	StartTime    int64
@@ -108,6 +129,63 @@ type SnowflakeQueryStatus struct {
 	ErrorMessage string
 	ScanBytes    int64
 	ProducedRows int64
+	Status       string // status reported by Snowflake, e.g. RUNNING, SUCCESS or FAILED_WITH_ERROR
+
+	QueryID       string
+	SessionID     int64
+	WarehouseID   int64
+	WarehouseName string
+	QueryTag      string
+
+	TotalDuration          int64 // time from the submission to the end of the query
+	CompilationTime        int64
+	ExecutionTime          int64
+	QueuedProvisioningTime int64 // time spent waiting for the warehouse to be provisioned
+	QueuedRepairTime       int64 // time spent waiting for the warehouse to be repaired
+	QueuedOverloadTime     int64 // time spent waiting because the warehouse was overloaded
+	TransactionBlockedTime int64 // time spent waiting on locks held by other transactions
+
+	BytesSpilledToLocalStorage  int64
+	BytesSpilledToRemoteStorage int64
+	PartitionsScanned           int64
+	PartitionsTotal             int64
+	RowsInserted                int64
+	RowsUpdated                 int64
+	RowsDeleted                 int64
+	CacheHitRatio               float64 // ratio of the scanned bytes read from the warehouse cache, between 0 and 1
+}
+
+func (rs *retStatus) toSnowflakeQueryStatus() *SnowflakeQueryStatus {
+	return &SnowflakeQueryStatus{
+		SQLText:                     rs.SQLText,
+		StartTime:                   rs.StartTime,
+		EndTime:                     rs.EndTime,
+		ErrorCode:                   rs.ErrorCode,
+		ErrorMessage:                rs.ErrorMessage,
+		ScanBytes:                   rs.Stats.ScanBytes,
+		ProducedRows:                rs.Stats.ProducedRows,
+		Status:                      rs.Status,
+		QueryID:                     rs.ID,
+		SessionID:                   rs.SessionID,
+		WarehouseID:                 rs.WarehouseID,
+		WarehouseName:               rs.WarehouseName,
+		QueryTag:                    rs.QueryTag,
+		TotalDuration:               rs.TotalDuration,
+		CompilationTime:             rs.Stats.CompilationTime,
+		ExecutionTime:               rs.Stats.ExecutionTime,
+		QueuedProvisioningTime:      rs.Stats.QueuedProvisioningTime,
+		QueuedRepairTime:            rs.Stats.QueuedRepairTime,
+		QueuedOverloadTime:          rs.Stats.QueuedOverloadTime,
+		TransactionBlockedTime:      rs.Stats.TransactionBlockedTime,
+		BytesSpilledToLocalStorage:  rs.Stats.IOLocalTempWriteBytes,
+		BytesSpilledToRemoteStorage: rs.Stats.IORemoteTempWriteBytes,
+		PartitionsScanned:           rs.Stats.ScanAssignedPartitions,
+		PartitionsTotal:             rs.Stats.ScanOriginalPartitions,
+		RowsInserted:                rs.Stats.NumRowsInserted,
+		RowsUpdated:                 rs.Stats.NumRowsUpdated,
+		RowsDeleted:                 rs.Stats.NumRowsDeleted,
+		CacheHitRatio:               rs.Stats.ScanPercentageFromCache / 100,
+	}
 }
 
 // SnowflakeConnection is a wrapper to snowflakeConn that exposes API functions
@@ -115,16 +193,9 @@ type SnowflakeConnection interface {
 	GetQueryStatus(ctx context.Context, queryID string) (*SnowflakeQueryStatus, error)
 }
 
-// checkQueryStatus returns the status given the query ID. If successful,
-// the error will be nil, indicating there is a complete query result to fetch.
-// Other than nil, there are three error types that can be returned:
-// 1. ErrQueryStatus, if GS cannot return any kind of status due to any reason,
-// i.e. connection, permission, if a query was just submitted, etc.
-// 2, ErrQueryReportedError, if the requested query was terminated or aborted
-// and GS returned an error status included in query. SFQueryFailedWithError
-// 3, ErrQueryIsRunning, if the requested query is still running and might have
-// a complete result later, these statuses were listed in query. SFQueryRunning
-func (sc *snowflakeConn) checkQueryStatus(
+// getQueryStatus fetches the status of a query from the monitoring endpoint. Unlike
+// checkQueryStatus, it does not return an error if the query is running or failed.
+func (sc *snowflakeConn) getQueryStatus(
 	ctx context.Context,
 	qid string) (
 	*retStatus, error) {
@@ -156,10 +227,28 @@ func (sc *snowflakeConn) checkQueryStatus(
 			Message: "status query returned not-success or no status returned. Please retry",
 		}).exceptionTelemetry(sc)
 	}
+	return &statusResp.Data.Queries[0], nil
+}
 
-	queryRet := statusResp.Data.Queries[0]
+// checkQueryStatus returns the status given the query ID. If successful,
+// the error will be nil, indicating there is a complete query result to fetch.
+// Other than nil, there are three error types that can be returned:
+// 1. ErrQueryStatus, if GS cannot return any kind of status due to any reason,
+// i.e. connection, permission, if a query was just submitted, etc.
+// 2, ErrQueryReportedError, if the requested query was terminated or aborted
+// and GS returned an error status included in query. SFQueryFailedWithError
+// 3, ErrQueryIsRunning, if the requested query is still running and might have
+// a complete result later, these statuses were listed in query. SFQueryRunning
+func (sc *snowflakeConn) checkQueryStatus(
+	ctx context.Context,
+	qid string) (
+	*retStatus, error) {
+	queryRet, err := sc.getQueryStatus(ctx, qid)
+	if err != nil {
+		return nil, err
+	}
 	if queryRet.ErrorCode != "" {
-		return &queryRet, (&SnowflakeError{
+		return queryRet, (&SnowflakeError{
 			Number:         ErrQueryStatus,
 			Message:        errMsgQueryStatus,
 			MessageArgs:    []interface{}{queryRet.ErrorCode, queryRet.ErrorMessage},
@@ -171,7 +260,7 @@ func (sc *snowflakeConn) checkQueryStatus(
 	// returned errorCode is 0. Now check what is the returned status of the query.
 	qStatus := strToQueryStatus(queryRet.Status)
 	if qStatus.isError() {
-		return &queryRet, (&SnowflakeError{
+		return queryRet, (&SnowflakeError{
 			Number: ErrQueryReportedError,
 			Message: fmt.Sprintf("%s: status from server: [%s]",
 				queryRet.ErrorMessage, queryRet.Status),
@@ -181,7 +270,7 @@ func (sc *snowflakeConn) checkQueryStatus(
 	}
 
 	if qStatus.isRunning() {
-		return &queryRet, (&SnowflakeError{
+		return queryRet, (&SnowflakeError{
 			Number: ErrQueryIsRunning,
 			Message: fmt.Sprintf("%s: status from server: [%s]",
 				queryRet.ErrorMessage, queryRet.Status),
@@ -190,7 +279,7 @@ func (sc *snowflakeConn) checkQueryStatus(
 		}).exceptionTelemetry(sc)
 	}
 	//success
-	return &queryRet, nil
+	return queryRet, nil
 }
 
 func (sc *snowflakeConn) getQueryResultResp(
@@ -221,29 +310,46 @@ func (sc *snowflakeConn) getQueryResultResp(
 	return respd, nil
 }
 
-// Fetch query result for a query id from /queries/<qid>/result endpoint.
-func (sc *snowflakeConn) rowsForRunningQuery(
-	ctx context.Context, qid string,
-	rows *snowflakeRows) error {
+// waitForQueryResult waits for a query to complete and returns its result from the
+// /queries/<qid>/result endpoint.
+func (sc *snowflakeConn) waitForQueryResult(
+	ctx context.Context,
+	qid string) (
+	*execResponse, error) {
 	resultPath := fmt.Sprintf(urlQueriesResultFmt, qid)
 	resp, err := sc.getQueryResultResp(ctx, resultPath)
 	if err != nil {
 		logger.WithContext(ctx).Errorf("error: %v", err)
-		return err
+		return nil, err
 	}
 
 	if !resp.Success {
 		code, err := strconv.Atoi(resp.Code)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		return (&SnowflakeError{
+		return nil, (&SnowflakeError{
 			Number:   code,
 			SQLState: resp.Data.SQLState,
 			Message:  resp.Message,
 			QueryID:  resp.Data.QueryID,
 		}).exceptionTelemetry(sc)
 	}
+	return resp, nil
+}
+
+// Fetch query result for a query id from /queries/<qid>/result endpoint.
+func (sc *snowflakeConn) rowsForRunningQuery(
+	ctx context.Context, qid string,
+	rows *snowflakeRows) error {
+	resp, err := sc.waitForQueryResult(ctx, qid)
+	if err != nil {
+		return err
+	}
+	rows.format = resultFormat(resp.Data.QueryResultFormat)
+	if rows.stats == nil {
+		rows.stats = newQueryStats(&resp.Data)
+	}
 	rows.addDownloader(populateChunkDownloader(ctx, sc, resp.Data))
 	return nil
 }
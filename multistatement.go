@@ -39,6 +39,7 @@ func (sc *snowflakeConn) handleMultiExec(
 		}).exceptionTelemetry(sc)
 	}
 	var updatedRows int64
+	stats := newQueryStats(&data)
 	childResults := getChildResults(data.ResultIDs, data.ResultTypes)
 	for _, child := range childResults {
 		resultPath := fmt.Sprintf(urlQueriesResultFmt, child.id)
@@ -50,6 +51,7 @@ func (sc *snowflakeConn) handleMultiExec(
 			childData, err := sc.getQueryResultResp(ctx, resultPath)
 			if err != nil {
 				logger.WithContext(ctx).Errorf("error: %v", err)
+				sc.afterChildExecute(ctx, data.QueryID, child.id, -1, err)
 				return nil, err
 			}
 			if childData != nil && !childData.Success {
@@ -57,12 +59,14 @@ func (sc *snowflakeConn) handleMultiExec(
 				if err != nil {
 					return nil, err
 				}
-				return nil, (&SnowflakeError{
+				err = (&SnowflakeError{
 					Number:   code,
 					SQLState: childData.Data.SQLState,
 					Message:  childData.Message,
 					QueryID:  childData.Data.QueryID,
 				}).exceptionTelemetry(sc)
+				sc.afterChildExecute(ctx, data.QueryID, child.id, -1, err)
+				return nil, err
 			}
 			count, err := updateRows(childData.Data)
 			if err != nil {
@@ -70,6 +74,10 @@ func (sc *snowflakeConn) handleMultiExec(
 				return nil, err
 			}
 			updatedRows += count
+			stats.add(newQueryStats(&childData.Data))
+			sc.afterChildExecute(ctx, data.QueryID, child.id, count, nil)
+		} else {
+			sc.afterChildExecute(ctx, data.QueryID, child.id, -1, nil)
 		}
 	}
 	logger.WithContext(ctx).Infof("number of updated rows: %#v", updatedRows)
@@ -77,6 +85,7 @@ func (sc *snowflakeConn) handleMultiExec(
 		affectedRows: updatedRows,
 		insertID:     -1,
 		queryID:      data.QueryID,
+		stats:        stats,
 	}, nil
 }
 
@@ -96,7 +105,9 @@ func (sc *snowflakeConn) handleMultiQuery(
 	}
 	childResults := getChildResults(data.ResultIDs, data.ResultTypes)
 	for _, child := range childResults {
-		if err := sc.rowsForRunningQuery(ctx, child.id, rows); err != nil {
+		err := sc.rowsForRunningQuery(ctx, child.id, rows)
+		sc.afterChildExecute(ctx, data.QueryID, child.id, -1, err)
+		if err != nil {
 			return err
 		}
 	}
@@ -0,0 +1,212 @@
+package gosnowflake
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"hash"
+	"os"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const pemTypeEncryptedPrivateKey = "ENCRYPTED PRIVATE KEY"
+
+var (
+	oidPBES2  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+
+	oidHMACWithSHA1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACWithSHA224 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 8}
+	oidHMACWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidHMACWithSHA384 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 10}
+	oidHMACWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+
+	oidAES128CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidDESEDE3CBC = asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7}
+)
+
+// encryptedPrivateKeyInfo is the EncryptedPrivateKeyInfo of PKCS #8 (RFC 5958).
+type encryptedPrivateKeyInfo struct {
+	EncryptionAlgorithm pkix.AlgorithmIdentifier
+	EncryptedData       []byte
+}
+
+// pbes2Params are the PBES2-params of PKCS #5 (RFC 8018).
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+// pbkdf2Params are the PBKDF2-params of PKCS #5 (RFC 8018).
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                      `asn1:"optional"`
+	PRF            pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// loadPrivateKeyFile reads Config.PrivateKey from Config.PrivateKeyFile, decrypting it
+// with the passphrase of the config if the file is encrypted.
+func (c *Config) loadPrivateKeyFile() error {
+	if c.PrivateKeyFile == "" || c.PrivateKey != nil {
+		return nil
+	}
+	passphrase := c.PrivateKeyFilePwd
+	if passphrase == "" && c.PrivateKeyFilePwdEnv != "" {
+		var ok bool
+		if passphrase, ok = os.LookupEnv(c.PrivateKeyFilePwdEnv); !ok {
+			return errPrivateKeyPassphraseEnvNotSet(c.PrivateKeyFilePwdEnv)
+		}
+	}
+	privateKey, err := parseEncryptedPrivateKeyFromFile(c.PrivateKeyFile, passphrase)
+	if err != nil {
+		return err
+	}
+	c.PrivateKey = privateKey
+	return nil
+}
+
+func parsePrivateKeyFromFile(path string) (*rsa.PrivateKey, error) {
+	return parseEncryptedPrivateKeyFromFile(path, "")
+}
+
+// parseEncryptedPrivateKeyFromFile reads a PKCS #8 PEM RSA private key, either unencrypted
+// or encrypted with PBES2, as written by "openssl pkcs8 -topk8 -v2 aes256". The passphrase
+// is ignored if the key is not encrypted.
+func parseEncryptedPrivateKeyFromFile(path string, passphrase string) (*rsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errPrivateKeyParse("failed to parse PEM block containing the private key")
+	}
+	der := block.Bytes
+	switch {
+	case block.Type == pemTypeEncryptedPrivateKey:
+		if passphrase == "" {
+			return nil, errPrivateKeyPassphraseRequired()
+		}
+		if der, err = decryptPKCS8PrivateKey(der, []byte(passphrase)); err != nil {
+			return nil, err
+		}
+	case block.Headers["Proc-Type"] != "":
+		// legacy OpenSSL encryption, e.g. "openssl genrsa -aes256"
+		return nil, errUnsupportedPrivateKeyFormat("PEM encryption of %v blocks is not supported. convert the key to encrypted PKCS #8 with \"openssl pkcs8 -topk8 -v2 aes256\"", block.Type)
+	}
+	privateKey, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		if block.Type == pemTypeEncryptedPrivateKey {
+			// the padding was correct by chance, but the key was not decrypted
+			return nil, errInvalidPrivateKeyPassphrase()
+		}
+		return nil, errPrivateKeyParse("failed to parse the PKCS #8 private key: " + err.Error())
+	}
+	pk, ok := privateKey.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errUnsupportedPrivateKeyFormat("interface convertion. expected type *rsa.PrivateKey, but got %T", privateKey)
+	}
+	return pk, nil
+}
+
+// decryptPKCS8PrivateKey decrypts the DER of an EncryptedPrivateKeyInfo encrypted with
+// PBES2, using PBKDF2 and AES or 3DES in CBC mode.
+func decryptPKCS8PrivateKey(der []byte, passphrase []byte) ([]byte, error) {
+	var info encryptedPrivateKeyInfo
+	if _, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, errPrivateKeyParse("failed to parse the encrypted PKCS #8 private key: " + err.Error())
+	}
+	if !info.EncryptionAlgorithm.Algorithm.Equal(oidPBES2) {
+		return nil, errUnsupportedPrivateKeyFormat("encryption algorithm %v is not supported. only PBES2 is", info.EncryptionAlgorithm.Algorithm)
+	}
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(info.EncryptionAlgorithm.Parameters.FullBytes, &params); err != nil {
+		return nil, errPrivateKeyParse("failed to parse the PBES2 parameters: " + err.Error())
+	}
+	if !params.KeyDerivationFunc.Algorithm.Equal(oidPBKDF2) {
+		return nil, errUnsupportedPrivateKeyFormat("key derivation function %v is not supported. only PBKDF2 is", params.KeyDerivationFunc.Algorithm)
+	}
+	var kdfParams pbkdf2Params
+	if _, err := asn1.Unmarshal(params.KeyDerivationFunc.Parameters.FullBytes, &kdfParams); err != nil {
+		return nil, errPrivateKeyParse("failed to parse the PBKDF2 parameters: " + err.Error())
+	}
+	prf, err := pbkdf2PRF(kdfParams.PRF.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	newCipher, keyLength, err := pbes2Cipher(params.EncryptionScheme.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	if kdfParams.KeyLength != 0 && kdfParams.KeyLength != keyLength {
+		return nil, errPrivateKeyParse(fmt.Sprintf("PBKDF2 key length %v does not match the cipher", kdfParams.KeyLength))
+	}
+	var iv []byte
+	if _, err = asn1.Unmarshal(params.EncryptionScheme.Parameters.FullBytes, &iv); err != nil {
+		return nil, errPrivateKeyParse("failed to parse the cipher IV: " + err.Error())
+	}
+
+	key := pbkdf2.Key(passphrase, kdfParams.Salt, kdfParams.IterationCount, keyLength, prf)
+	block, err := newCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != block.BlockSize() || len(info.EncryptedData) == 0 || len(info.EncryptedData)%block.BlockSize() != 0 {
+		return nil, errPrivateKeyParse("the encrypted private key is corrupted")
+	}
+	decrypted := make([]byte, len(info.EncryptedData))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(decrypted, info.EncryptedData)
+	// a wrong passphrase is detected by the PKCS #7 padding it leaves
+	padding := int(decrypted[len(decrypted)-1])
+	if padding == 0 || padding > block.BlockSize() ||
+		!bytes.Equal(decrypted[len(decrypted)-padding:], bytes.Repeat([]byte{byte(padding)}, padding)) {
+		return nil, errInvalidPrivateKeyPassphrase()
+	}
+	return decrypted[:len(decrypted)-padding], nil
+}
+
+// pbkdf2PRF returns the hash of the HMAC used by PBKDF2, HMAC-SHA1 if not specified.
+func pbkdf2PRF(oid asn1.ObjectIdentifier) (func() hash.Hash, error) {
+	switch {
+	case len(oid) == 0, oid.Equal(oidHMACWithSHA1):
+		return sha1.New, nil
+	case oid.Equal(oidHMACWithSHA224):
+		return sha256.New224, nil
+	case oid.Equal(oidHMACWithSHA256):
+		return sha256.New, nil
+	case oid.Equal(oidHMACWithSHA384):
+		return sha512.New384, nil
+	case oid.Equal(oidHMACWithSHA512):
+		return sha512.New, nil
+	}
+	return nil, errUnsupportedPrivateKeyFormat("PBKDF2 pseudorandom function %v is not supported", oid)
+}
+
+// pbes2Cipher returns the block cipher of the PBES2 encryption scheme and its key length.
+func pbes2Cipher(oid asn1.ObjectIdentifier) (func(key []byte) (cipher.Block, error), int, error) {
+	switch {
+	case oid.Equal(oidAES128CBC):
+		return aes.NewCipher, 16, nil
+	case oid.Equal(oidAES192CBC):
+		return aes.NewCipher, 24, nil
+	case oid.Equal(oidAES256CBC):
+		return aes.NewCipher, 32, nil
+	case oid.Equal(oidDESEDE3CBC):
+		return des.NewTripleDESCipher, 24, nil
+	}
+	return nil, 0, errUnsupportedPrivateKeyFormat("PBES2 encryption scheme %v is not supported. use AES-CBC or DES-EDE3-CBC", oid)
+}
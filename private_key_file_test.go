@@ -0,0 +1,120 @@
+package gosnowflake
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testPrivateKeyFilePwd = "test"
+
+func testPrivateKeyFile(name string) string {
+	return filepath.Join("test_data", "private_keys", name)
+}
+
+func TestParseEncryptedPrivateKeyFromFile(t *testing.T) {
+	expected, err := parsePrivateKeyFromFile(testPrivateKeyFile("rsa_key.p8"))
+	assertNilF(t, err)
+
+	for _, name := range []string{"rsa_key_aes256.p8", "rsa_key_aes128.p8", "rsa_key_des3.p8"} {
+		t.Run(name, func(t *testing.T) {
+			key, err := parseEncryptedPrivateKeyFromFile(testPrivateKeyFile(name), testPrivateKeyFilePwd)
+			assertNilF(t, err)
+			assertTrueE(t, expected.Equal(key))
+
+			_, err = parseEncryptedPrivateKeyFromFile(testPrivateKeyFile(name), "wrong passphrase")
+			assertNotNilF(t, err)
+			assertEqualE(t, err.(*SnowflakeError).Number, ErrCodeInvalidPrivateKeyPassphrase)
+
+			_, err = parsePrivateKeyFromFile(testPrivateKeyFile(name))
+			assertNotNilF(t, err)
+			assertEqualE(t, err.(*SnowflakeError).Number, ErrCodeInvalidPrivateKeyPassphrase)
+		})
+	}
+
+	// the passphrase is ignored for an unencrypted key
+	key, err := parseEncryptedPrivateKeyFromFile(testPrivateKeyFile("rsa_key.p8"), testPrivateKeyFilePwd)
+	assertNilF(t, err)
+	assertTrueE(t, expected.Equal(key))
+}
+
+func TestParseEncryptedPrivateKeyFromFileUnsupportedFormat(t *testing.T) {
+	for _, name := range []string{"rsa_key_scrypt.p8", "rsa_key_pbes1.p8", "rsa_key_legacy.pem"} {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseEncryptedPrivateKeyFromFile(testPrivateKeyFile(name), testPrivateKeyFilePwd)
+			assertNotNilF(t, err)
+			assertEqualE(t, err.(*SnowflakeError).Number, ErrCodeUnsupportedPrivateKeyFormat)
+		})
+	}
+}
+
+func TestPrivateKeyFileInDSN(t *testing.T) {
+	expected, err := parsePrivateKeyFromFile(testPrivateKeyFile("rsa_key.p8"))
+	assertNilF(t, err)
+	keyFile := url.QueryEscape(testPrivateKeyFile("rsa_key_aes256.p8"))
+
+	cfg, err := ParseDSN("u@a.snowflakecomputing.com/db?authenticator=snowflake_jwt&privateKeyFile=" + keyFile + "&privateKeyFilePwd=" + testPrivateKeyFilePwd)
+	assertNilF(t, err)
+	assertTrueE(t, expected.Equal(cfg.PrivateKey))
+
+	// the DSN references the file instead of holding the decrypted key
+	dsn, err := DSN(cfg)
+	assertNilF(t, err)
+	assertStringContainsE(t, dsn, "privateKeyFile="+keyFile)
+	assertFalseE(t, strings.Contains(dsn, "privateKey="))
+	cfg, err = ParseDSN(dsn)
+	assertNilF(t, err)
+	assertTrueE(t, expected.Equal(cfg.PrivateKey))
+
+	t.Setenv("SF_TEST_PRIVATE_KEY_FILE_PWD", testPrivateKeyFilePwd)
+	cfg, err = ParseDSN("u@a.snowflakecomputing.com/db?authenticator=snowflake_jwt&privateKeyFile=" + keyFile + "&privateKeyFilePwdEnv=SF_TEST_PRIVATE_KEY_FILE_PWD")
+	assertNilF(t, err)
+	assertTrueE(t, expected.Equal(cfg.PrivateKey))
+
+	_, err = ParseDSN("u@a.snowflakecomputing.com/db?authenticator=snowflake_jwt&privateKeyFile=" + keyFile + "&privateKeyFilePwdEnv=SF_TEST_UNSET")
+	assertNotNilF(t, err)
+	assertEqualE(t, err.(*SnowflakeError).Number, ErrCodeInvalidPrivateKeyPassphrase)
+
+	_, err = ParseDSN("u@a.snowflakecomputing.com/db?authenticator=snowflake_jwt&privateKeyFile=" + keyFile + "&privateKeyFilePwd=wrong")
+	assertNotNilF(t, err)
+	assertEqualE(t, err.(*SnowflakeError).Number, ErrCodeInvalidPrivateKeyPassphrase)
+}
+
+func TestPrivateKeyFileInToml(t *testing.T) {
+	expected, err := parsePrivateKeyFromFile(testPrivateKeyFile("rsa_key.p8"))
+	assertNilF(t, err)
+
+	cfg := &Config{Account: "a", User: "u", Authenticator: AuthTypeJwt}
+	assertNilF(t, parseToml(cfg, map[string]interface{}{
+		"private_key_file":     testPrivateKeyFile("rsa_key_des3.p8"),
+		"private_key_file_pwd": testPrivateKeyFilePwd,
+	}))
+	assertNilF(t, fillMissingConfigParameters(cfg))
+	assertTrueE(t, expected.Equal(cfg.PrivateKey))
+
+	cfg = &Config{Account: "a", User: "u", Authenticator: AuthTypeJwt}
+	assertNilF(t, parseToml(cfg, map[string]interface{}{"privatekeyfile": testPrivateKeyFile("rsa_key_legacy.pem")}))
+	err = fillMissingConfigParameters(cfg)
+	assertNotNilF(t, err)
+	assertEqualE(t, err.(*SnowflakeError).Number, ErrCodeUnsupportedPrivateKeyFormat)
+}
+
+func TestPrivateKeyFileFromEnv(t *testing.T) {
+	expected, err := parsePrivateKeyFromFile(testPrivateKeyFile("rsa_key.p8"))
+	assertNilF(t, err)
+	t.Setenv("SF_TEST_PRIVATE_KEY_FILE_PWD", testPrivateKeyFilePwd)
+	t.Setenv("SF_TEST_PRIVATE_KEY", testPrivateKeyFile("rsa_key_aes256.p8"))
+
+	// the passphrase may come after the file
+	cfg, err := GetConfigFromEnv([]*ConfigParam{
+		{Name: "PrivateKey", EnvName: "SF_TEST_PRIVATE_KEY", FailOnMissing: true},
+		{Name: "PrivateKeyFilePwd", EnvName: "SF_TEST_PRIVATE_KEY_FILE_PWD", FailOnMissing: true},
+	})
+	assertNilF(t, err)
+	assertTrueE(t, expected.Equal(cfg.PrivateKey))
+
+	_, err = GetConfigFromEnv([]*ConfigParam{{Name: "PrivateKey", EnvName: "SF_TEST_PRIVATE_KEY", FailOnMissing: true}})
+	assertNotNilF(t, err)
+	assertEqualE(t, err.(*SnowflakeError).Number, ErrCodeInvalidPrivateKeyPassphrase)
+}
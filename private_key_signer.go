@@ -0,0 +1,211 @@
+package gosnowflake
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var privateKeySigners = struct {
+	sync.RWMutex
+	signers map[string]crypto.Signer
+}{signers: make(map[string]crypto.Signer)}
+
+// lastPrivateKeys holds the fingerprint of the private key of the last successful key pair
+// login of each account and user, so that new connections start with the key that works.
+var lastPrivateKeys = struct {
+	sync.RWMutex
+	fingerprints map[string]string
+}{fingerprints: make(map[string]string)}
+
+func lastPrivateKeyName(cfg *Config) string {
+	return extractAccountName(cfg.Account) + "." + strings.ToUpper(cfg.User)
+}
+
+func getLastPrivateKey(cfg *Config) string {
+	lastPrivateKeys.RLock()
+	defer lastPrivateKeys.RUnlock()
+	return lastPrivateKeys.fingerprints[lastPrivateKeyName(cfg)]
+}
+
+func setLastPrivateKey(cfg *Config, fingerprint string) {
+	lastPrivateKeys.Lock()
+	defer lastPrivateKeys.Unlock()
+	lastPrivateKeys.fingerprints[lastPrivateKeyName(cfg)] = fingerprint
+}
+
+// RegisterPrivateKeySigner registers a signer, e.g. backed by a KMS or an HSM, that signs
+// the JWT of key pair authentication without the private key being loaded in memory. A
+// DSN or connections.toml references it by name with the privateKeySigner parameter. The
+// public key of the signer must be an RSA key.
+func RegisterPrivateKeySigner(name string, signer crypto.Signer) error {
+	if name == "" {
+		return errors.New("private key signer name is empty")
+	}
+	if err := validatePrivateKeySigner(signer); err != nil {
+		return err
+	}
+	privateKeySigners.Lock()
+	defer privateKeySigners.Unlock()
+	privateKeySigners.signers[name] = signer
+	return nil
+}
+
+// DeregisterPrivateKeySigner removes the signer registered with the name.
+func DeregisterPrivateKeySigner(name string) {
+	privateKeySigners.Lock()
+	defer privateKeySigners.Unlock()
+	delete(privateKeySigners.signers, name)
+}
+
+func getPrivateKeySigner(name string) (crypto.Signer, error) {
+	privateKeySigners.RLock()
+	defer privateKeySigners.RUnlock()
+	signer, ok := privateKeySigners.signers[name]
+	if !ok {
+		return nil, errUnknownPrivateKeySigner(name)
+	}
+	return signer, nil
+}
+
+// getPrivateKeySignerName returns the name the signer was registered with.
+func getPrivateKeySignerName(signer crypto.Signer) (string, bool) {
+	if !reflect.TypeOf(signer).Comparable() {
+		return "", false
+	}
+	privateKeySigners.RLock()
+	defer privateKeySigners.RUnlock()
+	for name, registered := range privateKeySigners.signers {
+		if reflect.TypeOf(registered).Comparable() && registered == signer {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func validatePrivateKeySigner(signer crypto.Signer) error {
+	if signer == nil {
+		return errors.New("private key signer is nil")
+	}
+	if _, ok := signer.Public().(*rsa.PublicKey); !ok {
+		return fmt.Errorf("the public key of the private key signer must be an RSA key, but got %T", signer.Public())
+	}
+	return nil
+}
+
+// getJWTSigner returns Config.PrivateKeySigner if set, or Config.PrivateKey.
+func (c *Config) getJWTSigner() (crypto.Signer, error) {
+	if c.PrivateKeySigner != nil {
+		return c.PrivateKeySigner, validatePrivateKeySigner(c.PrivateKeySigner)
+	}
+	if c.PrivateKey != nil {
+		return c.PrivateKey, nil
+	}
+	return nil, errors.New("trying to use keypair authentication, but neither PrivateKey nor PrivateKeySigner was provided in the driver config")
+}
+
+// getJWTSigners returns the keys of Config.PrivateKeySource if set, or the single key of
+// the config.
+func (c *Config) getJWTSigners(ctx context.Context) ([]crypto.Signer, error) {
+	if c.PrivateKeySource == nil {
+		signer, err := c.getJWTSigner()
+		if err != nil {
+			return nil, err
+		}
+		return []crypto.Signer{signer}, nil
+	}
+	signers, err := c.PrivateKeySource(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(signers) == 0 {
+		return nil, errors.New("trying to use keypair authentication, but PrivateKeySource returned no key")
+	}
+	for _, signer := range signers {
+		if err = validatePrivateKeySigner(signer); err != nil {
+			return nil, err
+		}
+	}
+	return signers, nil
+}
+
+// PrivateKeys returns a Config.PrivateKeySource with the keys, tried in order.
+func PrivateKeys(keys ...crypto.Signer) func(context.Context) ([]crypto.Signer, error) {
+	return func(context.Context) ([]crypto.Signer, error) {
+		return keys, nil
+	}
+}
+
+// PrivateKeysFromFiles returns a Config.PrivateKeySource reading the unencrypted PKCS #8
+// PEM keys from the files at each login, tried in order. Keys rotated on disk are picked
+// up without a restart. A missing file is skipped, so that a key can be removed once it
+// is no longer used.
+func PrivateKeysFromFiles(paths ...string) func(context.Context) ([]crypto.Signer, error) {
+	return EncryptedPrivateKeysFromFiles("", paths...)
+}
+
+// EncryptedPrivateKeysFromFiles is like PrivateKeysFromFiles, but the PKCS #8 PEM keys
+// may be encrypted with the passphrase, as for Config.PrivateKeyFile. Unencrypted keys
+// are read as well.
+func EncryptedPrivateKeysFromFiles(passphrase string, paths ...string) func(context.Context) ([]crypto.Signer, error) {
+	return func(context.Context) ([]crypto.Signer, error) {
+		var keys []crypto.Signer
+		for _, path := range paths {
+			key, err := parseEncryptedPrivateKeyFromFile(path, passphrase)
+			if errors.Is(err, os.ErrNotExist) {
+				logger.Warnf("private key file %v does not exist. skipping it", path)
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to read the private key from %v: %w", path, err)
+			}
+			keys = append(keys, key)
+		}
+		return keys, nil
+	}
+}
+
+// publicKeyFingerprint returns the fingerprint Snowflake shows for a public key, e.g. in
+// the RSA_PUBLIC_KEY_FP property of a user.
+func publicKeyFingerprint(publicKey crypto.PublicKey) (string, error) {
+	pubBytes, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(pubBytes)
+	return "SHA256:" + base64.StdEncoding.EncodeToString(hash[:]), nil
+}
+
+// signerSigningMethodRS256 signs a JWT with RS256 using a crypto.Signer, which unlike
+// jwt.SigningMethodRS256 does not need the RSA private key.
+type signerSigningMethodRS256 struct{}
+
+func (signerSigningMethodRS256) Alg() string {
+	return jwt.SigningMethodRS256.Alg()
+}
+
+func (signerSigningMethodRS256) Sign(signingString string, key interface{}) ([]byte, error) {
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, jwt.ErrInvalidKeyType
+	}
+	digest := sha256.Sum256([]byte(signingString))
+	// an RSA signer uses PKCS #1 v1.5 when the options are a crypto.Hash
+	return signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+}
+
+func (signerSigningMethodRS256) Verify(signingString string, sig []byte, key interface{}) error {
+	return jwt.SigningMethodRS256.Verify(signingString, sig, key)
+}
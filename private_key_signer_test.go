@@ -0,0 +1,227 @@
+package gosnowflake
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testRemoteSigner signs like a KMS or an HSM would, without exposing the private key.
+type testRemoteSigner struct {
+	key   crypto.Signer
+	signs int
+}
+
+func (s *testRemoteSigner) Public() crypto.PublicKey {
+	return s.key.Public()
+}
+
+func (s *testRemoteSigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	s.signs++
+	return s.key.Sign(rand, digest, opts)
+}
+
+func TestUnitAuthenticateJWTWithPrivateKeySigner(t *testing.T) {
+	signer := &testRemoteSigner{key: testPrivKey}
+	sc := getDefaultSnowflakeConn()
+	sc.cfg.Authenticator = AuthTypeJwt
+	sc.cfg.JWTExpireTimeout = defaultJWTTimeout
+	sc.cfg.PrivateKeySigner = signer
+	sc.rest.FuncPostAuth = postAuthCheckJWTToken
+
+	_, err := authenticate(context.Background(), sc, []byte{}, []byte{})
+	assertNilF(t, err)
+	assertEqualE(t, signer.signs, 1)
+
+	// the signer is used even if a private key is set
+	sc.cfg.PrivateKey = testPrivKey
+	_, err = authenticate(context.Background(), sc, []byte{}, []byte{})
+	assertNilF(t, err)
+	assertEqualE(t, signer.signs, 2)
+}
+
+func TestUnitPrivateKeySignerMustBeRSA(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assertNilF(t, err)
+	assertNotNilE(t, RegisterPrivateKeySigner("ec", ecKey))
+	assertNotNilE(t, RegisterPrivateKeySigner("", testPrivKey))
+
+	_, err = prepareJWTToken(&Config{Account: "a", User: "u", PrivateKeySigner: ecKey})
+	assertNotNilE(t, err)
+	_, err = prepareJWTToken(&Config{Account: "a", User: "u"})
+	assertNotNilE(t, err)
+}
+
+func TestUnitPrivateKeySignerInDSN(t *testing.T) {
+	signer := &testRemoteSigner{key: testPrivKey}
+	assertNilF(t, RegisterPrivateKeySigner("kms-key", signer))
+	defer DeregisterPrivateKeySigner("kms-key")
+
+	cfg, err := ParseDSN("u@a.snowflakecomputing.com/db?authenticator=snowflake_jwt&privateKeySigner=kms-key")
+	assertNilF(t, err)
+	assertEqualE(t, cfg.PrivateKeySigner, crypto.Signer(signer))
+
+	dsn, err := DSN(cfg)
+	assertNilF(t, err)
+	assertStringContainsE(t, dsn, "privateKeySigner=kms-key")
+
+	tomlCfg := &Config{}
+	assertNilF(t, handleSingleParam(tomlCfg, "privatekeysigner", "kms-key"))
+	assertEqualE(t, tomlCfg.PrivateKeySigner, crypto.Signer(signer))
+
+	_, err = ParseDSN("u@a.snowflakecomputing.com/db?authenticator=snowflake_jwt&privateKeySigner=unknown")
+	assertNotNilF(t, err)
+	assertEqualE(t, err.(*SnowflakeError).Number, ErrCodeUnknownPrivateKeySigner)
+
+	// a signer must be registered to be referenced in a DSN
+	cfg.PrivateKeySigner = &testRemoteSigner{key: testPrivKey}
+	_, err = DSN(cfg)
+	assertNotNilF(t, err)
+	assertEqualE(t, err.(*SnowflakeError).Number, ErrCodeUnknownPrivateKeySigner)
+}
+
+// postAuthAcceptingKey accepts the JWTs signed with the key, and rejects the others as
+// Snowflake does.
+func postAuthAcceptingKey(key *rsa.PrivateKey, fingerprints *[]string) func(context.Context, *snowflakeRestful, *http.Client, *url.Values, map[string]string, bodyCreatorType, time.Duration) (*authResponse, error) {
+	return func(_ context.Context, _ *snowflakeRestful, _ *http.Client, _ *url.Values, _ map[string]string, bodyCreator bodyCreatorType, _ time.Duration) (*authResponse, error) {
+		body, err := bodyCreator()
+		if err != nil {
+			return nil, err
+		}
+		var ar authRequest
+		if err = json.Unmarshal(body, &ar); err != nil {
+			return nil, err
+		}
+		claims := jwt.MapClaims{}
+		if _, _, err = jwt.NewParser().ParseUnverified(ar.Data.Token, claims); err != nil {
+			return nil, err
+		}
+		*fingerprints = append(*fingerprints, claims["iss"].(string))
+		if _, err = jwt.Parse(ar.Data.Token, func(*jwt.Token) (interface{}, error) { return key.Public(), nil }); err != nil {
+			return &authResponse{Success: false, Code: strconv.Itoa(invalidJWTTokenNumber), Message: "JWT token is invalid."}, nil
+		}
+		return &authResponse{Success: true, Data: authResponseMain{Token: "t", MasterToken: "m"}}, nil
+	}
+}
+
+func TestUnitPrivateKeyRotation(t *testing.T) {
+	oldKeys := make([]crypto.Signer, 3)
+	for i := range oldKeys {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		assertNilF(t, err)
+		oldKeys[i] = key
+	}
+	newKey := testPrivKey
+	oldFingerprint, err := publicKeyFingerprint(oldKeys[0].Public())
+	assertNilF(t, err)
+	newFingerprint, err := publicKeyFingerprint(newKey.Public())
+	assertNilF(t, err)
+
+	var fingerprints []string
+	newConn := func() *snowflakeConn {
+		sc := getDefaultSnowflakeConn()
+		sc.ctx = context.Background()
+		sc.cfg.User = "key_rotation_user"
+		sc.cfg.Authenticator = AuthTypeJwt
+		sc.cfg.JWTExpireTimeout = defaultJWTTimeout
+		sc.cfg.PrivateKeySource = PrivateKeys(append(oldKeys, newKey)...)
+		sc.rest.FuncPostAuth = postAuthAcceptingKey(newKey, &fingerprints)
+		return sc
+	}
+	sc := newConn()
+	t.Cleanup(func() { setLastPrivateKey(sc.cfg, "") })
+
+	// the old keys are no longer accepted, and each key is tried
+	assertNilF(t, authenticateWithConfig(sc))
+	assertEqualF(t, len(fingerprints), 4)
+	assertStringContainsE(t, fingerprints[0], oldFingerprint)
+	assertStringContainsE(t, fingerprints[3], newFingerprint)
+	assertEqualE(t, sc.privateKeyFingerprint, newFingerprint)
+
+	// the connection keeps using the key that worked
+	fingerprints = nil
+	assertNilF(t, authenticateWithConfig(sc))
+	assertEqualF(t, len(fingerprints), 1)
+	assertStringContainsE(t, fingerprints[0], newFingerprint)
+
+	// so do new connections of the same account and user
+	fingerprints = nil
+	assertNilF(t, authenticateWithConfig(newConn()))
+	assertEqualF(t, len(fingerprints), 1)
+	assertStringContainsE(t, fingerprints[0], newFingerprint)
+
+	// no key is accepted
+	fingerprints = nil
+	sc.cfg.PrivateKeySource = PrivateKeys(oldKeys...)
+	err = authenticateWithConfig(sc)
+	assertNotNilF(t, err)
+	assertEqualE(t, err.(*SnowflakeError).Number, invalidJWTTokenNumber)
+	assertEqualE(t, len(fingerprints), 3)
+}
+
+func TestUnitPrivateKeysFromFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeKey := func(name string, key *rsa.PrivateKey) string {
+		keyBytes, err := x509.MarshalPKCS8PrivateKey(key)
+		assertNilF(t, err)
+		path := filepath.Join(dir, name)
+		assertNilF(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyBytes}), 0600))
+		return path
+	}
+	secondKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assertNilF(t, err)
+	source := PrivateKeysFromFiles(writeKey("first.p8", testPrivKey), filepath.Join(dir, "missing.p8"), writeKey("second.p8", secondKey))
+
+	keys, err := source(context.Background())
+	assertNilF(t, err)
+	assertEqualF(t, len(keys), 2)
+	assertTrueE(t, testPrivKey.Equal(keys[0]))
+	assertTrueE(t, secondKey.Equal(keys[1]))
+
+	// the keys are read again, e.g. after a rotation
+	writeKey("first.p8", secondKey)
+	keys, err = source(context.Background())
+	assertNilF(t, err)
+	assertTrueE(t, secondKey.Equal(keys[0]))
+
+	_, err = (&Config{PrivateKeySource: PrivateKeysFromFiles(filepath.Join(dir, "missing.p8"))}).getJWTSigners(context.Background())
+	assertNotNilE(t, err)
+}
+
+func TestUnitEncryptedPrivateKeysFromFiles(t *testing.T) {
+	expected, err := parsePrivateKeyFromFile(testPrivateKeyFile("rsa_key.p8"))
+	assertNilF(t, err)
+	paths := []string{testPrivateKeyFile("rsa_key_aes256.p8"), testPrivateKeyFile("missing.p8"), testPrivateKeyFile("rsa_key.p8")}
+
+	keys, err := EncryptedPrivateKeysFromFiles(testPrivateKeyFilePwd, paths...)(context.Background())
+	assertNilF(t, err)
+	assertEqualF(t, len(keys), 2)
+	assertTrueE(t, expected.Equal(keys[0]))
+	assertTrueE(t, expected.Equal(keys[1]))
+
+	_, err = EncryptedPrivateKeysFromFiles("wrong passphrase", paths...)(context.Background())
+	assertNotNilF(t, err)
+	var se *SnowflakeError
+	assertTrueF(t, errors.As(err, &se))
+	assertEqualE(t, se.Number, ErrCodeInvalidPrivateKeyPassphrase)
+
+	_, err = PrivateKeysFromFiles(paths...)(context.Background())
+	assertNotNilE(t, err)
+}
@@ -47,14 +47,15 @@ type contextData struct {
 }
 
 type execResponseRowType struct {
-	Name       string          `json:"name"`
-	Fields     []fieldMetadata `json:"fields"`
-	ByteLength int64           `json:"byteLength"`
-	Length     int64           `json:"length"`
-	Type       string          `json:"type"`
-	Precision  int64           `json:"precision"`
-	Scale      int64           `json:"scale"`
-	Nullable   bool            `json:"nullable"`
+	Name            string          `json:"name"`
+	Fields          []fieldMetadata `json:"fields"`
+	ByteLength      int64           `json:"byteLength"`
+	Length          int64           `json:"length"`
+	Type            string          `json:"type"`
+	Precision       int64           `json:"precision"`
+	Scale           int64           `json:"scale"`
+	Nullable        bool            `json:"nullable"`
+	VectorDimension int64           `json:"vectorDimension,omitempty"`
 }
 
 func (ex *execResponseRowType) toFieldMetadata() fieldMetadata {
@@ -66,17 +67,19 @@ func (ex *execResponseRowType) toFieldMetadata() fieldMetadata {
 		int(ex.Scale),
 		int(ex.Precision),
 		ex.Fields,
+		int(ex.VectorDimension),
 	}
 }
 
 type fieldMetadata struct {
-	Name      string          `json:"name,omitempty"`
-	Type      string          `json:"type"`
-	Nullable  bool            `json:"nullable"`
-	Length    int             `json:"length"`
-	Scale     int             `json:"scale"`
-	Precision int             `json:"precision"`
-	Fields    []fieldMetadata `json:"fields,omitempty"`
+	Name            string          `json:"name,omitempty"`
+	Type            string          `json:"type"`
+	Nullable        bool            `json:"nullable"`
+	Length          int             `json:"length"`
+	Scale           int             `json:"scale"`
+	Precision       int             `json:"precision"`
+	Fields          []fieldMetadata `json:"fields,omitempty"`
+	VectorDimension int             `json:"vectorDimension,omitempty"`
 }
 
 type execResponseChunk struct {
@@ -128,11 +131,13 @@ type execResponseData struct {
 	FinalWarehouseName string                `json:"finalWarehouseName,omitempty"`
 	FinalRoleName      string                `json:"finalRoleName,omitempty"`
 	NumberOfBinds      int                   `json:"numberOfBinds,omitempty"`   // java:int
+	MetaDataOfBinds    []execResponseRowType `json:"metaDataOfBinds,omitempty"` // returned for describe only requests
 	StatementTypeID    int64                 `json:"statementTypeId,omitempty"` // java:long
 	Version            int64                 `json:"version,omitempty"`         // java:long
 	Chunks             []execResponseChunk   `json:"chunks,omitempty"`
 	Qrmk               string                `json:"qrmk,omitempty"`
 	ChunkHeaders       map[string]string     `json:"chunkHeaders,omitempty"`
+	Stats              *execResponseStats    `json:"stats,omitempty"`
 
 	// ping pong response data
 	GetResultURL      string        `json:"getResultUrl,omitempty"`
@@ -167,6 +172,13 @@ type execResponseData struct {
 	QueryContext json.RawMessage `json:"queryContext,omitempty"`
 }
 
+type execResponseStats struct {
+	NumRowsInserted  int64 `json:"numRowsInserted"`
+	NumRowsUpdated   int64 `json:"numRowsUpdated"`
+	NumRowsDeleted   int64 `json:"numRowsDeleted"`
+	NumDmlDuplicates int64 `json:"numDmlDuplicates"`
+}
+
 type execResponse struct {
 	Data    execResponseData `json:"Data"`
 	Message string           `json:"message"`
@@ -0,0 +1,117 @@
+package gosnowflake
+
+import (
+	"context"
+	"database/sql/driver"
+)
+
+// QueryHandleConnection is implemented by the driver connections, which can be
+// type-asserted to it in sql.Conn.Raw, to run queries asynchronously and manage them
+// by their query IDs.
+type QueryHandleConnection interface {
+	// SubmitQuery submits a query for asynchronous execution and returns as soon as
+	// Snowflake has accepted it.
+	SubmitQuery(ctx context.Context, query string, args ...driver.NamedValue) (QueryHandle, error)
+	// GetQueryHandle returns a handle to a query submitted by any connection of the
+	// same user, e.g. by another process.
+	GetQueryHandle(queryID string) (QueryHandle, error)
+	// CancelQuery cancels a running query submitted by any connection of the same user.
+	// It returns an error with the ErrQueryNotCanceled code if the query was not running.
+	CancelQuery(ctx context.Context, queryID string) error
+}
+
+// QueryHandle is a handle to a query executed asynchronously. It is returned by
+// QueryHandleConnection.SubmitQuery and QueryHandleConnection.GetQueryHandle, and it is
+// bound to the connection that returned it.
+type QueryHandle interface {
+	// ID returns the query ID.
+	ID() string
+	// Status returns the current status of the query without waiting for it. Snowflake
+	// may not report the status of a query submitted a moment ago, in which case an
+	// error with the ErrQueryStatus code is returned.
+	Status(ctx context.Context) (*SnowflakeQueryStatus, error)
+	// Wait waits until the query completes. It returns the error of the query if it failed.
+	Wait(ctx context.Context) error
+	// Cancel cancels the query.
+	Cancel(ctx context.Context) error
+	// Rows waits until the query completes and returns its result.
+	Rows(ctx context.Context) (driver.Rows, error)
+	// ArrowBatches waits until the query completes and returns its result as Arrow batches.
+	ArrowBatches(ctx context.Context) ([]*ArrowBatch, error)
+}
+
+type snowflakeQueryHandle struct {
+	sc      *snowflakeConn
+	queryID string
+}
+
+func (qh *snowflakeQueryHandle) ID() string {
+	return qh.queryID
+}
+
+func (qh *snowflakeQueryHandle) Status(ctx context.Context) (*SnowflakeQueryStatus, error) {
+	if qh.sc.rest == nil {
+		return nil, driver.ErrBadConn
+	}
+	queryRet, err := qh.sc.getQueryStatus(ctx, qh.queryID)
+	if err != nil {
+		return nil, err
+	}
+	return queryRet.toSnowflakeQueryStatus(), nil
+}
+
+func (qh *snowflakeQueryHandle) Wait(ctx context.Context) error {
+	if qh.sc.rest == nil {
+		return driver.ErrBadConn
+	}
+	_, err := qh.sc.waitForQueryResult(ctx, qh.queryID)
+	return err
+}
+
+func (qh *snowflakeQueryHandle) Cancel(ctx context.Context) error {
+	return qh.sc.CancelQuery(ctx, qh.queryID)
+}
+
+func (qh *snowflakeQueryHandle) Rows(ctx context.Context) (driver.Rows, error) {
+	if qh.sc.rest == nil {
+		return nil, driver.ErrBadConn
+	}
+	return qh.sc.buildRowsForRunningQuery(ctx, qh.queryID)
+}
+
+func (qh *snowflakeQueryHandle) ArrowBatches(ctx context.Context) ([]*ArrowBatch, error) {
+	rows, err := qh.Rows(WithArrowBatches(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return rows.(SnowflakeRows).GetArrowBatches()
+}
+
+func (sc *snowflakeConn) SubmitQuery(
+	ctx context.Context,
+	query string,
+	args ...driver.NamedValue) (
+	QueryHandle, error) {
+	logger.WithContext(ctx).Infof("SubmitQuery: %#v, %v", query, args)
+	if sc.rest == nil {
+		return nil, driver.ErrBadConn
+	}
+	ctx = context.WithValue(WithAsyncMode(ctx), submitOnly, true)
+	data, err := sc.exec(ctx, query, true, isInternal(ctx), false, args)
+	if err != nil {
+		logger.WithContext(ctx).Errorf("error: %v", err)
+		return nil, err
+	}
+	return &snowflakeQueryHandle{sc: sc, queryID: data.Data.QueryID}, nil
+}
+
+func (sc *snowflakeConn) GetQueryHandle(queryID string) (QueryHandle, error) {
+	if !queryIDRegexp.MatchString(queryID) {
+		return nil, &SnowflakeError{
+			Number:  ErrQueryIDFormat,
+			Message: "Invalid QID",
+			QueryID: queryID,
+		}
+	}
+	return &snowflakeQueryHandle{sc: sc, queryID: queryID}, nil
+}
@@ -0,0 +1,222 @@
+package gosnowflake
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testQueryHandleID = "01b2c3d4-0000-0000-0000-000000000042"
+
+func newQueryHandleTestConn(get funcGetType) *snowflakeConn {
+	return &snowflakeConn{
+		cfg: &Config{Params: map[string]*string{}},
+		rest: &snowflakeRestful{
+			FuncGet:       get,
+			TokenAccessor: getSimpleTokenAccessor(),
+		},
+		queryContextCache:   (&queryContextCache{}).init(),
+		currentTimeProvider: defaultTimeProvider,
+	}
+}
+
+// systemCancelQueryResponse answers SYSTEM$CANCEL_QUERY with the given result.
+func systemCancelQueryResponse(result string) *execResponse {
+	return &execResponse{
+		Data: execResponseData{
+			QueryResultFormat: "json",
+			RowType:           []execResponseRowType{{Name: "SYSTEM$CANCEL_QUERY", Type: "text"}},
+			RowSet:            [][]*string{{&result}},
+			Total:             1,
+			Returned:          1,
+		},
+		Success: true,
+	}
+}
+
+func jsonResponse(body string) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(bytes.NewBufferString(body)),
+	}
+}
+
+func TestUnitSubmitQuery(t *testing.T) {
+	var sentRequest execRequest
+	sc := newQueryHandleTestConn(func(_ context.Context, _ *snowflakeRestful, _ *url.URL, _ map[string]string, _ time.Duration) (*http.Response, error) {
+		t.Fatal("the result should not be retrieved after the query is submitted")
+		return nil, nil
+	})
+	sc.rest.FuncPostQuery = postRestfulQuery
+	sc.rest.FuncPostQueryHelper = postRestfulQueryHelper
+	sc.rest.FuncPost = func(_ context.Context, _ *snowflakeRestful, _ *url.URL, _ map[string]string, body []byte, _ time.Duration, _ currentTimeProvider, _ *Config) (*http.Response, error) {
+		assertNilF(t, json.Unmarshal(body, &sentRequest))
+		return jsonResponse(`{"data": {"queryId": "` + testQueryHandleID + `", "getResultUrl": "/queries/` + testQueryHandleID + `/result"}, "code": "333334", "success": true}`), nil
+	}
+
+	handle, err := sc.SubmitQuery(context.Background(), "CALL long_running_procedure(?)", driver.NamedValue{Ordinal: 1, Value: int64(1)})
+	assertNilF(t, err)
+	assertEqualE(t, handle.ID(), testQueryHandleID)
+	assertTrueE(t, sentRequest.AsyncExec)
+	assertEqualE(t, sentRequest.SQLText, "CALL long_running_procedure(?)")
+	assertEqualE(t, len(sentRequest.Bindings), 1)
+}
+
+func TestUnitQueryHandleConnection(t *testing.T) {
+	var conn driver.Conn = newQueryHandleTestConn(nil)
+	_, ok := conn.(QueryHandleConnection)
+	assertTrueE(t, ok, "the connection should implement QueryHandleConnection")
+	_, ok = conn.(SnowflakeConnection)
+	assertTrueE(t, ok, "the connection should implement SnowflakeConnection")
+}
+
+func TestUnitSubmitQueryFailure(t *testing.T) {
+	sc := newQueryHandleTestConn(nil)
+	sc.rest.FuncPostQuery = func(_ context.Context, _ *snowflakeRestful, _ *url.Values, _ map[string]string, _ []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		return &execResponse{
+			Data:    execResponseData{QueryID: testQueryHandleID, SQLState: "42000"},
+			Code:    "1003",
+			Message: "SQL compilation error",
+			Success: false,
+		}, nil
+	}
+	_, err := sc.SubmitQuery(context.Background(), "SELEC 1")
+	assertTrueF(t, IsSyntaxError(err))
+	var se *SnowflakeError
+	assertTrueF(t, errors.As(err, &se))
+	assertEqualE(t, se.QueryID, testQueryHandleID)
+}
+
+func TestUnitQueryHandleWaitAndRows(t *testing.T) {
+	polls := 0
+	sc := newQueryHandleTestConn(func(_ context.Context, _ *snowflakeRestful, u *url.URL, _ map[string]string, _ time.Duration) (*http.Response, error) {
+		assertEqualE(t, u.Path, "/queries/"+testQueryHandleID+"/result")
+		polls++
+		if polls%2 == 1 {
+			return jsonResponse(`{"data": {"queryId": "` + testQueryHandleID + `"}, "code": "333334", "success": true}`), nil
+		}
+		return jsonResponse(`{"data": {"queryId": "` + testQueryHandleID + `", "queryResultFormat": "json", "total": 2, "returned": 2,
+			"rowtype": [{"name": "C1", "type": "fixed", "nullable": false}], "rowset": [["1"], ["2"]]}, "success": true}`), nil
+	})
+	handle, err := sc.GetQueryHandle(testQueryHandleID)
+	assertNilF(t, err)
+
+	assertNilF(t, handle.Wait(context.Background()))
+	assertEqualE(t, polls, 2)
+
+	rows, err := handle.Rows(context.Background())
+	assertNilF(t, err)
+	defer rows.Close()
+	assertDeepEqualE(t, rows.Columns(), []string{"C1"})
+	dest := make([]driver.Value, 1)
+	var values []string
+	for rows.Next(dest) == nil {
+		values = append(values, dest[0].(string))
+	}
+	assertDeepEqualE(t, values, []string{"1", "2"})
+	assertEqualE(t, rows.(SnowflakeRows).GetQueryID(), testQueryHandleID)
+}
+
+func TestUnitQueryHandleWaitFailure(t *testing.T) {
+	sc := newQueryHandleTestConn(func(_ context.Context, _ *snowflakeRestful, _ *url.URL, _ map[string]string, _ time.Duration) (*http.Response, error) {
+		return jsonResponse(`{"data": {"queryId": "` + testQueryHandleID + `", "sqlState": "57014"}, "code": "000630", "message": "Statement reached its statement or warehouse timeout", "success": false}`), nil
+	})
+	handle, err := sc.GetQueryHandle(testQueryHandleID)
+	assertNilF(t, err)
+	err = handle.Wait(context.Background())
+	assertTrueF(t, IsQueryTimeout(err))
+	_, err = handle.Rows(context.Background())
+	assertTrueE(t, IsQueryTimeout(err))
+}
+
+func TestUnitQueryHandleWaitCanceled(t *testing.T) {
+	sc := newQueryHandleTestConn(func(_ context.Context, _ *snowflakeRestful, _ *url.URL, _ map[string]string, _ time.Duration) (*http.Response, error) {
+		return jsonResponse(`{"data": {"queryId": "` + testQueryHandleID + `"}, "code": "333334", "success": true}`), nil
+	})
+	handle, err := sc.GetQueryHandle(testQueryHandleID)
+	assertNilF(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err = handle.Wait(ctx)
+	assertTrueE(t, errors.Is(err, context.DeadlineExceeded))
+	assertTrueE(t, time.Since(start) < time.Second)
+}
+
+func TestUnitQueryHandleStatus(t *testing.T) {
+	sc := newQueryHandleTestConn(func(_ context.Context, _ *snowflakeRestful, u *url.URL, _ map[string]string, _ time.Duration) (*http.Response, error) {
+		assertEqualE(t, u.Path, monitoringQueriesPath+"/"+testQueryHandleID)
+		return jsonResponse(`{"data": {"queries": [{"status": "RUNNING", "sqlText": "CALL long_running_procedure(1)", "startTime": 1700000000000}]}, "success": true}`), nil
+	})
+	handle, err := sc.GetQueryHandle(testQueryHandleID)
+	assertNilF(t, err)
+	status, err := handle.Status(context.Background())
+	assertNilF(t, err)
+	assertEqualE(t, status.Status, "RUNNING")
+	assertEqualE(t, status.SQLText, "CALL long_running_procedure(1)")
+	assertEqualE(t, status.StartTime, int64(1700000000000))
+}
+
+func TestUnitQueryHandleCancel(t *testing.T) {
+	var sentRequest execRequest
+	sc := newQueryHandleTestConn(nil)
+	sc.rest.FuncPostQuery = func(_ context.Context, _ *snowflakeRestful, _ *url.Values, _ map[string]string, body []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		assertNilF(t, json.Unmarshal(body, &sentRequest))
+		return systemCancelQueryResponse("query [" + testQueryHandleID + "] terminated."), nil
+	}
+	sc.rest.FuncGet = func(_ context.Context, _ *snowflakeRestful, u *url.URL, _ map[string]string, _ time.Duration) (*http.Response, error) {
+		assertEqualE(t, u.Path, monitoringQueriesPath+"/"+testQueryHandleID)
+		return jsonResponse(`{"data": {"queries": [{"status": "ABORTING"}]}, "success": true}`), nil
+	}
+	handle, err := sc.GetQueryHandle(testQueryHandleID)
+	assertNilF(t, err)
+	assertNilF(t, handle.Cancel(context.Background()))
+	assertTrueE(t, strings.Contains(sentRequest.SQLText, "SYSTEM$CANCEL_QUERY"))
+	assertTrueE(t, sentRequest.IsInternal)
+	assertEqualE(t, sentRequest.Bindings["1"].Value, testQueryHandleID)
+}
+
+func TestUnitGetQueryHandleInvalidID(t *testing.T) {
+	sc := newQueryHandleTestConn(nil)
+	_, err := sc.GetQueryHandle("?")
+	var se *SnowflakeError
+	assertTrueF(t, errors.As(err, &se))
+	assertEqualE(t, se.Number, ErrQueryIDFormat)
+}
+
+func TestQueryHandle(t *testing.T) {
+	runSnowflakeConnTest(t, func(sct *SCTest) {
+		handle, err := sct.sc.SubmitQuery(sct.sc.ctx, "SELECT SYSTEM$WAIT(3), 1")
+		assertNilF(t, err)
+		status, err := handle.Status(sct.sc.ctx)
+		if err == nil {
+			assertNotEqualE(t, status.Status, "")
+		}
+
+		// the result can be fetched from another connection
+		runSnowflakeConnTest(t, func(other *SCTest) {
+			otherHandle, err := other.sc.GetQueryHandle(handle.ID())
+			assertNilF(t, err)
+			assertNilF(t, otherHandle.Wait(other.sc.ctx))
+			rows, err := otherHandle.Rows(other.sc.ctx)
+			assertNilF(t, err)
+			defer rows.Close()
+			dest := make([]driver.Value, 2)
+			assertNilF(t, rows.Next(dest))
+			assertEqualE(t, dest[1], "1")
+		})
+
+		handle, err = sct.sc.SubmitQuery(sct.sc.ctx, "SELECT SYSTEM$WAIT(60)")
+		assertNilF(t, err)
+		assertNilF(t, handle.Cancel(sct.sc.ctx))
+		assertTrueE(t, IsCanceled(handle.Wait(sct.sc.ctx)))
+	})
+}
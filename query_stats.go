@@ -0,0 +1,129 @@
+package gosnowflake
+
+import (
+	"strconv"
+	"strings"
+)
+
+// QueryStatsProvider is implemented by the driver.Result and driver.Rows returned by
+// the connection, including the results of DDL and other statements without rows.
+type QueryStatsProvider interface {
+	// Stats returns the execution statistics of the statement. It waits for the
+	// statement to complete if it was executed in asynchronous mode.
+	Stats() (*QueryStats, error)
+}
+
+// QueryStats are the execution statistics Snowflake returns with the result of a
+// statement. They are available from QueryStatsProvider.Stats without another
+// round trip.
+type QueryStats struct {
+	QueryID         string
+	StatementTypeID int64
+	StatementType   string // e.g. SELECT, INSERT, MERGE or DDL
+
+	RowsProduced  int64 // rows in the result set of a query
+	RowsInserted  int64
+	RowsUpdated   int64
+	RowsDeleted   int64
+	DMLDuplicates int64 // duplicate rows reported for a DML statement, if any
+
+	FinalDatabaseName  string // database in use after the statement completed
+	FinalSchemaName    string // schema in use after the statement completed
+	FinalRoleName      string // role in use after the statement completed
+	FinalWarehouseName string // warehouse in use after the statement completed
+}
+
+// RowsAffected returns the number of rows inserted, updated and deleted.
+func (qs *QueryStats) RowsAffected() int64 {
+	return qs.RowsInserted + qs.RowsUpdated + qs.RowsDeleted
+}
+
+func newQueryStats(data *execResponseData) *QueryStats {
+	stats := &QueryStats{
+		QueryID:            data.QueryID,
+		StatementTypeID:    data.StatementTypeID,
+		StatementType:      statementTypeName(data.StatementTypeID),
+		FinalDatabaseName:  data.FinalDatabaseName,
+		FinalSchemaName:    data.FinalSchemaName,
+		FinalRoleName:      data.FinalRoleName,
+		FinalWarehouseName: data.FinalWarehouseName,
+	}
+	if data.StatementTypeID == statementTypeIDSelect {
+		stats.RowsProduced = data.Total
+	}
+	if data.Stats != nil {
+		stats.RowsInserted = data.Stats.NumRowsInserted
+		stats.RowsUpdated = data.Stats.NumRowsUpdated
+		stats.RowsDeleted = data.Stats.NumRowsDeleted
+		stats.DMLDuplicates = data.Stats.NumDmlDuplicates
+	} else if isDml(data.StatementTypeID) {
+		addDMLRowCounts(stats, data)
+	}
+	return stats
+}
+
+// addDMLRowCounts collects the row counts from the single row returned by a DML
+// statement, whose columns are named e.g. "number of rows inserted".
+func addDMLRowCounts(stats *QueryStats, data *execResponseData) {
+	if len(data.RowSet) == 0 {
+		return
+	}
+	for i, rowType := range data.RowType {
+		if i >= len(data.RowSet[0]) || data.RowSet[0][i] == nil {
+			break
+		}
+		count, err := strconv.ParseInt(*data.RowSet[0][i], 10, 64)
+		if err != nil {
+			continue
+		}
+		name := strings.ToLower(rowType.Name)
+		switch {
+		case strings.HasPrefix(name, "number of rows inserted"):
+			stats.RowsInserted += count
+		case strings.HasPrefix(name, "number of rows updated"):
+			stats.RowsUpdated += count
+		case strings.HasPrefix(name, "number of rows deleted"):
+			stats.RowsDeleted += count
+		}
+	}
+}
+
+// add accumulates the row counts of a child statement of a multi-statement query.
+func (qs *QueryStats) add(child *QueryStats) {
+	qs.RowsInserted += child.RowsInserted
+	qs.RowsUpdated += child.RowsUpdated
+	qs.RowsDeleted += child.RowsDeleted
+	qs.DMLDuplicates += child.DMLDuplicates
+}
+
+func statementTypeName(id int64) string {
+	switch id {
+	case statementTypeIDSelect:
+		return "SELECT"
+	case statementTypeIDInsert:
+		return "INSERT"
+	case statementTypeIDUpdate:
+		return "UPDATE"
+	case statementTypeIDDelete:
+		return "DELETE"
+	case statementTypeIDMerge:
+		return "MERGE"
+	case statementTypeIDMultiTableInsert:
+		return "MULTI_TABLE_INSERT"
+	case statementTypeIDMultistatement:
+		return "MULTI_STATEMENT"
+	}
+	switch id &^ 0xFFF {
+	case statementTypeIDSelect:
+		return "SELECT"
+	case statementTypeIDDml:
+		return "DML"
+	case statementTypeIDScl:
+		return "SCL"
+	case statementTypeIDTcl:
+		return "TCL"
+	case statementTypeIDDdl:
+		return "DDL"
+	}
+	return "UNKNOWN"
+}
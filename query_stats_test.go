@@ -0,0 +1,193 @@
+package gosnowflake
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+)
+
+// queryStatsResponse answers every statement with data.
+func queryStatsResponse(data execResponseData) func(string) *execResponse {
+	return func(string) *execResponse {
+		return &execResponse{Data: data, Success: true}
+	}
+}
+
+func TestUnitQueryStatsForMerge(t *testing.T) {
+	inserted, updated, deleted := "3", "5", "1"
+	var statements []string
+	sc := newSessionStateTestConn(&statements, queryStatsResponse(execResponseData{
+		QueryID:         testQueryHandleID,
+		StatementTypeID: statementTypeIDMerge,
+		RowType: []execResponseRowType{
+			{Name: "number of rows inserted", Type: "fixed"},
+			{Name: "number of rows updated", Type: "fixed"},
+			{Name: "number of rows deleted", Type: "fixed"},
+		},
+		RowSet:             [][]*string{{&inserted, &updated, &deleted}},
+		FinalDatabaseName:  "TEST_DB",
+		FinalSchemaName:    "PUBLIC",
+		FinalRoleName:      "LOADER",
+		FinalWarehouseName: "TEST_WH",
+	}))
+	result, err := sc.ExecContext(context.Background(), "MERGE INTO t USING s ON t.id = s.id ...", nil)
+	assertNilF(t, err)
+	affected, err := result.RowsAffected()
+	assertNilF(t, err)
+	assertEqualE(t, affected, int64(9))
+
+	stats, err := result.(QueryStatsProvider).Stats()
+	assertNilF(t, err)
+	assertDeepEqualE(t, stats, &QueryStats{
+		QueryID:            testQueryHandleID,
+		StatementTypeID:    statementTypeIDMerge,
+		StatementType:      "MERGE",
+		RowsInserted:       3,
+		RowsUpdated:        5,
+		RowsDeleted:        1,
+		FinalDatabaseName:  "TEST_DB",
+		FinalSchemaName:    "PUBLIC",
+		FinalRoleName:      "LOADER",
+		FinalWarehouseName: "TEST_WH",
+	})
+	assertEqualE(t, stats.RowsAffected(), affected)
+}
+
+func TestUnitQueryStatsFromResponseStats(t *testing.T) {
+	updated, multiJoined := "4", "2"
+	stats := newQueryStats(&execResponseData{
+		StatementTypeID: statementTypeIDUpdate,
+		RowType: []execResponseRowType{
+			{Name: "number of rows updated", Type: "fixed"},
+			{Name: "number of multi-joined rows updated", Type: "fixed"},
+		},
+		RowSet: [][]*string{{&updated, &multiJoined}},
+		Stats:  &execResponseStats{NumRowsUpdated: 4, NumDmlDuplicates: 2},
+	})
+	assertEqualE(t, stats.StatementType, "UPDATE")
+	assertEqualE(t, stats.RowsUpdated, int64(4))
+	assertEqualE(t, stats.DMLDuplicates, int64(2))
+	assertEqualE(t, stats.RowsInserted, int64(0))
+}
+
+func TestUnitQueryStatsForMultiTableInsert(t *testing.T) {
+	t1, t2 := "10", "20"
+	stats := newQueryStats(&execResponseData{
+		StatementTypeID: statementTypeIDMultiTableInsert,
+		RowType: []execResponseRowType{
+			{Name: "number of rows inserted into T1", Type: "fixed"},
+			{Name: "number of rows inserted into T2", Type: "fixed"},
+		},
+		RowSet: [][]*string{{&t1, &t2}},
+	})
+	assertEqualE(t, stats.StatementType, "MULTI_TABLE_INSERT")
+	assertEqualE(t, stats.RowsInserted, int64(30))
+}
+
+func TestUnitQueryStatsForQuery(t *testing.T) {
+	one, two := "1", "2"
+	var statements []string
+	sc := newSessionStateTestConn(&statements, queryStatsResponse(execResponseData{
+		QueryID:            testQueryHandleID,
+		StatementTypeID:    statementTypeIDSelect,
+		QueryResultFormat:  "json",
+		RowType:            []execResponseRowType{{Name: "C1", Type: "fixed"}},
+		RowSet:             [][]*string{{&one}, {&two}},
+		Total:              2,
+		Returned:           2,
+		FinalWarehouseName: "TEST_WH",
+	}))
+	rows, err := sc.QueryContext(context.Background(), "SELECT 1 UNION SELECT 2", nil)
+	assertNilF(t, err)
+	defer rows.Close()
+	stats, err := rows.(QueryStatsProvider).Stats()
+	assertNilF(t, err)
+	assertEqualE(t, stats.QueryID, testQueryHandleID)
+	assertEqualE(t, stats.StatementType, "SELECT")
+	assertEqualE(t, stats.RowsProduced, int64(2))
+	assertEqualE(t, stats.FinalWarehouseName, "TEST_WH")
+	assertEqualE(t, stats.RowsAffected(), int64(0))
+}
+
+func TestUnitQueryStatsForStatementsWithoutRows(t *testing.T) {
+	var statements []string
+	sc := newSessionStateTestConn(&statements, func(query string) *execResponse {
+		if strings.HasPrefix(query, "USE ") {
+			return useStatementResponse(query)
+		}
+		return &execResponse{Data: execResponseData{
+			QueryID:           testQueryHandleID,
+			StatementTypeID:   statementTypeIDDdl + 0x200,
+			FinalDatabaseName: "TEST_DB",
+		}, Success: true}
+	})
+	result, err := sc.ExecContext(context.Background(), "CREATE TABLE t (c1 int)", nil)
+	assertNilF(t, err)
+	_, err = result.RowsAffected()
+	assertNotNilE(t, err)
+	stats, err := result.(QueryStatsProvider).Stats()
+	assertNilF(t, err)
+	assertEqualE(t, stats.QueryID, testQueryHandleID)
+	assertEqualE(t, stats.StatementType, "DDL")
+	assertEqualE(t, stats.FinalDatabaseName, "TEST_DB")
+
+	result, err = sc.ExecContext(context.Background(), "USE WAREHOUSE OTHER_WH", nil)
+	assertNilF(t, err)
+	stats, err = result.(QueryStatsProvider).Stats()
+	assertNilF(t, err)
+	assertEqualE(t, stats.StatementType, "SCL")
+	assertEqualE(t, stats.FinalWarehouseName, "OTHER_WH")
+
+	// prepared statements return the same result
+	stmt, err := sc.PrepareContext(context.Background(), "CREATE TABLE t (c1 int)")
+	assertNilF(t, err)
+	result, err = stmt.(driver.StmtExecContext).ExecContext(context.Background(), nil)
+	assertNilF(t, err)
+	stats, err = result.(QueryStatsProvider).Stats()
+	assertNilF(t, err)
+	assertEqualE(t, stats.StatementType, "DDL")
+}
+
+func TestUnitStatementTypeName(t *testing.T) {
+	testcases := map[int64]string{
+		statementTypeIDSelect:         "SELECT",
+		statementTypeIDInsert:         "INSERT",
+		statementTypeIDDelete:         "DELETE",
+		statementTypeIDMultistatement: "MULTI_STATEMENT",
+		statementTypeIDDml + 0x600:    "DML",
+		statementTypeIDScl + 0x100:    "SCL",
+		statementTypeIDTcl:            "TCL",
+		statementTypeIDDdl + 0x200:    "DDL",
+		0:                             "UNKNOWN",
+	}
+	for id, name := range testcases {
+		assertEqualE(t, statementTypeName(id), name)
+	}
+}
+
+func TestQueryStats(t *testing.T) {
+	runDBTest(t, func(dbt *DBTest) {
+		dbt.mustExec("CREATE OR REPLACE TEMPORARY TABLE test_query_stats (id int, v string)")
+		dbt.mustExec("INSERT INTO test_query_stats VALUES (1, 'a'), (2, 'b')")
+
+		err := dbt.conn.Raw(func(x any) error {
+			result, err := x.(driver.ExecerContext).ExecContext(context.Background(),
+				`MERGE INTO test_query_stats t USING (SELECT 2 AS id, 'c' AS v UNION ALL SELECT 3, 'd') s ON t.id = s.id
+				WHEN MATCHED THEN UPDATE SET t.v = s.v WHEN NOT MATCHED THEN INSERT (id, v) VALUES (s.id, s.v)`, nil)
+			if err != nil {
+				return err
+			}
+			stats, err := result.(QueryStatsProvider).Stats()
+			if err != nil {
+				return err
+			}
+			assertEqualE(t, stats.StatementType, "MERGE")
+			assertEqualE(t, stats.RowsInserted, int64(1))
+			assertEqualE(t, stats.RowsUpdated, int64(1))
+			assertNotEqualE(t, stats.FinalDatabaseName, "")
+			return nil
+		})
+		assertNilF(t, err)
+	})
+}
@@ -3,6 +3,7 @@ package gosnowflake
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -36,7 +37,7 @@ const (
 type (
 	funcGetType      func(context.Context, *snowflakeRestful, *url.URL, map[string]string, time.Duration) (*http.Response, error)
 	funcPostType     func(context.Context, *snowflakeRestful, *url.URL, map[string]string, []byte, time.Duration, currentTimeProvider, *Config) (*http.Response, error)
-	funcAuthPostType func(context.Context, *http.Client, *url.URL, map[string]string, bodyCreatorType, time.Duration, int) (*http.Response, error)
+	funcAuthPostType func(context.Context, *http.Client, *url.URL, map[string]string, bodyCreatorType, time.Duration, int, *Config) (*http.Response, error)
 	bodyCreatorType  func() ([]byte, error)
 )
 
@@ -74,6 +75,14 @@ type snowflakeRestful struct {
 	FuncGetSSO       func(context.Context, *snowflakeRestful, *url.Values, map[string]string, string, time.Duration) ([]byte, error)
 }
 
+// getConfig returns the config of the connection the restful client belongs to, if any.
+func (sr *snowflakeRestful) getConfig() *Config {
+	if sr.Connection == nil {
+		return nil
+	}
+	return sr.Connection.cfg
+}
+
 func (sr *snowflakeRestful) getURL() *url.URL {
 	return &url.URL{
 		Scheme: sr.Protocol,
@@ -113,7 +122,10 @@ func (sr *snowflakeRestful) renewExpiredSessionToken(ctx context.Context, timeou
 	currentToken, _, _ := sr.TokenAccessor.GetTokens()
 	if expiredToken == currentToken || currentToken == "" {
 		// Only renew the session if the current token is still the expired token or current token is empty
-		return sr.FuncRenewSession(ctx, sr, timeout)
+		if err = sr.FuncRenewSession(ctx, sr, timeout); err != nil && IsSessionExpired(err) && sr.canReauthenticate() {
+			return sr.reauthenticate(ctx, err)
+		}
+		return err
 	}
 	return nil
 }
@@ -171,7 +183,7 @@ func getRestful(
 	headers map[string]string,
 	timeout time.Duration) (
 	*http.Response, error) {
-	return newRetryHTTP(ctx, sr.Client, http.NewRequest, fullURL, headers, timeout, sr.MaxRetryCount, defaultTimeProvider, nil).execute()
+	return newRetryHTTP(ctx, sr.Client, http.NewRequest, fullURL, headers, timeout, sr.MaxRetryCount, defaultTimeProvider, sr.getConfig()).execute()
 }
 
 func postAuthRestful(
@@ -181,9 +193,10 @@ func postAuthRestful(
 	headers map[string]string,
 	bodyCreator bodyCreatorType,
 	timeout time.Duration,
-	maxRetryCount int) (
+	maxRetryCount int,
+	cfg *Config) (
 	*http.Response, error) {
-	return newRetryHTTP(ctx, client, http.NewRequest, fullURL, headers, timeout, maxRetryCount, defaultTimeProvider, nil).
+	return newRetryHTTP(ctx, client, http.NewRequest, fullURL, headers, timeout, maxRetryCount, defaultTimeProvider, cfg).
 		doPost().
 		setBodyCreator(bodyCreator).
 		execute()
@@ -202,15 +215,19 @@ func postRestfulQuery(
 
 	data, err = sr.FuncPostQueryHelper(ctx, sr, params, headers, body, timeout, requestID, cfg)
 
-	// errors other than context timeout and cancel would be returned to upper layers
-	if err != context.Canceled && err != context.DeadlineExceeded {
+	// errors other than context timeout and cancel would be returned to upper layers.
+	// They may come wrapped, e.g. in a *url.Error, if the request was interrupted.
+	if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
 		return data, err
 	}
 
-	if err = sr.FuncCancelQuery(context.Background(), sr, requestID, timeout); err != nil {
-		return nil, err
+	if cancelErr := sr.FuncCancelQuery(context.Background(), sr, requestID, timeout); cancelErr != nil {
+		return nil, cancelErr
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
 	}
-	return nil, ctx.Err()
+	return nil, err
 }
 
 func postRestfulQueryHelper(
@@ -261,10 +278,15 @@ func postRestfulQueryHelper(
 
 		isSessionRenewed := false
 
+		// if the query is only submitted, the caller retrieves the result by query ID
+		if respd.Code == queryInProgressAsyncCode && isSubmitOnly(ctx) {
+			return &respd, nil
+		}
 		// if asynchronous query in progress, kick off retrieval but return object
 		if respd.Code == queryInProgressAsyncCode && isAsyncMode(ctx) {
 			return sr.processAsync(ctx, &respd, headers, timeout, cfg)
 		}
+		pollAttempt := 0
 		for isSessionRenewed || respd.Code == queryInProgressCode ||
 			respd.Code == queryInProgressAsyncCode {
 			if !isSessionRenewed {
@@ -275,16 +297,8 @@ func postRestfulQueryHelper(
 			token, _, _ = sr.TokenAccessor.GetTokens()
 			headers[headerAuthorizationKey] = fmt.Sprintf(headerSnowflakeToken, token)
 
-			resp, err = sr.FuncGet(ctx, sr, fullURL, headers, timeout)
-			if err != nil {
-				logger.WithContext(ctx).Errorf("failed to get response. err: %v", err)
-				return nil, err
-			}
-			respd = execResponse{} // reset the response
-			err = json.NewDecoder(resp.Body).Decode(&respd)
-			resp.Body.Close()
-			if err != nil {
-				logger.WithContext(ctx).Errorf("failed to decode JSON. err: %v", err)
+			pollAttempt++
+			if respd, err = pollQueryResult(ctx, sr, fullURL, headers, timeout, cfg, pollAttempt); err != nil {
 				return nil, err
 			}
 			if respd.Code == sessionExpiredCode {
@@ -313,6 +327,33 @@ func postRestfulQueryHelper(
 	}
 }
 
+// pollQueryResult fetches the current state of a query that is still in progress.
+func pollQueryResult(
+	ctx context.Context,
+	sr *snowflakeRestful,
+	fullURL *url.URL,
+	headers map[string]string,
+	timeout time.Duration,
+	cfg *Config,
+	attempt int) (
+	respd execResponse, err error) {
+	ctx, span := startSpan(ctx, cfg, queryPollSpanName, pollAttemptAttribute.Int(attempt))
+	defer func() { endSpan(span, err) }()
+
+	resp, err := sr.FuncGet(ctx, sr, fullURL, headers, timeout)
+	if err != nil {
+		logger.WithContext(ctx).Errorf("failed to get response. err: %v", err)
+		return respd, err
+	}
+	defer resp.Body.Close()
+	if err = json.NewDecoder(resp.Body).Decode(&respd); err != nil {
+		logger.WithContext(ctx).Errorf("failed to decode JSON. err: %v", err)
+		return respd, err
+	}
+	span.SetAttributes(queryIDAttribute.String(respd.Data.QueryID))
+	return respd, nil
+}
+
 func closeSession(ctx context.Context, sr *snowflakeRestful, timeout time.Duration) error {
 	logger.WithContext(ctx).Info("close session")
 	params := &url.Values{}
@@ -363,7 +404,8 @@ func closeSession(ctx context.Context, sr *snowflakeRestful, timeout time.Durati
 	}
 }
 
-func renewRestfulSession(ctx context.Context, sr *snowflakeRestful, timeout time.Duration) error {
+func renewRestfulSession(ctx context.Context, sr *snowflakeRestful, timeout time.Duration) (err error) {
+	defer func() { getMetrics(sr.getConfig()).recordSessionRenewal(ctx, err) }()
 	logger.WithContext(ctx).Info("start renew session")
 	params := &url.Values{}
 	params.Set(requestIDKey, getOrGenerateRequestIDFromContext(ctx).String())
@@ -379,12 +421,12 @@ func renewRestfulSession(ctx context.Context, sr *snowflakeRestful, timeout time
 	body["requestType"] = "RENEW"
 
 	var reqBody []byte
-	reqBody, err := json.Marshal(body)
+	reqBody, err = json.Marshal(body)
 	if err != nil {
 		return err
 	}
 
-	resp, err := sr.FuncPost(ctx, sr, fullURL, headers, reqBody, timeout, defaultTimeProvider, nil)
+	resp, err := sr.FuncPost(ctx, sr, fullURL, headers, reqBody, timeout, defaultTimeProvider, sr.getConfig())
 	if err != nil {
 		return err
 	}
@@ -24,6 +24,7 @@ type snowflakeResult struct {
 	affectedRows int64
 	insertID     int64 // Snowflake doesn't support last insert id
 	queryID      string
+	stats        *QueryStats
 	status       queryStatus
 	err          error
 	errChannel   chan error
@@ -58,6 +59,16 @@ func (res *snowflakeResult) GetArrowBatches() ([]*ArrowBatch, error) {
 	}
 }
 
+func (res *snowflakeResult) Stats() (*QueryStats, error) {
+	if err := res.waitForAsyncExecStatus(); err != nil {
+		return nil, err
+	}
+	if res.stats == nil {
+		return &QueryStats{QueryID: res.queryID}, nil
+	}
+	return res.stats, nil
+}
+
 func (res *snowflakeResult) waitForAsyncExecStatus() error {
 	// if async exec, block until execution is finished
 	if res.status == QueryStatusInProgress {
@@ -76,6 +87,7 @@ func (res *snowflakeResult) waitForAsyncExecStatus() error {
 
 type snowflakeResultNoRows struct {
 	queryID string
+	stats   *QueryStats
 }
 
 func (*snowflakeResultNoRows) LastInsertId() (int64, error) {
@@ -89,3 +101,10 @@ func (*snowflakeResultNoRows) RowsAffected() (int64, error) {
 func (rnr *snowflakeResultNoRows) GetQueryID() string {
 	return rnr.queryID
 }
+
+func (rnr *snowflakeResultNoRows) Stats() (*QueryStats, error) {
+	if rnr.stats == nil {
+		return &QueryStats{QueryID: rnr.queryID}, nil
+	}
+	return rnr.stats, nil
+}
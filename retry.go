@@ -249,6 +249,7 @@ type retryHTTP struct {
 	maxRetryCount       int
 	currentTimeProvider currentTimeProvider
 	cfg                 *Config
+	kind                RetryRequestKind
 }
 
 func newRetryHTTP(ctx context.Context,
@@ -272,6 +273,7 @@ func newRetryHTTP(ctx context.Context,
 	instance.bodyCreator = emptyBodyCreator
 	instance.currentTimeProvider = currentTimeProvider
 	instance.cfg = cfg
+	instance.kind = retryRequestKindOf(fullURL)
 	return &instance
 }
 
@@ -287,13 +289,23 @@ func (r *retryHTTP) setBody(body []byte) *retryHTTP {
 	return r
 }
 
+func (r *retryHTTP) setKind(kind RetryRequestKind) *retryHTTP {
+	r.kind = kind
+	return r
+}
+
 func (r *retryHTTP) setBodyCreator(bodyCreator bodyCreatorType) *retryHTTP {
 	r.bodyCreator = bodyCreator
 	return r
 }
 
 func (r *retryHTTP) execute() (res *http.Response, err error) {
-	totalTimeout := r.timeout
+	policy := retryPolicyFor(r.cfg)
+	timeout := r.timeout
+	if maxElapsedTime := policy.MaxElapsedTime(r.kind); maxElapsedTime > 0 && (timeout <= 0 || maxElapsedTime < timeout) {
+		timeout = maxElapsedTime
+	}
+	totalTimeout := timeout
 	logger.WithContext(r.ctx).Infof("retryHTTP.totalTimeout: %v", totalTimeout)
 	retryCounter := 0
 	sleepTime := time.Duration(time.Second)
@@ -303,6 +315,17 @@ func (r *retryHTTP) execute() (res *http.Response, err error) {
 	var retryCountUpdater retryCountUpdater
 	var retryReasonUpdater retryReasonUpdater
 
+	metrics := getMetrics(r.cfg)
+	endpoint := endpointName(r.fullURL)
+	breaker := getCircuitBreaker(r.cfg, r.fullURL, r.kind)
+	defer func() {
+		statusCode := 0
+		if res != nil {
+			statusCode = res.StatusCode
+		}
+		metrics.recordHTTPRequest(r.ctx, endpoint, statusCode, err)
+	}()
+
 	for {
 		logger.WithContext(r.ctx).Debugf("retry count: %v", retryCounter)
 		body, err := r.bodyCreator()
@@ -320,9 +343,22 @@ func (r *retryHTTP) execute() (res *http.Response, err error) {
 		for k, v := range r.headers {
 			req.Header.Set(k, v)
 		}
+		if breaker != nil {
+			if err = breaker.allow(r.cfg.CircuitBreaker); err != nil {
+				return nil, err
+			}
+		}
 		res, err = r.client.Do(req)
+		metrics.recordHTTPAttempt(r.ctx, endpoint)
+		if breaker != nil {
+			if r.ctx.Err() != nil {
+				breaker.release()
+			} else {
+				breaker.record(r.cfg.CircuitBreaker, isCircuitBreakerFailure(res, err))
+			}
+		}
 		// check if it can retry.
-		retryable, err := isRetryableError(req, res, err)
+		retryable, err := isRetryableError(policy, r.kind, req, res, err)
 		if !retryable {
 			return res, err
 		}
@@ -336,11 +372,7 @@ func (r *retryHTTP) execute() (res *http.Response, err error) {
 		}
 		// uses exponential jitter backoff
 		retryCounter++
-		if isLoginRequest(req) {
-			sleepTime = defaultWaitAlgo.calculateWaitBeforeRetryForAuthRequest(retryCounter, sleepTime)
-		} else {
-			sleepTime = defaultWaitAlgo.calculateWaitBeforeRetry(sleepTime)
-		}
+		sleepTime = policy.Wait(r.kind, retryCounter, sleepTime)
 
 		if totalTimeout > 0 {
 			logger.WithContext(r.ctx).Infof("to timeout: %v", totalTimeout)
@@ -351,9 +383,9 @@ func (r *retryHTTP) execute() (res *http.Response, err error) {
 					return nil, err
 				}
 				if res != nil {
-					return nil, fmt.Errorf("timeout after %s and %v attempts. HTTP Status: %v. Hanging?", r.timeout, retryCounter, res.StatusCode)
+					return nil, fmt.Errorf("timeout after %s and %v attempts. HTTP Status: %v. Hanging?", timeout, retryCounter, res.StatusCode)
 				}
-				return nil, fmt.Errorf("timeout after %s and %v attempts. Hanging?", r.timeout, retryCounter)
+				return nil, fmt.Errorf("timeout after %s and %v attempts. Hanging?", timeout, retryCounter)
 			}
 		}
 		if requestGUIDReplacer == nil {
@@ -372,6 +404,7 @@ func (r *retryHTTP) execute() (res *http.Response, err error) {
 			retryReason = res.StatusCode
 		}
 		r.fullURL = retryReasonUpdater.replaceOrAdd(retryReason)
+		metrics.recordHTTPRetry(r.ctx, endpoint, retryReason)
 		r.fullURL = ensureClientStartTimeIsSet(r.fullURL, clientStartTime)
 		logger.WithContext(r.ctx).Infof("sleeping %v. to timeout: %v. retrying", sleepTime, totalTimeout)
 		logger.WithContext(r.ctx).Infof("retry count: %v, retry reason: %v", retryCounter, retryReason)
@@ -387,20 +420,16 @@ func (r *retryHTTP) execute() (res *http.Response, err error) {
 	}
 }
 
-func isRetryableError(req *http.Request, res *http.Response, err error) (bool, error) {
+func isRetryableError(policy RetryPolicy, kind RetryRequestKind, req *http.Request, res *http.Response, err error) (bool, error) {
 	if err != nil && res == nil { // Failed http connection. Most probably client timeout.
-		return true, err
+		return policy.IsRetryable(kind, nil, err), err
 	}
 	if res == nil || req == nil {
 		return false, err
 	}
-	return isRetryableStatus(res.StatusCode), err
+	return policy.IsRetryable(kind, res, err), err
 }
 
 func isRetryableStatus(statusCode int) bool {
 	return (statusCode >= 500 && statusCode < 600) || contains(clientErrorsStatusCodesEligibleForRetry, statusCode)
 }
-
-func isLoginRequest(req *http.Request) bool {
-	return contains(authEndpoints, req.URL.Path)
-}
@@ -0,0 +1,117 @@
+package gosnowflake
+
+import (
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// RetryRequestKind identifies the kind of HTTP request a RetryPolicy is consulted for.
+type RetryRequestKind int
+
+const (
+	// OtherRetryRequest is any request not covered by the other kinds, e.g. telemetry or heartbeat.
+	OtherRetryRequest RetryRequestKind = iota
+	// LoginRetryRequest is a login, token or authenticator request.
+	LoginRetryRequest
+	// QueryRetryRequest is a request submitting a statement.
+	QueryRetryRequest
+	// QueryResultRetryRequest is a request polling for the result of an asynchronous statement.
+	QueryResultRetryRequest
+	// ChunkDownloadRetryRequest is a request downloading a result chunk.
+	ChunkDownloadRetryRequest
+	// CloudStorageRetryRequest is a PUT or GET transfer against the stage cloud storage.
+	CloudStorageRetryRequest
+)
+
+func (k RetryRequestKind) String() string {
+	switch k {
+	case LoginRetryRequest:
+		return "login"
+	case QueryRetryRequest:
+		return "query"
+	case QueryResultRetryRequest:
+		return "query_result"
+	case ChunkDownloadRetryRequest:
+		return "chunk_download"
+	case CloudStorageRetryRequest:
+		return "cloud_storage"
+	}
+	return "other"
+}
+
+// RetryPolicy decides how failed HTTP requests are retried. It is registered with
+// Config.RetryPolicy, and DefaultRetryPolicy is used if it is not set.
+//
+// IsRetryable reports whether an attempt is retried given its response, successful or
+// not. res is nil if the request failed without a response, in which case err is set.
+// Transfers to cloud storage are only passed to IsRetryable once the cloud provider
+// response was classified as retryable, with a nil res and the error of the transfer.
+//
+// Wait returns the time to sleep before the given retry attempt, starting from 1.
+// previous is the time slept before the previous attempt, or 1s for the first retry.
+//
+// MaxElapsedTime bounds the total time spent sleeping between attempts. It can only
+// shorten the timeout the driver uses for the request, e.g. Config.LoginTimeout or
+// Config.RequestTimeout, and zero leaves that timeout as is. The number of retries is
+// still bounded by Config.MaxRetryCount. It is not consulted for CloudStorageRetryRequest,
+// whose retries are bounded by the number of attempts of the transfer.
+type RetryPolicy interface {
+	IsRetryable(kind RetryRequestKind, res *http.Response, err error) bool
+	Wait(kind RetryRequestKind, attempt int, previous time.Duration) time.Duration
+	MaxElapsedTime(kind RetryRequestKind) time.Duration
+}
+
+// DefaultRetryPolicy retries connection failures, 5xx, 408 and 429 responses with a
+// jittered exponential backoff.
+var DefaultRetryPolicy RetryPolicy = &defaultRetryPolicy{}
+
+type defaultRetryPolicy struct{}
+
+func (p *defaultRetryPolicy) IsRetryable(kind RetryRequestKind, res *http.Response, err error) bool {
+	if kind == CloudStorageRetryRequest {
+		// already classified as retryable by the cloud storage client
+		return true
+	}
+	if res == nil {
+		// Failed http connection. Most probably client timeout.
+		return err != nil
+	}
+	return isRetryableStatus(res.StatusCode)
+}
+
+func (p *defaultRetryPolicy) Wait(kind RetryRequestKind, attempt int, previous time.Duration) time.Duration {
+	switch kind {
+	case LoginRetryRequest:
+		return defaultWaitAlgo.calculateWaitBeforeRetryForAuthRequest(attempt, previous)
+	case CloudStorageRetryRequest:
+		return time.Duration(math.Min(math.Exp2(float64(attempt-1)), 16)) * time.Second
+	}
+	return defaultWaitAlgo.calculateWaitBeforeRetry(previous)
+}
+
+func (p *defaultRetryPolicy) MaxElapsedTime(_ RetryRequestKind) time.Duration {
+	return 0
+}
+
+func retryPolicyFor(cfg *Config) RetryPolicy {
+	if cfg == nil || cfg.RetryPolicy == nil {
+		return DefaultRetryPolicy
+	}
+	return cfg.RetryPolicy
+}
+
+// retryRequestKindOf guesses the kind of a request against Snowflake from its path.
+func retryRequestKindOf(u *url.URL) RetryRequestKind {
+	switch {
+	case contains(authEndpoints, u.Path):
+		return LoginRetryRequest
+	case strings.HasPrefix(u.Path, queryRequestPath):
+		return QueryRetryRequest
+	case strings.HasPrefix(u.Path, "/queries/") && strings.HasSuffix(u.Path, "/result"):
+		return QueryResultRetryRequest
+	}
+	return OtherRetryRequest
+}
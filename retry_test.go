@@ -3,6 +3,7 @@ package gosnowflake
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -519,7 +520,7 @@ func TestIsRetryable(t *testing.T) {
 
 	for _, tc := range tcs {
 		t.Run(fmt.Sprintf("req %v, resp %v", tc.req, tc.res), func(t *testing.T) {
-			result, _ := isRetryableError(tc.req, tc.res, tc.err)
+			result, _ := isRetryableError(DefaultRetryPolicy, OtherRetryRequest, tc.req, tc.res, tc.err)
 			if result != tc.expected {
 				t.Fatalf("expected %v, got %v; request: %v, response: %v", tc.expected, result, tc.req, tc.res)
 			}
@@ -643,3 +644,146 @@ func TestCalculateRetryWaitForNonAuthRequests(t *testing.T) {
 		})
 	}
 }
+
+type recordingRetryPolicy struct {
+	retryable      bool
+	wait           time.Duration
+	maxElapsedTime time.Duration
+	kinds          []RetryRequestKind
+	attempts       []int
+}
+
+func (p *recordingRetryPolicy) IsRetryable(kind RetryRequestKind, res *http.Response, _ error) bool {
+	p.kinds = append(p.kinds, kind)
+	return p.retryable && (res == nil || res.StatusCode != http.StatusOK)
+}
+
+func (p *recordingRetryPolicy) Wait(_ RetryRequestKind, attempt int, _ time.Duration) time.Duration {
+	p.attempts = append(p.attempts, attempt)
+	return p.wait
+}
+
+func (p *recordingRetryPolicy) MaxElapsedTime(_ RetryRequestKind) time.Duration {
+	return p.maxElapsedTime
+}
+
+func TestRetryPolicyIsConsulted(t *testing.T) {
+	policy := &recordingRetryPolicy{retryable: true, wait: time.Millisecond}
+	client := &fakeHTTPClient{
+		cnt:        3,
+		success:    true,
+		statusCode: http.StatusServiceUnavailable,
+		t:          t,
+	}
+	urlPtr, err := url.Parse("https://fakeaccountretrypolicy.snowflakecomputing.com:443/queries/v1/query-request?" + requestIDKey + "=testid")
+	assertNilF(t, err, "failed to parse the test URL")
+	res, err := newRetryHTTP(context.Background(),
+		client,
+		emptyRequest, urlPtr, make(map[string]string), 60*time.Second, 3, defaultTimeProvider, &Config{RetryPolicy: policy}).doPost().setBody([]byte{0}).execute()
+	assertNilF(t, err, "failed to run retry")
+	assertEqualE(t, res.StatusCode, http.StatusOK)
+	assertDeepEqualE(t, policy.kinds, []RetryRequestKind{QueryRetryRequest, QueryRetryRequest, QueryRetryRequest})
+	assertDeepEqualE(t, policy.attempts, []int{1, 2})
+}
+
+func TestRetryPolicyNotRetryable(t *testing.T) {
+	policy := &recordingRetryPolicy{retryable: false, wait: time.Millisecond}
+	client := &fakeHTTPClient{
+		cnt:        3,
+		success:    true,
+		statusCode: http.StatusServiceUnavailable,
+		t:          t,
+	}
+	urlPtr, err := url.Parse("https://fakeaccountretrypolicy.snowflakecomputing.com:443/session/v1/login-request?" + requestIDKey + "=testid")
+	assertNilF(t, err, "failed to parse the test URL")
+	res, err := newRetryHTTP(context.Background(),
+		client,
+		emptyRequest, urlPtr, make(map[string]string), 60*time.Second, 3, defaultTimeProvider, &Config{RetryPolicy: policy}).doPost().setBody([]byte{0}).execute()
+	assertNilF(t, err, "failed to run retry")
+	assertEqualE(t, res.StatusCode, http.StatusServiceUnavailable)
+	assertDeepEqualE(t, policy.kinds, []RetryRequestKind{LoginRetryRequest})
+	assertEqualE(t, len(policy.attempts), 0)
+}
+
+func TestRetryPolicyMaxElapsedTime(t *testing.T) {
+	policy := &recordingRetryPolicy{retryable: true, wait: 10 * time.Millisecond, maxElapsedTime: 25 * time.Millisecond}
+	client := &fakeHTTPClient{
+		success:    false,
+		statusCode: http.StatusServiceUnavailable,
+		t:          t,
+	}
+	urlPtr, err := url.Parse("https://fakeaccountretrypolicy.snowflakecomputing.com:443/queries/01b2c3d4/result?" + requestIDKey + "=testid")
+	assertNilF(t, err, "failed to parse the test URL")
+	_, err = newRetryHTTP(context.Background(),
+		client,
+		emptyRequest, urlPtr, make(map[string]string), time.Hour, 100, defaultTimeProvider, &Config{RetryPolicy: policy}).execute()
+	assertNotNilF(t, err, "should fail to run retry")
+	assertStringContainsE(t, err.Error(), "timeout after 25ms and 3 attempts")
+	assertDeepEqualE(t, policy.kinds, []RetryRequestKind{QueryResultRetryRequest, QueryResultRetryRequest, QueryResultRetryRequest})
+}
+
+func TestRetryPolicyMaxElapsedTimeLongerThanTimeout(t *testing.T) {
+	policy := &recordingRetryPolicy{retryable: true, wait: 10 * time.Millisecond, maxElapsedTime: time.Hour}
+	client := &fakeHTTPClient{
+		success:    false,
+		statusCode: http.StatusServiceUnavailable,
+		t:          t,
+	}
+	urlPtr, err := url.Parse("https://fakeaccountretrypolicy.snowflakecomputing.com:443/queries/01b2c3d4/result?" + requestIDKey + "=testid")
+	assertNilF(t, err, "failed to parse the test URL")
+	_, err = newRetryHTTP(context.Background(),
+		client,
+		emptyRequest, urlPtr, make(map[string]string), 25*time.Millisecond, 100, defaultTimeProvider, &Config{RetryPolicy: policy}).execute()
+	assertNotNilF(t, err, "should fail to run retry")
+	assertStringContainsE(t, err.Error(), "timeout after 25ms and 3 attempts")
+}
+
+func TestRetryPolicyChunkDownloadKind(t *testing.T) {
+	policy := &recordingRetryPolicy{retryable: true, wait: time.Millisecond}
+	client := &fakeHTTPClient{
+		cnt:        2,
+		success:    true,
+		statusCode: http.StatusServiceUnavailable,
+		t:          t,
+	}
+	urlPtr, err := url.Parse("https://sfc-stage.s3.amazonaws.com/results/chunk_0")
+	assertNilF(t, err, "failed to parse the test URL")
+	_, err = newRetryHTTP(context.Background(),
+		client,
+		emptyRequest, urlPtr, make(map[string]string), time.Minute, 3, defaultTimeProvider, &Config{RetryPolicy: policy}).setKind(ChunkDownloadRetryRequest).execute()
+	assertNilF(t, err, "failed to run retry")
+	assertDeepEqualE(t, policy.kinds, []RetryRequestKind{ChunkDownloadRetryRequest, ChunkDownloadRetryRequest})
+}
+
+func TestRetryRequestKindOf(t *testing.T) {
+	testcases := []struct {
+		path string
+		kind RetryRequestKind
+	}{
+		{path: loginRequestPath, kind: LoginRetryRequest},
+		{path: tokenRequestPath, kind: LoginRetryRequest},
+		{path: authenticatorRequestPath, kind: LoginRetryRequest},
+		{path: queryRequestPath, kind: QueryRetryRequest},
+		{path: "/queries/01b2c3d4/result", kind: QueryResultRetryRequest},
+		{path: abortRequestPath, kind: OtherRetryRequest},
+		{path: heartBeatPath, kind: OtherRetryRequest},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.path, func(t *testing.T) {
+			assertEqualE(t, retryRequestKindOf(&url.URL{Path: tc.path}), tc.kind)
+		})
+	}
+}
+
+func TestDefaultRetryPolicy(t *testing.T) {
+	assertTrueE(t, DefaultRetryPolicy.IsRetryable(QueryRetryRequest, nil, errors.New("connection reset")))
+	assertTrueE(t, DefaultRetryPolicy.IsRetryable(QueryRetryRequest, &http.Response{StatusCode: http.StatusTooManyRequests}, nil))
+	assertFalseE(t, DefaultRetryPolicy.IsRetryable(QueryRetryRequest, &http.Response{StatusCode: http.StatusForbidden}, nil))
+	assertTrueE(t, DefaultRetryPolicy.IsRetryable(CloudStorageRetryRequest, nil, nil))
+	assertEqualE(t, DefaultRetryPolicy.Wait(CloudStorageRetryRequest, 1, time.Second), time.Second)
+	assertEqualE(t, DefaultRetryPolicy.Wait(CloudStorageRetryRequest, 3, time.Second), 4*time.Second)
+	assertEqualE(t, DefaultRetryPolicy.Wait(CloudStorageRetryRequest, 10, time.Second), 16*time.Second)
+	wait := DefaultRetryPolicy.Wait(QueryRetryRequest, 1, time.Second)
+	assertTrueE(t, wait >= time.Second && wait <= 3*time.Second)
+	assertEqualE(t, DefaultRetryPolicy.MaxElapsedTime(LoginRetryRequest), time.Duration(0))
+}
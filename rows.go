@@ -39,21 +39,30 @@ type snowflakeRows struct {
 	ChunkDownloader     chunkDownloader
 	tailChunkDownloader chunkDownloader
 	queryID             string
+	stats               *QueryStats
 	status              queryStatus
 	err                 error
 	errChannel          chan error
 	location            *time.Location
+	params              map[string]*string
 	ctx                 context.Context
 	format              resultFormat
 }
 
 func (rows *snowflakeRows) getLocation() *time.Location {
 	if rows.location == nil && rows.sc != nil && rows.sc.cfg != nil {
-		rows.location = getCurrentLocation(rows.sc.cfg.Params)
+		rows.location = getCurrentLocation(rows.getParams())
 	}
 	return rows.location
 }
 
+func (rows *snowflakeRows) getParams() map[string]*string {
+	if rows.params == nil {
+		rows.params = sessionParametersFor(rows.ctx, rows.sc.cfg.Params)
+	}
+	return rows.params
+}
+
 type snowflakeValue interface{}
 
 type chunkRowType struct {
@@ -99,6 +108,8 @@ func (rows *snowflakeRows) ColumnTypeLength(index int) (length int64, ok bool) {
 	switch rows.ChunkDownloader.getRowType()[index].Type {
 	case "text", "variant", "object", "array", "binary":
 		return rows.ChunkDownloader.getRowType()[index].Length, true
+	case "vector":
+		return rows.ChunkDownloader.getRowType()[index].VectorDimension, true
 	}
 	return 0, false
 }
@@ -159,6 +170,16 @@ func (rows *snowflakeRows) GetStatus() queryStatus {
 	return rows.status
 }
 
+func (rows *snowflakeRows) Stats() (*QueryStats, error) {
+	if err := rows.waitForAsyncQueryStatus(); err != nil {
+		return nil, err
+	}
+	if rows.stats == nil {
+		return &QueryStats{QueryID: rows.queryID}, nil
+	}
+	return rows.stats, nil
+}
+
 // GetArrowBatches returns an array of ArrowBatch objects to retrieve data in arrow.Record format
 func (rows *snowflakeRows) GetArrowBatches() ([]*ArrowBatch, error) {
 	// Wait for all arrow batches before fetching.
@@ -195,7 +216,7 @@ func (rows *snowflakeRows) Next(dest []driver.Value) (err error) {
 		for i, n := 0, len(row.RowSet); i < n; i++ {
 			// could move to chunk downloader so that each go routine
 			// can convert data
-			err = stringToValue(rows.ctx, &dest[i], rows.ChunkDownloader.getRowType()[i], row.RowSet[i], rows.getLocation(), rows.sc.cfg.Params)
+			err = stringToValue(rows.ctx, &dest[i], rows.ChunkDownloader.getRowType()[i], row.RowSet[i], rows.getLocation(), rows.getParams())
 			if err != nil {
 				return err
 			}
@@ -0,0 +1,224 @@
+package gosnowflake
+
+import (
+	"context"
+	"database/sql/driver"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	statementTypeIDAlterSession = statementTypeIDScl + int64(0x100)
+	statementTypeIDUse          = statementTypeIDScl + int64(0x300)
+	statementTypeIDShow         = statementTypeIDScl + int64(0x400)
+	statementTypeIDDescribe     = statementTypeIDScl + int64(0x500)
+)
+
+var useSecondaryRolesPattern = regexp.MustCompile(`(?i)^\s*USE\s+SECONDARY\s+ROLES?\b`)
+
+// sessionState is the part of the session state a statement may change and that is
+// restored when the connection is returned to the pool.
+type sessionState struct {
+	role      string
+	warehouse string
+	database  string
+	schema    string
+}
+
+func (sc *snowflakeConn) currentSessionState() sessionState {
+	sc.sessionMu.Lock()
+	defer sc.sessionMu.Unlock()
+	return sessionState{
+		role:      sc.cfg.Role,
+		warehouse: sc.cfg.Warehouse,
+		database:  sc.cfg.Database,
+		schema:    sc.cfg.Schema,
+	}
+}
+
+// initSessionState records the state of the session right after login, which is the
+// state ResetSession restores.
+func (sc *snowflakeConn) initSessionState(info authResponseSessionInfo) {
+	sc.updateSessionState(info)
+	initial := sc.currentSessionState()
+	sc.sessionMu.Lock()
+	defer sc.sessionMu.Unlock()
+	sc.initialSession = &initial
+	sc.sessionAltered = false
+}
+
+// updateSessionState records the state of a new session without changing the state
+// ResetSession restores.
+func (sc *snowflakeConn) updateSessionState(info authResponseSessionInfo) {
+	sc.sessionMu.Lock()
+	defer sc.sessionMu.Unlock()
+	if info.RoleName != "" {
+		sc.cfg.Role = info.RoleName
+	}
+	if info.WarehouseName != "" {
+		sc.cfg.Warehouse = info.WarehouseName
+	}
+	if info.DatabaseName != "" {
+		sc.cfg.Database = info.DatabaseName
+	}
+	if info.SchemaName != "" {
+		sc.cfg.Schema = info.SchemaName
+	}
+}
+
+// trackSessionChanges records the role, warehouse, database and schema in use after a
+// statement, and whether the statement changed the session in a way ResetSession
+// cannot restore.
+func (sc *snowflakeConn) trackSessionChanges(query string, data *execResponseData) {
+	sc.sessionMu.Lock()
+	defer sc.sessionMu.Unlock()
+	if data.FinalDatabaseName != "" {
+		sc.cfg.Database = data.FinalDatabaseName
+	}
+	if data.FinalSchemaName != "" {
+		sc.cfg.Schema = data.FinalSchemaName
+	}
+	if data.FinalWarehouseName != "" {
+		sc.cfg.Warehouse = data.FinalWarehouseName
+	}
+	if data.FinalRoleName != "" {
+		sc.cfg.Role = data.FinalRoleName
+	}
+	if altersSession(data.StatementTypeID, query) {
+		sc.sessionAltered = true
+		return
+	}
+	if isMultiStmt(data) {
+		for _, child := range getChildResults(data.ResultIDs, data.ResultTypes) {
+			// the text of a child statement is not known
+			if typ, err := strconv.ParseInt(child.typ, 10, 64); err == nil && altersSession(typ, "") {
+				sc.sessionAltered = true
+				return
+			}
+		}
+	}
+}
+
+// altersSession returns true if a statement of the type changes the session in a way
+// ResetSession cannot restore, i.e. any SCL statement that is not read-only or a USE of
+// a role, warehouse, database or schema. USE SECONDARY ROLES can only be told apart
+// from the other USE statements by its text, so a USE statement whose text is unknown
+// is considered to alter the session.
+func altersSession(typ int64, query string) bool {
+	switch {
+	case typ < statementTypeIDScl || typ >= statementTypeIDTcl:
+		return false
+	case typ == statementTypeIDShow || typ == statementTypeIDDescribe:
+		return false
+	case statementTypeIDUse <= typ && typ < statementTypeIDShow:
+		return query == "" || useSecondaryRolesPattern.MatchString(query)
+	}
+	return true
+}
+
+// ResetSession is called by database/sql before a pooled connection is reused. It
+// restores the role, warehouse, database and schema the session had after login. A
+// session that was changed otherwise, e.g. with ALTER SESSION, SET or USE SECONDARY
+// ROLES, cannot be restored, so the connection is discarded and a new session is
+// created with the parameters of the Config.
+func (sc *snowflakeConn) ResetSession(ctx context.Context) error {
+	if !sc.IsValid() {
+		return driver.ErrBadConn
+	}
+	sc.sessionMu.Lock()
+	initialSession, sessionAltered := sc.initialSession, sc.sessionAltered
+	sc.sessionMu.Unlock()
+	if sessionAltered {
+		logger.WithContext(ctx).Info("session was altered. discarding the connection")
+		return driver.ErrBadConn
+	}
+	if initialSession == nil {
+		return nil
+	}
+	initial, current := *initialSession, sc.currentSessionState()
+	if initial == current {
+		return nil
+	}
+	logger.WithContext(ctx).Infof("restoring session state. from: %+v, to: %+v", current, initial)
+	schemaName := quoteIdentifier(initial.schema)
+	if initial.database != "" {
+		schemaName = quoteIdentifier(initial.database) + "." + schemaName
+	}
+	restore := []struct {
+		objectType string
+		initial    string
+		name       string
+		current    func(sessionState) string
+	}{
+		{"ROLE", initial.role, quoteIdentifier(initial.role), func(s sessionState) string { return s.role }},
+		{"WAREHOUSE", initial.warehouse, quoteIdentifier(initial.warehouse), func(s sessionState) string { return s.warehouse }},
+		{"DATABASE", initial.database, quoteIdentifier(initial.database), func(s sessionState) string { return s.database }},
+		// the schema is checked after the database is restored, which changes it as well
+		{"SCHEMA", initial.schema, schemaName, func(s sessionState) string { return s.schema }},
+	}
+	for _, r := range restore {
+		if r.initial == r.current(sc.currentSessionState()) {
+			continue
+		}
+		if r.initial == "" {
+			// nothing to go back to, e.g. no default warehouse
+			logger.WithContext(ctx).Infof("cannot restore an empty %v. discarding the connection", strings.ToLower(r.objectType))
+			return driver.ErrBadConn
+		}
+		if _, err := sc.exec(ctx, "USE "+r.objectType+" "+r.name, false, true, false, nil); err != nil {
+			logger.WithContext(ctx).Errorf("failed to restore the %v. err: %v", strings.ToLower(r.objectType), err)
+			return driver.ErrBadConn
+		}
+	}
+	return nil
+}
+
+// IsValid is called by database/sql before a connection is returned to the pool. The
+// connection is discarded if its session is known to be closed or expired, or if its
+// heartbeats keep failing.
+func (sc *snowflakeConn) IsValid() bool {
+	if sc.rest == nil || sc.sessionExpired {
+		return false
+	}
+	if sc.rest.HeartBeat != nil && !sc.rest.HeartBeat.healthy() {
+		return false
+	}
+	if sc.rest.TokenAccessor != nil {
+		if token, _, _ := sc.rest.TokenAccessor.GetTokens(); token == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// sessionContext is the context of a connection. It carries the ID of the current
+// session, which is replaced when the connection logs in again.
+type sessionContext struct {
+	context.Context
+	sessionID atomic.Value // int64
+}
+
+func withSessionID(ctx context.Context, sessionID int64) context.Context {
+	sessionCtx := &sessionContext{Context: ctx}
+	sessionCtx.setSessionID(sessionID)
+	return sessionCtx
+}
+
+func (c *sessionContext) setSessionID(sessionID int64) {
+	c.sessionID.Store(sessionID)
+}
+
+func (c *sessionContext) Value(key any) any {
+	if key == SFSessionIDKey {
+		if sessionID := c.sessionID.Load(); sessionID != nil {
+			return sessionID
+		}
+	}
+	return c.Context.Value(key)
+}
+
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,194 @@
+package gosnowflake
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newSessionStateTestConn returns a logged in connection whose statements are answered
+// by respond, recording the statements sent.
+func newSessionStateTestConn(statements *[]string, respond func(query string) *execResponse) *snowflakeConn {
+	postQueryMock := func(_ context.Context, _ *snowflakeRestful, _ *url.Values,
+		_ map[string]string, body []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		var req execRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			return nil, err
+		}
+		*statements = append(*statements, req.SQLText)
+		return respond(req.SQLText), nil
+	}
+	tokenAccessor := getSimpleTokenAccessor()
+	tokenAccessor.SetTokens("token", "master token", 1)
+	sc := &snowflakeConn{
+		cfg:               &Config{Params: map[string]*string{}, Role: "analyst"},
+		rest:              &snowflakeRestful{FuncPostQuery: postQueryMock, TokenAccessor: tokenAccessor},
+		queryContextCache: (&queryContextCache{}).init(),
+	}
+	sc.initSessionState(authResponseSessionInfo{
+		RoleName:      "ANALYST",
+		WarehouseName: "TEST_WH",
+		DatabaseName:  "TEST_DB",
+		SchemaName:    "PUBLIC",
+	})
+	return sc
+}
+
+// useStatementResponse answers USE statements like Snowflake, with the final names
+// of the session.
+func useStatementResponse(query string) *execResponse {
+	data := execResponseData{StatementTypeID: statementTypeIDScl + 0x300}
+	fields := strings.SplitN(query, " ", 3)
+	name := strings.ReplaceAll(strings.Trim(fields[2], `"`), `"."`, ".")
+	switch fields[1] {
+	case "ROLE":
+		data.FinalRoleName = name
+	case "WAREHOUSE":
+		data.FinalWarehouseName = name
+	case "DATABASE":
+		data.FinalDatabaseName = name
+		data.FinalSchemaName = "PUBLIC"
+	case "SCHEMA":
+		if parts := strings.Split(name, "."); len(parts) == 2 {
+			data.FinalDatabaseName, name = parts[0], parts[1]
+		}
+		data.FinalSchemaName = name
+	}
+	return &execResponse{Data: data, Success: true}
+}
+
+func TestUnitResetSessionRestoresSessionState(t *testing.T) {
+	var statements []string
+	sc := newSessionStateTestConn(&statements, useStatementResponse)
+	assertEqualE(t, sc.cfg.Role, "ANALYST")
+
+	for _, query := range []string{"USE ROLE ADMIN", "USE WAREHOUSE OTHER_WH", "USE DATABASE OTHER_DB"} {
+		_, err := sc.ExecContext(context.Background(), query, nil)
+		assertNilF(t, err)
+	}
+	assertEqualE(t, sc.cfg.Role, "ADMIN")
+	assertEqualE(t, sc.cfg.Schema, "PUBLIC")
+	statements = nil
+
+	assertNilF(t, sc.ResetSession(context.Background()))
+	assertDeepEqualE(t, statements, []string{
+		`USE ROLE "ANALYST"`,
+		`USE WAREHOUSE "TEST_WH"`,
+		`USE DATABASE "TEST_DB"`,
+	})
+	assertEqualE(t, sc.currentSessionState(), *sc.initialSession)
+
+	// nothing to restore
+	statements = nil
+	assertNilF(t, sc.ResetSession(context.Background()))
+	assertEqualE(t, len(statements), 0)
+}
+
+func TestUnitResetSessionRestoresSchema(t *testing.T) {
+	var statements []string
+	sc := newSessionStateTestConn(&statements, useStatementResponse)
+	_, err := sc.ExecContext(context.Background(), "USE SCHEMA OTHER_SCHEMA", nil)
+	assertNilF(t, err)
+	statements = nil
+
+	assertNilF(t, sc.ResetSession(context.Background()))
+	assertDeepEqualE(t, statements, []string{`USE SCHEMA "TEST_DB"."PUBLIC"`})
+	assertEqualE(t, sc.cfg.Schema, "PUBLIC")
+}
+
+func TestUnitResetSessionDiscardsAlteredSession(t *testing.T) {
+	var statements []string
+	sc := newSessionStateTestConn(&statements, func(string) *execResponse {
+		return &execResponse{Data: execResponseData{StatementTypeID: statementTypeIDAlterSession}, Success: true}
+	})
+	assertNilF(t, sc.ResetSession(context.Background()))
+	_, err := sc.ExecContext(context.Background(), "ALTER SESSION SET TIMEZONE = 'UTC'", nil)
+	assertNilF(t, err)
+	assertEqualE(t, sc.ResetSession(context.Background()), driver.ErrBadConn)
+}
+
+func TestUnitResetSessionDiscardsSecondaryRoles(t *testing.T) {
+	var statements []string
+	sc := newSessionStateTestConn(&statements, func(query string) *execResponse {
+		if strings.HasPrefix(query, "USE SECONDARY ROLES") {
+			return &execResponse{Data: execResponseData{StatementTypeID: statementTypeIDUse}, Success: true}
+		}
+		return useStatementResponse(query)
+	})
+	_, err := sc.ExecContext(context.Background(), "USE ROLE ADMIN", nil)
+	assertNilF(t, err)
+	assertNilF(t, sc.ResetSession(context.Background()))
+
+	_, err = sc.ExecContext(context.Background(), "USE SECONDARY ROLES ALL", nil)
+	assertNilF(t, err)
+	assertEqualE(t, sc.ResetSession(context.Background()), driver.ErrBadConn)
+}
+
+func TestUnitResetSessionDiscardsSessionVariables(t *testing.T) {
+	var statements []string
+	sc := newSessionStateTestConn(&statements, func(query string) *execResponse {
+		typ := statementTypeIDScl + 0x200
+		if strings.HasPrefix(query, "SHOW") {
+			typ = statementTypeIDShow
+		}
+		return &execResponse{Data: execResponseData{StatementTypeID: typ}, Success: true}
+	})
+	_, err := sc.ExecContext(context.Background(), "SHOW VARIABLES", nil)
+	assertNilF(t, err)
+	assertNilF(t, sc.ResetSession(context.Background()))
+
+	_, err = sc.ExecContext(context.Background(), "SET v = 1", nil)
+	assertNilF(t, err)
+	assertEqualE(t, sc.ResetSession(context.Background()), driver.ErrBadConn)
+}
+
+func TestUnitResetSessionDiscardsUnrestorableSession(t *testing.T) {
+	var statements []string
+	sc := newSessionStateTestConn(&statements, useStatementResponse)
+	sc.initSessionState(authResponseSessionInfo{})
+	sc.initialSession.warehouse = ""
+	_, err := sc.ExecContext(context.Background(), "USE WAREHOUSE OTHER_WH", nil)
+	assertNilF(t, err)
+	assertEqualE(t, sc.ResetSession(context.Background()), driver.ErrBadConn)
+}
+
+func TestUnitIsValid(t *testing.T) {
+	var statements []string
+	sc := newSessionStateTestConn(&statements, func(string) *execResponse {
+		return &execResponse{Code: "390114", Message: "Authentication token has expired.", Success: false}
+	})
+	assertTrueE(t, sc.IsValid())
+
+	_, err := sc.ExecContext(context.Background(), "SELECT 1", nil)
+	assertTrueF(t, IsSessionExpired(err))
+	assertFalseE(t, sc.IsValid())
+	assertEqualE(t, sc.ResetSession(context.Background()), driver.ErrBadConn)
+
+	sc = newSessionStateTestConn(&statements, useStatementResponse)
+	sc.rest.TokenAccessor.SetTokens("", "", -1)
+	assertFalseE(t, sc.IsValid())
+}
+
+func TestResetSession(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	var initialSchema string
+	assertNilF(t, db.QueryRow("SELECT CURRENT_SCHEMA()").Scan(&initialSchema))
+	_, err := db.Exec("CREATE OR REPLACE TEMPORARY TABLE test_reset_session (c int)")
+	assertNilF(t, err)
+	_, err = db.Exec("USE SCHEMA INFORMATION_SCHEMA")
+	assertNilF(t, err)
+
+	// the same connection is reused with its schema restored
+	var schema string
+	assertNilF(t, db.QueryRow("SELECT CURRENT_SCHEMA()").Scan(&schema))
+	assertEqualE(t, schema, initialSchema)
+	_, err = db.Exec("SELECT * FROM test_reset_session")
+	assertNilF(t, err)
+}
@@ -13,4 +13,12 @@ const (
 	SQLStateConnectionFailure = "08006"
 	// SQLStateFeatureNotSupported is a SQL State code indicating the feature is not enabled.
 	SQLStateFeatureNotSupported = "0A000"
+	// SQLStateSyntaxErrorOrAccessRuleViolation is a SQL State code indicating a syntax error in the statement.
+	SQLStateSyntaxErrorOrAccessRuleViolation = "42000"
+	// SQLStateInsufficientPrivilege is a SQL State code indicating the role lacks privileges on an object.
+	SQLStateInsufficientPrivilege = "42501"
+	// SQLStateObjectNotFound is a SQL State code indicating the object does not exist or is not authorized.
+	SQLStateObjectNotFound = "42S02"
+	// SQLStateQueryCanceled is a SQL State code indicating the statement was canceled or timed out.
+	SQLStateQueryCanceled = "57014"
 )
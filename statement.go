@@ -5,6 +5,9 @@ import (
 	"database/sql/driver"
 	"errors"
 	"fmt"
+	"reflect"
+	"strings"
+	"sync"
 )
 
 // SnowflakeStmt represents the prepared statement in driver.
@@ -12,10 +15,96 @@ type SnowflakeStmt interface {
 	GetQueryID() string
 }
 
+// StatementDescriber is implemented by the prepared statements of the driver. The first
+// time its metadata is requested, the statement is sent to Snowflake in describe only
+// mode, without being executed.
+type StatementDescriber interface {
+	// ColumnTypes returns the columns of the result set the statement produces.
+	ColumnTypes(ctx context.Context) ([]ColumnType, error)
+	// ParameterTypes returns the bind parameters of the statement.
+	ParameterTypes(ctx context.Context) ([]ColumnType, error)
+}
+
+// ColumnType describes a result column or a bind parameter of a prepared statement.
+type ColumnType struct {
+	Name             string       // column name, or parameter name if provided by the server
+	DatabaseTypeName string       // Snowflake type name, e.g. FIXED, TEXT, TIMESTAMP_NTZ
+	ScanType         reflect.Type // Go type the value can be scanned into
+	Nullable         bool
+	Length           int64 // maximum length of text and binary types
+	Precision        int64
+	Scale            int64
+}
+
 type snowflakeStmt struct {
 	sc          *snowflakeConn
 	query       string
 	lastQueryID string
+
+	// PUT/GET and multi statement queries cannot be described
+	describable    bool
+	describeMu     sync.Mutex
+	described      bool
+	numInput       int
+	numInputFailed bool // whether describing the statement failed in NumInput
+	columnTypes    []ColumnType
+	parameterTypes []ColumnType
+
+	// type marker checked by CheckNamedValue, folded into the value bound after it
+	pendingMarker  driver.Value
+	pendingOrdinal int
+}
+
+// typedValue is a bind value together with the type marker, e.g. DataTypeBinary, bound
+// before it.
+type typedValue struct {
+	marker driver.Value
+	value  driver.Value
+}
+
+var errStatementNotDescribable = errors.New("PUT, GET and multi-statement queries cannot be described")
+
+// describe sends the query to the server in describe only mode to learn about
+// its bind parameters and result columns without executing it. The statement is
+// described once, and again after a failure.
+func (stmt *snowflakeStmt) describe(ctx context.Context) error {
+	stmt.describeMu.Lock()
+	defer stmt.describeMu.Unlock()
+	if stmt.described {
+		return nil
+	}
+	if !stmt.describable {
+		return errStatementNotDescribable
+	}
+	logger.WithContext(ctx).Infoln("Stmt.describe")
+	// the query ID channel belongs to the statement execution, not to the describe request,
+	// which is never asynchronous
+	ctx = context.WithValue(WithQueryIDChan(ctx, nil), asyncMode, false)
+	data, err := stmt.sc.exec(ctx, stmt.query, false /* noResult */, isInternal(ctx), true /* describeOnly */, nil)
+	if err != nil {
+		return err
+	}
+	stmt.numInput = data.Data.NumberOfBinds
+	stmt.columnTypes = toColumnTypes(ctx, data.Data.RowType)
+	stmt.parameterTypes = toColumnTypes(ctx, data.Data.MetaDataOfBinds)
+	stmt.described = true
+	return nil
+}
+
+func toColumnTypes(ctx context.Context, rowTypes []execResponseRowType) []ColumnType {
+	columnTypes := make([]ColumnType, len(rowTypes))
+	for i, rowType := range rowTypes {
+		columnTypes[i] = ColumnType{
+			Name:             rowType.Name,
+			DatabaseTypeName: strings.ToUpper(rowType.Type),
+			ScanType:         snowflakeTypeToGo(ctx, getSnowflakeType(rowType.Type), rowType.Precision, rowType.Scale, rowType.Fields),
+			Nullable:         rowType.Nullable,
+			Length:           rowType.Length,
+			Precision:        rowType.Precision,
+			Scale:            rowType.Scale,
+		}
+	}
+	return columnTypes
 }
 
 func (stmt *snowflakeStmt) Close() error {
@@ -24,10 +113,73 @@ func (stmt *snowflakeStmt) Close() error {
 	return nil
 }
 
+// NumInput returns the number of bind parameters reported by Snowflake, describing the
+// statement the first time. It returns -1 if the statement cannot be described, in which
+// case the arguments are checked when the statement is executed.
 func (stmt *snowflakeStmt) NumInput() int {
 	logger.WithContext(stmt.sc.ctx).Infoln("Stmt.NumInput")
-	// Go Snowflake doesn't know the number of binding parameters.
-	return -1
+	if stmt.numInputFailed {
+		return -1
+	}
+	ctx := stmt.sc.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := stmt.describe(ctx); err != nil {
+		logger.WithContext(stmt.sc.ctx).Debugf("the number of bind parameters is unknown. %v", err)
+		stmt.numInputFailed = true
+		return -1
+	}
+	return stmt.numInput
+}
+
+// CheckNamedValue folds the type markers, e.g. DataTypeBinary, into the values bound after
+// them, so that database/sql does not count them as arguments when it compares the
+// arguments with NumInput. The other values are checked by the connection.
+func (stmt *snowflakeStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if _, err := dataTypeMode(nv.Value); err == nil {
+		stmt.pendingMarker, stmt.pendingOrdinal = nv.Value, nv.Ordinal
+		return driver.ErrRemoveArgument
+	}
+	err := stmt.sc.CheckNamedValue(nv)
+	if err == driver.ErrSkip && stmt.pendingMarker != nil {
+		// the value is passed on with its marker, so it is converted here
+		nv.Value, err = driver.DefaultParameterConverter.ConvertValue(nv.Value)
+	}
+	if err != nil {
+		stmt.pendingMarker = nil
+		return err
+	}
+	if stmt.pendingMarker != nil && stmt.pendingOrdinal == nv.Ordinal {
+		nv.Value = typedValue{marker: stmt.pendingMarker, value: nv.Value}
+	}
+	stmt.pendingMarker = nil
+	return nil
+}
+
+// withTypeMarkers unfolds the type markers folded into the values by CheckNamedValue.
+func withTypeMarkers(args []driver.NamedValue) []driver.NamedValue {
+	var unfolded []driver.NamedValue
+	for i, arg := range args {
+		tv, ok := arg.Value.(typedValue)
+		if !ok {
+			if unfolded != nil {
+				unfolded = append(unfolded, arg)
+			}
+			continue
+		}
+		if unfolded == nil {
+			unfolded = append(make([]driver.NamedValue, 0, len(args)+1), args[:i]...)
+		}
+		unfolded = append(unfolded, driver.NamedValue{Value: tv.marker}, driver.NamedValue{Name: arg.Name, Value: tv.value})
+	}
+	if unfolded == nil {
+		return args
+	}
+	for i := range unfolded {
+		unfolded[i].Ordinal = i + 1
+	}
+	return unfolded
 }
 
 func (stmt *snowflakeStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
@@ -37,7 +189,7 @@ func (stmt *snowflakeStmt) ExecContext(ctx context.Context, args []driver.NamedV
 
 func (stmt *snowflakeStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
 	logger.WithContext(stmt.sc.ctx).Infoln("Stmt.QueryContext")
-	rows, err := stmt.sc.QueryContext(ctx, stmt.query, args)
+	rows, err := stmt.sc.QueryContext(ctx, stmt.query, withTypeMarkers(args))
 	if err != nil {
 		stmt.setQueryIDFromError(err)
 		return nil, err
@@ -60,8 +212,7 @@ func (stmt *snowflakeStmt) execInternal(ctx context.Context, args []driver.Named
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	stmtCtx := context.WithValue(ctx, executionType, executionTypeStatement)
-	result, err := stmt.sc.ExecContext(stmtCtx, stmt.query, args)
+	result, err := stmt.sc.ExecContext(ctx, stmt.query, withTypeMarkers(args))
 	if err != nil {
 		stmt.setQueryIDFromError(err)
 		return nil, err
@@ -69,7 +220,7 @@ func (stmt *snowflakeStmt) execInternal(ctx context.Context, args []driver.Named
 	rnr, ok := result.(*snowflakeResultNoRows)
 	if ok {
 		stmt.lastQueryID = rnr.GetQueryID()
-		return driver.ResultNoRows, nil
+		return rnr, nil
 	}
 	r, ok := result.(SnowflakeResult)
 	if !ok {
@@ -98,6 +249,20 @@ func (stmt *snowflakeStmt) GetQueryID() string {
 	return stmt.lastQueryID
 }
 
+func (stmt *snowflakeStmt) ColumnTypes(ctx context.Context) ([]ColumnType, error) {
+	if err := stmt.describe(ctx); err != nil {
+		return nil, err
+	}
+	return stmt.columnTypes, nil
+}
+
+func (stmt *snowflakeStmt) ParameterTypes(ctx context.Context) ([]ColumnType, error) {
+	if err := stmt.describe(ctx); err != nil {
+		return nil, err
+	}
+	return stmt.parameterTypes, nil
+}
+
 func (stmt *snowflakeStmt) setQueryIDFromError(err error) {
 	var snowflakeError *SnowflakeError
 	if errors.As(err, &snowflakeError) {
@@ -6,10 +6,12 @@ import (
 	"context"
 	"database/sql"
 	"database/sql/driver"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -849,3 +851,327 @@ func TestWithQueryTag(t *testing.T) {
 		assertEqualF(t, tag.String, testQueryTag)
 	})
 }
+
+func TestWithStatementTimeout(t *testing.T) {
+	runDBTest(t, func(dbt *DBTest) {
+		ctx := WithStatementTimeout(context.Background(), time.Second)
+		_, err := dbt.conn.ExecContext(ctx, "CALL SYSTEM$WAIT(10)")
+		assertTrueF(t, IsQueryTimeout(err), fmt.Sprintf("expected a statement timeout, got %v", err))
+	})
+}
+
+func TestUnitWithStatementTimeout(t *testing.T) {
+	var sentRequest execRequest
+	postQueryMock := func(_ context.Context, _ *snowflakeRestful, _ *url.Values,
+		_ map[string]string, body []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		sentRequest = execRequest{}
+		assertNilF(t, json.Unmarshal(body, &sentRequest))
+		return &execResponse{Data: execResponseData{StatementTypeID: statementTypeIDDdl}, Success: true}, nil
+	}
+	sc := &snowflakeConn{
+		cfg:               &Config{Params: map[string]*string{}},
+		rest:              &snowflakeRestful{FuncPostQuery: postQueryMock},
+		queryContextCache: (&queryContextCache{}).init(),
+	}
+
+	testcases := []struct {
+		timeout  time.Duration
+		expected float64
+	}{
+		{time.Minute, 60},
+		{1500 * time.Millisecond, 2},
+		{time.Millisecond, 1},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.timeout.String(), func(t *testing.T) {
+			_, err := sc.ExecContext(WithStatementTimeout(context.Background(), tc.timeout), "CREATE TABLE t (c int)", nil)
+			assertNilF(t, err)
+			assertEqualE(t, sentRequest.Parameters["STATEMENT_TIMEOUT_IN_SECONDS"], tc.expected)
+		})
+	}
+
+	for _, timeout := range []time.Duration{0, -time.Second} {
+		t.Run(timeout.String(), func(t *testing.T) {
+			sentRequest = execRequest{SQLText: "not sent"}
+			_, err := sc.ExecContext(WithStatementTimeout(context.Background(), timeout), "CREATE TABLE t (c int)", nil)
+			assertNotNilF(t, err)
+			assertEqualE(t, err.(*SnowflakeError).Number, ErrCodeInvalidStatementTimeout)
+			assertEqualE(t, sentRequest.SQLText, "not sent")
+		})
+	}
+
+	_, err := sc.ExecContext(WithoutStatementTimeout(context.Background()), "CREATE TABLE t (c int)", nil)
+	assertNilF(t, err)
+	assertEqualE(t, sentRequest.Parameters["STATEMENT_TIMEOUT_IN_SECONDS"], float64(0))
+
+	_, err = sc.ExecContext(context.Background(), "CREATE TABLE t (c int)", nil)
+	assertNilF(t, err)
+	_, ok := sentRequest.Parameters["STATEMENT_TIMEOUT_IN_SECONDS"]
+	assertFalseE(t, ok, "no statement timeout should be sent by default")
+}
+
+func TestWithSessionParameters(t *testing.T) {
+	runDBTest(t, func(dbt *DBTest) {
+		ctx := WithSessionParameters(context.Background(), map[string]string{"TIMEZONE": "America/New_York"})
+		rows := dbt.mustQueryContext(ctx, "SELECT '2024-01-01 00:00:00 +0000'::timestamp_ltz, CURRENT_TIMEZONE()")
+		defer rows.Close()
+		assertTrueF(t, rows.Next())
+		var ts time.Time
+		var tz string
+		assertNilF(t, rows.Scan(&ts, &tz))
+		assertEqualE(t, tz, "America/New_York")
+		assertEqualE(t, ts.Location().String(), "America/New_York")
+
+		// the session keeps its own timezone
+		rows2 := dbt.mustQuery("SELECT CURRENT_TIMEZONE()")
+		defer rows2.Close()
+		assertTrueF(t, rows2.Next())
+		assertNilF(t, rows2.Scan(&tz))
+		assertNotEqualE(t, tz, "America/New_York")
+	})
+}
+
+func TestUnitWithSessionParameters(t *testing.T) {
+	var sentRequest execRequest
+	value := "1700000000.000000000"
+	postQueryMock := func(_ context.Context, _ *snowflakeRestful, _ *url.Values,
+		_ map[string]string, body []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		sentRequest = execRequest{}
+		assertNilF(t, json.Unmarshal(body, &sentRequest))
+		// like Snowflake, echo the parameters the query ran with
+		parameters := []nameValueParameter{{Name: "CLIENT_RESULT_CHUNK_SIZE", Value: float64(160)}}
+		for name, value := range sentRequest.Parameters {
+			parameters = append(parameters, nameValueParameter{Name: name, Value: value})
+		}
+		return &execResponse{
+			Data: execResponseData{
+				Parameters:        parameters,
+				StatementTypeID:   statementTypeIDSelect,
+				QueryResultFormat: "json",
+				RowType:           []execResponseRowType{{Name: "TS", Type: "timestamp_ltz", Scale: 9}},
+				RowSet:            [][]*string{{&value}},
+				Total:             1,
+				Returned:          1,
+			},
+			Success: true,
+		}, nil
+	}
+	sessionTimezone := "UTC"
+	sc := &snowflakeConn{
+		cfg:                 &Config{Params: map[string]*string{"timezone": &sessionTimezone}},
+		rest:                &snowflakeRestful{FuncPostQuery: postQueryMock},
+		queryContextCache:   (&queryContextCache{}).init(),
+		currentTimeProvider: defaultTimeProvider,
+	}
+
+	ctx := WithSessionParameters(context.Background(), map[string]string{
+		"timezone":          "Asia/Tokyo",
+		"USE_CACHED_RESULT": "false",
+		"QUERY_TAG":         "overridden",
+	})
+	ctx = WithQueryTag(ctx, "tagged")
+	ctx = WithStatementTimeout(ctx, time.Minute)
+	rows, err := sc.QueryContext(ctx, "SELECT CURRENT_TIMESTAMP()::timestamp_ltz", nil)
+	assertNilF(t, err)
+	defer rows.Close()
+
+	assertEqualE(t, sentRequest.Parameters["TIMEZONE"], "Asia/Tokyo")
+	assertEqualE(t, sentRequest.Parameters["USE_CACHED_RESULT"], "false")
+	assertEqualE(t, sentRequest.Parameters["QUERY_TAG"], "tagged")
+
+	dest := make([]driver.Value, 1)
+	assertNilF(t, rows.Next(dest))
+	assertEqualE(t, dest[0].(time.Time).Location().String(), "Asia/Tokyo")
+
+	// the overrides echoed back by Snowflake are not kept as parameters of the session
+	assertEqualE(t, *sc.cfg.Params["timezone"], "UTC")
+	_, ok := sc.cfg.Params["use_cached_result"]
+	assertFalseE(t, ok, "use_cached_result should not be kept")
+	_, ok = sc.cfg.Params["statement_timeout_in_seconds"]
+	assertFalseE(t, ok, "statement_timeout_in_seconds should not be kept")
+	assertEqualE(t, *sc.cfg.Params["client_result_chunk_size"], "160")
+
+	rows2, err := sc.QueryContext(context.Background(), "SELECT CURRENT_TIMESTAMP()::timestamp_ltz", nil)
+	assertNilF(t, err)
+	defer rows2.Close()
+	_, ok = sentRequest.Parameters["TIMEZONE"]
+	assertFalseE(t, ok, "the timezone override should not be sent with the next query")
+	_, ok = sentRequest.Parameters["STATEMENT_TIMEOUT_IN_SECONDS"]
+	assertFalseE(t, ok, "the statement timeout should not be sent with the next query")
+	assertNilF(t, rows2.Next(dest))
+	assertEqualE(t, dest[0].(time.Time).Location().String(), "UTC")
+}
+
+func TestUnitPrepareDescribesStatement(t *testing.T) {
+	var requests int
+	postQueryMock := func(_ context.Context, _ *snowflakeRestful, _ *url.Values,
+		_ map[string]string, body []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		requests++
+		var req execRequest
+		assertNilF(t, json.Unmarshal(body, &req))
+		assertTrueF(t, req.DescribeOnly, "the statement should be described without being executed")
+		return &execResponse{
+			Data: execResponseData{
+				QueryID:         "01b2c3d4-0000-0000-0000-000000000003",
+				StatementTypeID: statementTypeIDSelect,
+				NumberOfBinds:   2,
+				RowType: []execResponseRowType{
+					{Name: "ID", Type: "fixed", Precision: 38, Scale: 0, Nullable: false},
+					{Name: "NAME", Type: "text", Length: 16777216, Nullable: true},
+				},
+				MetaDataOfBinds: []execResponseRowType{
+					{Name: "1", Type: "fixed", Precision: 38, Scale: 0, Nullable: true},
+					{Name: "2", Type: "text", Length: 16777216, Nullable: true},
+				},
+			},
+			Code:    "0",
+			Success: true,
+		}, nil
+	}
+	sc := &snowflakeConn{
+		cfg:               &Config{Params: map[string]*string{}},
+		rest:              &snowflakeRestful{FuncPostQuery: postQueryMock},
+		queryContextCache: (&queryContextCache{}).init(),
+	}
+
+	stmt, err := sc.PrepareContext(context.Background(), "SELECT id, name FROM t WHERE id = ? AND name = ?")
+	assertNilF(t, err)
+	assertEqualE(t, requests, 0, "the statement should be described when its metadata is requested")
+	assertEqualE(t, stmt.(SnowflakeStmt).GetQueryID(), "", "query ID should be empty before executing any query")
+
+	columns, err := stmt.(StatementDescriber).ColumnTypes(context.Background())
+	assertNilF(t, err)
+	assertEqualF(t, len(columns), 2)
+	assertEqualE(t, columns[0].Name, "ID")
+	assertEqualE(t, columns[0].DatabaseTypeName, "FIXED")
+	assertEqualE(t, columns[0].Precision, int64(38))
+	assertFalseE(t, columns[0].Nullable)
+	assertEqualE(t, columns[1].DatabaseTypeName, "TEXT")
+	assertEqualE(t, columns[1].ScanType, reflect.TypeOf(""))
+	assertEqualE(t, columns[1].Length, int64(16777216))
+
+	params, err := stmt.(StatementDescriber).ParameterTypes(context.Background())
+	assertNilF(t, err)
+	assertEqualF(t, len(params), 2)
+	assertEqualE(t, params[0].DatabaseTypeName, "FIXED")
+	assertEqualE(t, params[1].DatabaseTypeName, "TEXT")
+	assertEqualE(t, stmt.NumInput(), 2)
+	assertEqualE(t, requests, 1, "the statement should be described once")
+}
+
+func TestUnitDescribeFailure(t *testing.T) {
+	var requests int
+	postQueryMock := func(_ context.Context, _ *snowflakeRestful, _ *url.Values,
+		_ map[string]string, _ []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		requests++
+		return &execResponse{
+			Data:    execResponseData{SQLState: "42000", QueryID: "01b2c3d4-0000-0000-0000-000000000004"},
+			Message: "SQL compilation error",
+			Code:    "1003",
+			Success: false,
+		}, nil
+	}
+	sc := &snowflakeConn{
+		cfg:               &Config{Params: map[string]*string{}},
+		rest:              &snowflakeRestful{FuncPostQuery: postQueryMock},
+		queryContextCache: (&queryContextCache{}).init(),
+		telemetry:         testTelemetry,
+	}
+
+	stmt, err := sc.PrepareContext(context.Background(), "SELECTT 1")
+	assertNilF(t, err)
+	_, err = stmt.(StatementDescriber).ColumnTypes(context.Background())
+	assertNotNilF(t, err)
+	// a failed describe is not cached
+	_, err = stmt.(StatementDescriber).ParameterTypes(context.Background())
+	assertNotNilF(t, err)
+	assertEqualE(t, requests, 2)
+
+	// the arguments are checked when the statement is executed instead
+	assertEqualE(t, stmt.NumInput(), -1)
+	assertEqualE(t, stmt.NumInput(), -1)
+	assertEqualE(t, requests, 3)
+}
+
+func TestUnitPrepareSkipsDescribeForFileTransfers(t *testing.T) {
+	postQueryMock := func(_ context.Context, _ *snowflakeRestful, _ *url.Values,
+		_ map[string]string, _ []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		t.Fatal("PUT should not be described")
+		return nil, nil
+	}
+	sc := &snowflakeConn{
+		cfg:  &Config{Params: map[string]*string{}},
+		rest: &snowflakeRestful{FuncPostQuery: postQueryMock},
+	}
+
+	stmt, err := sc.PrepareContext(context.Background(), "PUT file:///tmp/data.csv @~")
+	assertNilF(t, err)
+	assertEqualE(t, stmt.NumInput(), -1)
+	_, err = stmt.(StatementDescriber).ColumnTypes(context.Background())
+	assertEqualE(t, err, errStatementNotDescribable)
+}
+
+// singleConnConnector hands out the same connection to database/sql.
+type singleConnConnector struct {
+	sc *snowflakeConn
+}
+
+func (c singleConnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.sc, nil
+}
+
+func (c singleConnConnector) Driver() driver.Driver {
+	return SnowflakeDriver{}
+}
+
+func TestUnitPreparedStatementWithTypeMarker(t *testing.T) {
+	var bindings []map[string]execBindParameter
+	postQueryMock := func(_ context.Context, _ *snowflakeRestful, _ *url.Values,
+		_ map[string]string, body []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		var req execRequest
+		assertNilF(t, json.Unmarshal(body, &req))
+		if req.DescribeOnly {
+			return &execResponse{
+				Data:    execResponseData{StatementTypeID: statementTypeIDInsert, NumberOfBinds: 2},
+				Code:    "0",
+				Success: true,
+			}, nil
+		}
+		bindings = append(bindings, req.Bindings)
+		return &execResponse{
+			Data:    execResponseData{QueryID: "01b2c3d4-0000-0000-0000-000000000005"},
+			Code:    "0",
+			Success: true,
+		}, nil
+	}
+	sc := &snowflakeConn{
+		cfg:               &Config{Params: map[string]*string{}, KeepSessionAlive: true},
+		rest:              &snowflakeRestful{FuncPostQuery: postQueryMock},
+		queryContextCache: (&queryContextCache{}).init(),
+		telemetry:         testTelemetry,
+	}
+	db := sql.OpenDB(singleConnConnector{sc})
+	defer db.Close()
+
+	stmt, err := db.Prepare("INSERT INTO t VALUES (?, ?)")
+	assertNilF(t, err)
+	defer stmt.Close()
+	// the type marker is bound with the value, database/sql must not count it as a parameter
+	_, err = stmt.Exec(DataTypeBinary, []byte{1, 2}, 3)
+	assertNilF(t, err)
+	_, err = stmt.Exec(4, DataTypeTimestampLtz, time.Now())
+	assertNilF(t, err)
+	assertEqualF(t, len(bindings), 2)
+	assertEqualF(t, len(bindings[0]), 2)
+	assertEqualE(t, bindings[0]["1"].Type, "BINARY")
+	assertEqualE(t, bindings[0]["2"].Type, "FIXED")
+	assertEqualF(t, len(bindings[1]), 2)
+	assertEqualE(t, bindings[1]["1"].Type, "FIXED")
+	assertEqualE(t, bindings[1]["2"].Type, "TIMESTAMP_LTZ")
+
+	// database/sql checks the number of arguments with NumInput
+	_, err = stmt.Exec(DataTypeBinary, []byte{1, 2})
+	assertNotNilF(t, err)
+	assertStringContainsE(t, err.Error(), "expected 2 arguments, got 1")
+	assertEqualE(t, len(bindings), 2)
+}
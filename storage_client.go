@@ -2,7 +2,6 @@ package gosnowflake
 
 import (
 	"fmt"
-	"math"
 	"os"
 	"path"
 	"path/filepath"
@@ -62,6 +61,8 @@ func (rsu *remoteStorageUtil) uploadOneFile(meta *fileMetadata) error {
 	utilClass := rsu.getNativeCloudType(meta.stageInfo.LocationType, meta.sfa.sc.cfg)
 	maxConcurrency := int(meta.parallel)
 	var lastErr error
+	retryPolicy := retryPolicyFor(meta.sfa.sc.cfg)
+	sleepingTime := time.Second
 	maxRetry := defaultMaxRetry
 	for retry := 0; retry < maxRetry; retry++ {
 		if !meta.overwrite {
@@ -86,12 +87,17 @@ func (rsu *remoteStorageUtil) uploadOneFile(meta *fileMetadata) error {
 				logger.Debugf("Error uploading %v. err: %v", meta.realSrcFileName, err)
 			}
 		}
+		if (meta.resStatus == needRetry || meta.resStatus == needRetryWithLowerConcurrency) &&
+			!retryPolicy.IsRetryable(CloudStorageRetryRequest, nil, meta.lastError) {
+			lastErr = meta.lastError
+			break
+		}
 		if meta.resStatus == uploaded || meta.resStatus == renewToken || meta.resStatus == renewPresignedURL {
 			return nil
 		} else if meta.resStatus == needRetry {
 			if !meta.noSleepingTime {
-				sleepingTime := intMin(int(math.Exp2(float64(retry))), 16)
-				time.Sleep(time.Second * time.Duration(sleepingTime))
+				sleepingTime = retryPolicy.Wait(CloudStorageRetryRequest, retry+1, sleepingTime)
+				time.Sleep(sleepingTime)
 			}
 		} else if meta.resStatus == needRetryWithLowerConcurrency {
 			maxConcurrency = int(meta.parallel) - (retry * int(meta.parallel) / maxRetry)
@@ -99,8 +105,8 @@ func (rsu *remoteStorageUtil) uploadOneFile(meta *fileMetadata) error {
 			meta.lastMaxConcurrency = maxConcurrency
 
 			if !meta.noSleepingTime {
-				sleepingTime := intMin(int(math.Exp2(float64(retry))), 16)
-				time.Sleep(time.Second * time.Duration(sleepingTime))
+				sleepingTime = retryPolicy.Wait(CloudStorageRetryRequest, retry+1, sleepingTime)
+				time.Sleep(sleepingTime)
 			}
 		}
 		lastErr = meta.lastError
@@ -185,6 +191,7 @@ func (rsu *remoteStorageUtil) downloadOneFile(meta *fileMetadata) error {
 
 	maxConcurrency := meta.parallel
 	var lastErr error
+	retryPolicy := retryPolicyFor(meta.sfa.sc.cfg)
 	maxRetry := defaultMaxRetry
 	for retry := 0; retry < maxRetry; retry++ {
 		if err = utilClass.nativeDownloadFile(meta, fullDstFileName, maxConcurrency); err != nil {
@@ -226,6 +233,9 @@ func (rsu *remoteStorageUtil) downloadOneFile(meta *fileMetadata) error {
 			return nil
 		}
 		lastErr = meta.lastError
+		if !retryPolicy.IsRetryable(CloudStorageRetryRequest, nil, lastErr) {
+			break
+		}
 	}
 	if lastErr != nil {
 		return lastErr
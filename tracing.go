@@ -0,0 +1,85 @@
+package gosnowflake
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/snowflakedb/gosnowflake"
+
+// span names
+const (
+	execSpanName          = "snowflake.exec"
+	querySpanName         = "snowflake.query"
+	queryPollSpanName     = "snowflake.query.poll"
+	authenticateSpanName  = "snowflake.authenticate"
+	chunkDownloadSpanName = "snowflake.chunk.download"
+	fileUploadSpanName    = "snowflake.file.upload"
+	fileDownloadSpanName  = "snowflake.file.download"
+)
+
+// span attribute keys
+const (
+	dbSystemAttribute        = attribute.Key("db.system")
+	queryIDAttribute         = attribute.Key("db.snowflake.query_id")
+	statementTypeIDAttribute = attribute.Key("db.snowflake.statement_type_id")
+	rowsAttribute            = attribute.Key("db.snowflake.rows")
+	resultFormatAttribute    = attribute.Key("db.snowflake.result_format")
+	authenticatorAttribute   = attribute.Key("db.snowflake.authenticator")
+	pollAttemptAttribute     = attribute.Key("db.snowflake.poll_attempt")
+	chunkIndexAttribute      = attribute.Key("db.snowflake.chunk.index")
+	chunkRowCountAttribute   = attribute.Key("db.snowflake.chunk.row_count")
+	fileNameAttribute        = attribute.Key("db.snowflake.file.name")
+	fileSizeAttribute        = attribute.Key("db.snowflake.file.size")
+	stageTypeAttribute       = attribute.Key("db.snowflake.stage.type")
+)
+
+var snowflakeSystemAttribute = dbSystemAttribute.String("snowflake")
+
+// getTracer returns a tracer from the TracerProvider configured in cfg.
+// The globally registered provider is used when none is configured.
+func getTracer(cfg *Config) trace.Tracer {
+	var tp trace.TracerProvider
+	if cfg != nil && cfg.TracerProvider != nil {
+		tp = cfg.TracerProvider
+	} else {
+		tp = otel.GetTracerProvider()
+	}
+	return tp.Tracer(tracerName, trace.WithInstrumentationVersion(SnowflakeGoDriverVersion))
+}
+
+// startSpan starts a client span named name as a child of the span in ctx, if any.
+func startSpan(ctx context.Context, cfg *Config, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, snowflakeSystemAttribute)
+	return getTracer(cfg).Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...))
+}
+
+// endSpan records err on span, if not nil, and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// setExecResponseAttributes decorates a query span with the details returned by the server.
+func setExecResponseAttributes(span trace.Span, data *execResponseData) {
+	if !span.IsRecording() || data == nil {
+		return
+	}
+	span.SetAttributes(
+		queryIDAttribute.String(data.QueryID),
+		statementTypeIDAttribute.Int64(data.StatementTypeID),
+		rowsAttribute.Int64(data.Total),
+	)
+	if data.QueryResultFormat != "" {
+		span.SetAttributes(resultFormatAttribute.String(data.QueryResultFormat))
+	}
+}
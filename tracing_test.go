@@ -0,0 +1,117 @@
+package gosnowflake
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newRecordingTracerProvider() (*sdktrace.TracerProvider, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	return sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)), recorder
+}
+
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestUnitExecContextCreatesSpan(t *testing.T) {
+	tp, recorder := newRecordingTracerProvider()
+	postQueryMock := func(_ context.Context, _ *snowflakeRestful, _ *url.Values,
+		_ map[string]string, _ []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		inserted := "3"
+		return &execResponse{
+			Data: execResponseData{
+				QueryID:           "01b2c3d4-0000-0000-0000-000000000001",
+				StatementTypeID:   statementTypeIDDml,
+				QueryResultFormat: "json",
+				RowType:           []execResponseRowType{{Name: "number of rows inserted"}},
+				RowSet:            [][]*string{{&inserted}},
+			},
+			Code:    "0",
+			Success: true,
+		}, nil
+	}
+	sc := &snowflakeConn{
+		cfg:               &Config{Params: map[string]*string{}, TracerProvider: tp},
+		rest:              &snowflakeRestful{FuncPostQuery: postQueryMock},
+		queryContextCache: (&queryContextCache{}).init(),
+	}
+
+	_, err := sc.ExecContext(context.Background(), "INSERT INTO t VALUES (1), (2), (3)", nil)
+	assertNilF(t, err)
+
+	spans := recorder.Ended()
+	assertEqualF(t, len(spans), 1)
+	assertEqualE(t, spans[0].Name(), execSpanName)
+	attrs := spanAttributes(spans[0])
+	assertEqualE(t, attrs[dbSystemAttribute].AsString(), "snowflake")
+	assertEqualE(t, attrs[queryIDAttribute].AsString(), "01b2c3d4-0000-0000-0000-000000000001")
+	assertEqualE(t, attrs[statementTypeIDAttribute].AsInt64(), statementTypeIDDml)
+	assertEqualE(t, attrs[rowsAttribute].AsInt64(), int64(3))
+	assertEqualE(t, attrs[resultFormatAttribute].AsString(), "json")
+}
+
+func TestUnitQueryContextSpanRecordsError(t *testing.T) {
+	tp, recorder := newRecordingTracerProvider()
+	postQueryMock := func(_ context.Context, _ *snowflakeRestful, _ *url.Values,
+		_ map[string]string, _ []byte, _ time.Duration, _ UUID, _ *Config) (*execResponse, error) {
+		return nil, errors.New("connection reset")
+	}
+	sc := &snowflakeConn{
+		cfg:               &Config{Params: map[string]*string{}, TracerProvider: tp},
+		rest:              &snowflakeRestful{FuncPostQuery: postQueryMock},
+		queryContextCache: (&queryContextCache{}).init(),
+	}
+
+	_, err := sc.QueryContext(context.Background(), "SELECT 1", nil)
+	assertNotNilF(t, err)
+
+	spans := recorder.Ended()
+	assertEqualF(t, len(spans), 1)
+	assertEqualE(t, spans[0].Name(), querySpanName)
+	assertEqualE(t, spans[0].Status().Code, codes.Error)
+	assertEqualE(t, spans[0].Status().Description, "connection reset")
+}
+
+func TestUnitPollQueryResultCreatesChildSpan(t *testing.T) {
+	tp, recorder := newRecordingTracerProvider()
+	cfg := &Config{TracerProvider: tp}
+	getMock := func(_ context.Context, _ *snowflakeRestful, _ *url.URL,
+		_ map[string]string, _ time.Duration) (*http.Response, error) {
+		body := `{"code": "333333", "success": true, "data": {"queryId": "01b2c3d4-0000-0000-0000-000000000002"}}`
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(bytes.NewBufferString(body)),
+		}, nil
+	}
+	sr := &snowflakeRestful{FuncGet: getMock}
+
+	ctx, parent := startSpan(context.Background(), cfg, querySpanName)
+	respd, err := pollQueryResult(ctx, sr, &url.URL{}, map[string]string{}, time.Second, cfg, 2)
+	parent.End()
+	assertNilF(t, err)
+	assertEqualE(t, respd.Code, queryInProgressCode)
+
+	spans := recorder.Ended()
+	assertEqualF(t, len(spans), 2)
+	assertEqualE(t, spans[0].Name(), queryPollSpanName)
+	assertEqualE(t, spans[0].Parent().SpanID(), parent.SpanContext().SpanID())
+	attrs := spanAttributes(spans[0])
+	assertEqualE(t, attrs[pollAttemptAttribute].AsInt64(), int64(2))
+	assertEqualE(t, attrs[queryIDAttribute].AsString(), "01b2c3d4-0000-0000-0000-000000000002")
+}
@@ -31,6 +31,8 @@ const (
 	arrowAlloc                       contextKey = "ARROW_ALLOC"
 	arrowBatchesTimestampOption      contextKey = "ARROW_BATCHES_TIMESTAMP_OPTION"
 	queryTag                         contextKey = "QUERY_TAG"
+	statementTimeout                 contextKey = "STATEMENT_TIMEOUT_IN_SECONDS"
+	sessionParameters                contextKey = "SESSION_PARAMETERS"
 	enableStructuredTypes            contextKey = "ENABLE_STRUCTURED_TYPES"
 	mapValuesNullable                contextKey = "MAP_VALUES_NULLABLE"
 	arrayValuesNullable              contextKey = "ARRAY_VALUES_NULLABLE"
@@ -41,6 +43,7 @@ const (
 	internalQuery       contextKey = "INTERNAL_QUERY"
 	cancelRetry         contextKey = "CANCEL_RETRY"
 	streamChunkDownload contextKey = "STREAM_CHUNK_DOWNLOAD"
+	submitOnly          contextKey = "SUBMIT_ONLY"
 )
 
 var (
@@ -153,6 +156,36 @@ func WithQueryTag(ctx context.Context, tag string) context.Context {
 	return context.WithValue(ctx, queryTag, tag)
 }
 
+// noStatementTimeout is the value of the statementTimeout key set by WithoutStatementTimeout.
+type noStatementTimeout struct{}
+
+// WithStatementTimeout returns a context that will set the STATEMENT_TIMEOUT_IN_SECONDS
+// parameter on any queries that are run, so Snowflake aborts them after the given time.
+// The timeout is rounded up to whole seconds. Queries run with a timeout that is not
+// positive fail with ErrCodeInvalidStatementTimeout instead of running without a timeout.
+func WithStatementTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, statementTimeout, timeout)
+}
+
+// WithoutStatementTimeout returns a context that will run any queries without a
+// statement timeout, overriding the STATEMENT_TIMEOUT_IN_SECONDS of the session.
+func WithoutStatementTimeout(ctx context.Context) context.Context {
+	return context.WithValue(ctx, statementTimeout, noStatementTimeout{})
+}
+
+// WithSessionParameters returns a context that will set the given session parameters,
+// e.g. TIMEZONE or USE_CACHED_RESULT, on any queries that are run, without changing
+// the parameters of the session. Results are converted according to the overridden
+// parameters, e.g. timestamps are returned in the overridden TIMEZONE. Parameters set
+// with WithQueryTag or WithStatementTimeout take precedence.
+func WithSessionParameters(ctx context.Context, params map[string]string) context.Context {
+	overrides := make(map[string]string, len(params))
+	for name, value := range params {
+		overrides[strings.ToLower(name)] = value
+	}
+	return context.WithValue(ctx, sessionParameters, overrides)
+}
+
 // WithStructuredTypesEnabled changes how structured types are returned.
 // Without this context structured types are returned as strings.
 // With this context enabled, structured types are returned as native Go types.